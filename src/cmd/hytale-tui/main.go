@@ -4,14 +4,14 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/sivert-io/hytale-server-manager/src/internal/cli"
 	"github.com/sivert-io/hytale-server-manager/src/internal/tui"
 )
 
 func main() {
 	if len(os.Args) > 1 {
 		// CLI mode for non-interactive commands
-		handleCLI(os.Args[1:])
-		return
+		os.Exit(cli.Run(os.Args[1:]))
 	}
 
 	// TUI mode
@@ -20,9 +20,3 @@ func main() {
 		os.Exit(1)
 	}
 }
-
-func handleCLI(args []string) {
-	// TODO: Implement CLI commands (start, stop, status, etc.)
-	fmt.Println("CLI mode not yet implemented. Use TUI: sudo hsm")
-	os.Exit(0)
-}
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/sivert-io/hytale-server-manager/src/internal/hytale"
+)
+
+func runBackups(args []string) error {
+	fs := flag.NewFlagSet("backups", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print output as JSON")
+	dryRun := fs.Bool("dry-run", false, "prune: only show what would be deleted")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		return usagef("expected list, inspect, restore, delete or prune")
+	}
+	numServers, err := installedServers()
+	if err != nil {
+		return err
+	}
+
+	action, rest := positional[0], positional[1:]
+	switch action {
+	case "list":
+		servers, err := parseTarget(rest, numServers)
+		if err != nil {
+			return err
+		}
+		return printBackups(servers, *asJSON)
+	case "prune":
+		servers, err := parseTarget(rest, numServers)
+		if err != nil {
+			return err
+		}
+		ctx, cancel := signalContext()
+		defer cancel()
+		results, err := hytale.PruneBackups(ctx, servers, *dryRun)
+		printPruneResults(results, *dryRun, *asJSON)
+		return err
+	case "inspect", "restore", "delete":
+		if len(rest) != 2 {
+			return usagef("expected a server number and a backup name")
+		}
+		server, err := parseServerNumber(rest[0], numServers)
+		if err != nil {
+			return err
+		}
+		name := rest[1]
+		switch action {
+		case "inspect":
+			return printBackupInspection(server, name, *asJSON)
+		case "restore":
+			tm := hytale.NewTmuxManagerFromManifest(hytale.LoadManifestOrDefault())
+			output, err := tm.RestoreGameBackup(server, name)
+			fmt.Fprint(stdout, output)
+			return err
+		default:
+			if err := hytale.DeleteGameBackup(server, name); err != nil {
+				return err
+			}
+			fmt.Fprintf(stdout, "Deleted %s from server %d\n", name, server)
+			return nil
+		}
+	default:
+		return usagef("unknown backups action %q", action)
+	}
+}
+
+// printBackups lists the game backups of each server
+func printBackups(servers []int, asJSON bool) error {
+	var all []hytale.GameBackup
+	for _, server := range servers {
+		backups, err := hytale.ListGameBackups(server)
+		if err != nil {
+			return err
+		}
+		all = append(all, backups...)
+	}
+
+	if asJSON {
+		if all == nil {
+			all = []hytale.GameBackup{}
+		}
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(all)
+	}
+
+	if len(all) == 0 {
+		fmt.Fprintln(stdout, "No backups found")
+		return nil
+	}
+	fmt.Fprintf(stdout, "%-7s %-40s %-18s %-12s %s\n", "SERVER", "BACKUP", "TIME", "SIZE", "WORLDS")
+	for _, b := range all {
+		worlds := strings.Join(b.Worlds, ",")
+		if worlds == "" {
+			worlds = "-"
+		}
+		fmt.Fprintf(stdout, "%-7d %-40s %-18s %-12d %s\n", b.Server, b.Name, b.Time.Format("2006-01-02 15:04"), b.Size, worlds)
+	}
+	return nil
+}
+
+// printBackupInspection prints what a backup archive contains
+func printBackupInspection(server int, name string, asJSON bool) error {
+	backup, err := hytale.FindGameBackup(server, name)
+	if err != nil {
+		return err
+	}
+	inspection, err := hytale.InspectGameBackup(backup.Path)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(struct {
+			hytale.GameBackup
+			Contents *hytale.BackupInspection `json:"contents"`
+		}{*backup, inspection})
+	}
+
+	fmt.Fprintf(stdout, "Backup:       %s\n", backup.Path)
+	fmt.Fprintf(stdout, "Created:      %s\n", backup.Time.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(stdout, "Size:         %d bytes (%d uncompressed)\n", backup.Size, inspection.UncompressedSize)
+	fmt.Fprintf(stdout, "Entries:      %d\n", inspection.Entries)
+	fmt.Fprintf(stdout, "Worlds:       %s\n", strings.Join(inspection.Worlds, ", "))
+	fmt.Fprintf(stdout, "Top level:    %s\n", strings.Join(inspection.TopLevel, ", "))
+	return nil
+}
+
+// printPruneResults prints what retention removed, or would remove with --dry-run
+func printPruneResults(results []hytale.PruneResult, dryRun, asJSON bool) {
+	if asJSON {
+		if results == nil {
+			results = []hytale.PruneResult{}
+		}
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(results)
+		return
+	}
+
+	if len(results) == 0 {
+		fmt.Fprintf(stdout, "No retention policy configured in %s\n", hytale.GetBackupConfigPath())
+		return
+	}
+	verb := "Deleted"
+	if dryRun {
+		verb = "Would delete"
+	}
+	for _, r := range results {
+		fmt.Fprintln(stdout, r)
+		if r.Err != "" {
+			continue
+		}
+		for _, item := range r.Removed {
+			fmt.Fprintf(stdout, "  %s %s (%s, %d bytes)\n", verb, item.Name, item.Time.Format("2006-01-02 15:04"), item.Size)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sivert-io/hytale-server-manager/src/internal/hytale"
+)
+
+// Exit codes for non-interactive use (Ansible, cron, scripts)
+const (
+	ExitOK             = 0
+	ExitFailed         = 1
+	ExitUsage          = 2
+	ExitNotInstalled   = 3
+	ExitAlreadyRunning = 4
+)
+
+// command is a single CLI subcommand
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(args []string) error
+	hidden  bool // Internal commands run by HSM itself, left out of the usage
+}
+
+// usageError signals that the command line itself was invalid
+type usageError struct {
+	msg string
+}
+
+func (e usageError) Error() string {
+	return e.msg
+}
+
+func usagef(format string, args ...interface{}) error {
+	return usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// exitStatus makes the process exit with a specific code without printing an error
+// (e.g. logpipe passing on the server's exit status)
+type exitStatus struct {
+	code int
+}
+
+func (e exitStatus) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+// stdout and stderr are package variables so output can be redirected
+var (
+	stdout io.Writer = os.Stdout
+	stderr io.Writer = os.Stderr
+)
+
+func commands() []command {
+	return []command{
+		{name: "start", usage: "start [N|all]", summary: "Start one server or all servers", run: runStart},
+		{name: "stop", usage: "stop [N|all]", summary: "Stop one server or all servers", run: runStop},
+		{name: "restart", usage: "restart [N|all] [--warn [--warnings 15m,5m,1m,30s,10s] [--rolling]]", summary: "Restart one server or all servers, optionally after an in-game countdown", run: runRestart},
+		{name: "status", usage: "status [--json]", summary: "Show server status", run: runStatus},
+		{name: "logs", usage: "logs N [--lines 200] [--follow|--list|--file F]", summary: "Print a server's console output or log files", run: runLogs},
+		{name: "exec", usage: "exec [--timeout 3s] [--json] N|all <command...>", summary: "Run a console command and print its output", run: runExec},
+		{name: "backups", usage: "backups list|inspect|restore|delete|prune [N|all] [NAME] [--dry-run]", summary: "List, inspect, restore, delete or prune world backups", run: runBackups},
+		{name: "snapshot", usage: "snapshot create|list|verify|restore|delete [N|NAME] [--to N]", summary: "Take, verify and restore HSM snapshots of universe, config and mods", run: runSnapshot},
+		{name: "targets", usage: "targets list|test|ls|push|pull|rm [TARGET] [NAME]", summary: "Manage copies of backups on remote backup targets", run: runTargets},
+		{name: "schedule", usage: "schedule list|history|run|check [JOB] [--json]", summary: "Show and run the daemon's scheduled jobs", run: runSchedule},
+		{name: "config", usage: "config explain|render|set|unset [N|all] [KEY] [VALUE] [--server N]", summary: "Explain, render and change layered server config", run: runConfig},
+		{name: "drift", usage: "drift check|reconcile [N|all] [--against N] [--json]", summary: "Find and fix servers whose config, mods or game files have drifted", run: runDrift},
+		{name: "update", usage: "update game [--check] [--rolling [--batch 1] [--warn] [--health-timeout 5m]]", summary: "Download the latest game files and update all servers", run: runUpdate},
+		{name: "game", usage: "game versions|rollback [VERSION] [N|all] [--json] | game patchline [PATCHLINE|default] [N|all]", summary: "List downloaded game versions, roll servers back and pick patchlines", run: runGame},
+		{name: "daemon", usage: "daemon [--max-crashes 5] [--crash-window 10m]", summary: "Supervise servers, restart them after crashes and run scheduled jobs", run: runDaemon},
+		{name: "add-servers", usage: "add-servers COUNT", summary: "Add server instances", run: runAddServers},
+		{name: "version", usage: "version", summary: "Print the HSM version", run: runVersion},
+		{name: "logpipe", usage: "logpipe N [--argv file | -- command...]", summary: "Write console output to the server's console log", run: runLogpipe, hidden: true},
+	}
+}
+
+// Run executes a CLI subcommand and returns the process exit code
+func Run(args []string) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(stdout)
+		return ExitOK
+	}
+
+	for _, cmd := range commands() {
+		if cmd.name != args[0] {
+			continue
+		}
+		err := cmd.run(args[1:])
+		var status exitStatus
+		if errors.As(err, &status) {
+			return status.code
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			var uerr usageError
+			if errors.As(err, &uerr) {
+				fmt.Fprintf(stderr, "Usage: hsm %s\n", cmd.usage)
+			}
+		}
+		return exitCode(err)
+	}
+
+	fmt.Fprintf(stderr, "Unknown command: %s\n\n", args[0])
+	printUsage(stderr)
+	return ExitUsage
+}
+
+// exitCode maps an error to the process exit code
+func exitCode(err error) int {
+	var uerr usageError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &uerr):
+		return ExitUsage
+	case errors.Is(err, hytale.ErrNotInstalled):
+		return ExitNotInstalled
+	case errors.Is(err, hytale.ErrAlreadyRunning):
+		return ExitAlreadyRunning
+	default:
+		return ExitFailed
+	}
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Hytale Server Manager (HSM)")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Usage:")
+	fmt.Fprintln(w, "  hsm                 Launch the interactive TUI")
+	for _, cmd := range commands() {
+		if cmd.hidden {
+			continue
+		}
+		fmt.Fprintf(w, "  hsm %-32s %s\n", cmd.usage, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Exit codes:")
+	fmt.Fprintf(w, "  %d  success\n", ExitOK)
+	fmt.Fprintf(w, "  %d  operation failed\n", ExitFailed)
+	fmt.Fprintf(w, "  %d  invalid usage\n", ExitUsage)
+	fmt.Fprintf(w, "  %d  no servers installed\n", ExitNotInstalled)
+	fmt.Fprintf(w, "  %d  server already running\n", ExitAlreadyRunning)
+}
+
+// parseArgs parses flags that may appear before or after positional arguments
+// (the standard flag package stops at the first positional argument)
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	fs.SetOutput(io.Discard)
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, usagef("%v", err)
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// installedServers returns the number of installed servers or ErrNotInstalled
+func installedServers() (int, error) {
+	numServers := hytale.DetectNumServers()
+	if numServers == 0 {
+		return 0, hytale.ErrNotInstalled
+	}
+	return numServers, nil
+}
+
+// parseTarget parses a "N" or "all" argument into a list of server numbers
+// An omitted target means all servers
+func parseTarget(args []string, numServers int) ([]int, error) {
+	if len(args) > 1 {
+		return nil, usagef("expected a single server number or 'all'")
+	}
+	if len(args) == 0 || strings.EqualFold(args[0], "all") {
+		servers := make([]int, numServers)
+		for i := range servers {
+			servers[i] = i + 1
+		}
+		return servers, nil
+	}
+	server, err := parseServerNumber(args[0], numServers)
+	if err != nil {
+		return nil, err
+	}
+	return []int{server}, nil
+}
+
+// parseServerNumber parses and validates a single server number
+func parseServerNumber(arg string, numServers int) (int, error) {
+	server, err := strconv.Atoi(arg)
+	if err != nil {
+		return 0, usagef("invalid server number %q", arg)
+	}
+	if server < 1 || server > numServers {
+		return 0, fmt.Errorf("server %d does not exist (%d server(s) installed)", server, numServers)
+	}
+	return server, nil
+}
@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+
+	"github.com/sivert-io/hytale-server-manager/src/internal/hytale"
+)
+
+func runConfig(args []string) error {
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "explain: print output as JSON")
+	serverFlag := fs.Int("server", 0, "set/unset: change this server's overrides instead of the shared defaults")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		return usagef("expected explain, render, set or unset")
+	}
+	numServers, err := installedServers()
+	if err != nil {
+		return err
+	}
+	if *serverFlag < 0 || *serverFlag > numServers {
+		return fmt.Errorf("server %d does not exist (%d server(s) installed)", *serverFlag, numServers)
+	}
+
+	action, rest := positional[0], positional[1:]
+	switch action {
+	case "explain":
+		if len(rest) != 1 {
+			return usagef("expected a server number")
+		}
+		server, err := parseServerNumber(rest[0], numServers)
+		if err != nil {
+			return err
+		}
+		return printConfigExplanation(server, *asJSON)
+
+	case "render":
+		servers, err := parseTarget(rest, numServers)
+		if err != nil {
+			return err
+		}
+		return printConfigRenders(hytale.RenderServerConfigs(servers))
+
+	case "set":
+		if len(rest) != 2 {
+			return usagef("expected a key and a value")
+		}
+		results, err := hytale.SetConfigValue(*serverFlag, rest[0], hytale.ParseConfigValue(rest[1]))
+		if err != nil {
+			return err
+		}
+		return printConfigRenders(results)
+
+	case "unset":
+		if len(rest) != 1 {
+			return usagef("expected a key")
+		}
+		results, err := hytale.UnsetConfigValue(*serverFlag, rest[0])
+		if err != nil {
+			return err
+		}
+		return printConfigRenders(results)
+
+	default:
+		return usagef("unknown config action %q", action)
+	}
+}
+
+// printConfigRenders prints the outcome of re-rendering server configs
+func printConfigRenders(results []hytale.ServerActionResult) error {
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			fmt.Fprintf(stderr, "Server %d: %v\n", result.Server, result.Err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(stdout, "Server %d: %s\n", result.Server, result.Detail)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d server config(s) could not be rendered", failed, len(results))
+	}
+	return nil
+}
+
+// printConfigExplanation prints each effective config value of a server and where it came from
+func printConfigExplanation(server int, asJSON bool) error {
+	explanation, err := hytale.ExplainServerConfig(server)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(explanation)
+	}
+
+	fmt.Fprintf(stdout, "%-40s %-30s %s\n", "KEY", "VALUE", "SOURCE")
+	for _, value := range explanation.Values {
+		source := value.Source
+		if value.Shadowed != nil {
+			source += fmt.Sprintf(" (default %s)", compactJSON(value.Shadowed))
+		}
+		fmt.Fprintf(stdout, "%-40s %-30s %s\n", value.Key, compactJSON(value.Value), source)
+	}
+	if explanation.Stale {
+		fmt.Fprintf(stdout, "\nconfig.json doesn't match its layers yet; run 'hsm config render %d' to update it\n", server)
+	}
+	return nil
+}
+
+// compactJSON renders a JSON value on one line
+func compactJSON(value json.RawMessage) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, value); err != nil {
+		return string(value)
+	}
+	return buf.String()
+}
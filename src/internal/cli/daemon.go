@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"flag"
+	"log"
+	"time"
+
+	"github.com/sivert-io/hytale-server-manager/src/internal/hytale"
+)
+
+func runDaemon(args []string) error {
+	fs := flag.NewFlagSet("daemon", flag.ContinueOnError)
+	interval := fs.Duration("interval", hytale.DefaultSupervisorInterval, "how often servers are checked")
+	maxCrashes := fs.Int("max-crashes", hytale.DefaultSupervisorMaxCrashes, "crashes within --crash-window before a server is marked crash-looping")
+	crashWindow := fs.Duration("crash-window", hytale.DefaultSupervisorCrashWindow, "window crashes are counted in")
+	backoff := fs.Duration("backoff", hytale.DefaultSupervisorBackoff, "delay before the first restart (doubles per crash)")
+	maxBackoff := fs.Duration("max-backoff", hytale.DefaultSupervisorMaxBackoff, "longest delay between restarts")
+	noScheduler := fs.Bool("no-scheduler", false, "don't run the jobs in shared/schedules.json")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usagef("unexpected argument %q", positional[0])
+	}
+	if *interval < time.Second {
+		return usagef("--interval must be at least 1s")
+	}
+	if *maxCrashes < 1 {
+		return usagef("--max-crashes must be at least 1")
+	}
+	if _, err := installedServers(); err != nil {
+		return err
+	}
+
+	logger := log.New(stdout, "", log.LstdFlags)
+	supervisor := hytale.NewSupervisor()
+	supervisor.Interval = *interval
+	supervisor.MaxCrashes = *maxCrashes
+	supervisor.CrashWindow = *crashWindow
+	supervisor.Backoff = *backoff
+	supervisor.MaxBackoff = *maxBackoff
+	supervisor.Logf = logger.Printf
+	if !*noScheduler {
+		supervisor.Scheduler = hytale.NewScheduler()
+		supervisor.Scheduler.Logf = logger.Printf
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+	return supervisor.Run(ctx)
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+
+	"github.com/sivert-io/hytale-server-manager/src/internal/hytale"
+)
+
+func runDrift(args []string) error {
+	fs := flag.NewFlagSet("drift", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "check: print output as JSON")
+	against := fs.Int("against", 0, "check: compare config.json with this server's instead of the config layers")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		return usagef("expected check or reconcile")
+	}
+	numServers, err := installedServers()
+	if err != nil {
+		return err
+	}
+	if *against < 0 || *against > numServers {
+		return fmt.Errorf("server %d does not exist (%d server(s) installed)", *against, numServers)
+	}
+
+	action, rest := positional[0], positional[1:]
+	servers, err := parseTarget(rest, numServers)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	switch action {
+	case "check":
+		reports, err := hytale.CheckDrift(ctx, servers, hytale.DriftOptions{Baseline: *against})
+		if err != nil {
+			return err
+		}
+		return printDrift(reports, *asJSON)
+
+	case "reconcile":
+		tm := hytale.NewTmuxManagerFromManifest(hytale.LoadManifestOrDefault())
+		failed := 0
+		for _, result := range tm.ReconcileDrift(ctx, servers) {
+			if result.Err != nil {
+				fmt.Fprintf(stderr, "Server %d: %v\n", result.Server, result.Err)
+				failed++
+				continue
+			}
+			fmt.Fprintf(stdout, "Server %d: %s\n", result.Server, result.Detail)
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d server(s) could not be reconciled", failed, len(servers))
+		}
+		return nil
+
+	default:
+		return usagef("unknown drift action %q", action)
+	}
+}
+
+// printDrift prints the drift report; drifted servers make the command fail so scripts can alert on it
+func printDrift(reports []hytale.ServerDrift, asJSON bool) error {
+	drifted := 0
+	for _, report := range reports {
+		if report.Error != "" || report.Count("") > 0 {
+			drifted++
+		}
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(reports); err != nil {
+			return err
+		}
+	} else {
+		for _, report := range reports {
+			fmt.Fprintf(stdout, "Server %d: %s\n", report.Server, report.Summary())
+			for _, item := range report.Items {
+				fmt.Fprintf(stdout, "  %-9s %-44s expected %-20s actual %s\n", item.Kind, item.Path, orNone(item.Expected), orNone(item.Actual))
+			}
+		}
+	}
+
+	if drifted > 0 {
+		return fmt.Errorf("%d of %d server(s) have drifted", drifted, len(reports))
+	}
+	return nil
+}
+
+// orNone shows a missing value as "(none)"
+func orNone(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sivert-io/hytale-server-manager/src/internal/hytale"
+)
+
+// execResult is the JSON output of hsm exec for one server
+type execResult struct {
+	Server int      `json:"server"`
+	Output []string `json:"output"`
+	Error  string   `json:"error,omitempty"`
+}
+
+func runExec(args []string) error {
+	fs := flag.NewFlagSet("exec", flag.ContinueOnError)
+	timeout := fs.Duration("timeout", hytale.DefaultCommandTimeout, "how long to collect the command's output (0 = don't wait)")
+	asJSON := fs.Bool("json", false, "print output as JSON")
+	// Flags come before the target; everything after it is the console command, which
+	// may have dashes of its own (hsm exec 1 /kick Bob -r spam)
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		return usagef("%v", err)
+	}
+	positional := fs.Args()
+	if len(positional) > 1 && positional[1] == "--" {
+		positional = append(positional[:1], positional[2:]...)
+	}
+	if len(positional) < 2 {
+		return usagef("expected a server number or 'all' and a command")
+	}
+	numServers, err := installedServers()
+	if err != nil {
+		return err
+	}
+	servers, err := parseTarget(positional[:1], numServers)
+	if err != nil {
+		return err
+	}
+	// Allow the command unquoted: hsm exec all say hello
+	command := strings.Join(positional[1:], " ")
+
+	tm := hytale.NewTmuxManagerFromManifest(hytale.LoadManifestOrDefault())
+	targetAll := len(servers) > 1
+	if targetAll {
+		// Skip stopped servers instead of failing the whole broadcast
+		var running []int
+		for _, server := range servers {
+			if tm.HasSession(server) {
+				running = append(running, server)
+			}
+		}
+		if len(running) == 0 {
+			return fmt.Errorf("no servers are running: %w", hytale.ErrNotRunning)
+		}
+		servers = running
+	}
+
+	outputs := make(map[int][]string)
+	results := hytale.RunOnServers(servers, func(server int) (string, error) {
+		lines, err := tm.SendCommandWithTimeout(server, command, *timeout)
+		return strings.Join(lines, "\n"), err
+	})
+	for _, result := range results {
+		if result.Detail != "" {
+			outputs[result.Server] = strings.Split(result.Detail, "\n")
+		}
+	}
+
+	failed := 0
+	if *asJSON {
+		report := make([]execResult, 0, len(results))
+		for _, result := range results {
+			entry := execResult{Server: result.Server, Output: outputs[result.Server]}
+			if entry.Output == nil {
+				entry.Output = []string{}
+			}
+			if result.Err != nil {
+				entry.Error = result.Err.Error()
+				failed++
+			}
+			report = append(report, entry)
+		}
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		for _, result := range results {
+			if result.Err != nil {
+				fmt.Fprintf(stderr, "Server %d: %v\n", result.Server, result.Err)
+				failed++
+				continue
+			}
+			for _, line := range outputs[result.Server] {
+				if targetAll {
+					fmt.Fprintf(stdout, "[server %d] %s\n", result.Server, line)
+				} else {
+					fmt.Fprintln(stdout, line)
+				}
+			}
+		}
+	}
+
+	if failed > 0 {
+		if failed == 1 && len(results) == 1 && errors.Is(results[0].Err, hytale.ErrNotRunning) {
+			return fmt.Errorf("server %d is not running", results[0].Server)
+		}
+		return fmt.Errorf("command failed on %d of %d server(s)", failed, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sivert-io/hytale-server-manager/src/internal/hytale"
+)
+
+func runGame(args []string) error {
+	fs := flag.NewFlagSet("game", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "versions: print output as JSON")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		return usagef("expected versions, rollback or patchline")
+	}
+	numServers, err := installedServers()
+	if err != nil {
+		return err
+	}
+
+	action, rest := positional[0], positional[1:]
+	switch action {
+	case "versions":
+		if len(rest) != 0 {
+			return usagef("versions takes no arguments")
+		}
+		return printGameVersions(*asJSON)
+
+	case "rollback":
+		// A leading argument that isn't a server number or "all" is the version
+		version := ""
+		if len(rest) > 0 && !isServerTarget(rest[0]) {
+			version, rest = rest[0], rest[1:]
+		}
+		servers, err := parseTarget(rest, numServers)
+		if err != nil {
+			return err
+		}
+		ctx, cancel := signalContext()
+		defer cancel()
+
+		tm := hytale.NewTmuxManagerFromManifest(hytale.LoadManifestOrDefault())
+		results, err := tm.RollbackGame(ctx, servers, version)
+		failed := 0
+		for _, result := range results {
+			if result.Err != nil {
+				fmt.Fprintf(stderr, "Server %d: %v\n", result.Server, result.Err)
+				failed++
+				continue
+			}
+			fmt.Fprintf(stdout, "Server %d: %s\n", result.Server, result.Detail)
+		}
+		if err != nil {
+			return err
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d server(s) could not be rolled back", failed, len(servers))
+		}
+		return nil
+
+	case "patchline":
+		if len(rest) == 0 {
+			return printPatchlines(numServers)
+		}
+		return setPatchline(rest[0], rest[1:], numServers)
+
+	default:
+		return usagef("unknown game action %q", action)
+	}
+}
+
+// setPatchline makes servers follow a patchline
+// Without servers it sets the installation's patchline, "all" also clears the servers' own ones,
+// and "default" makes servers follow the installation's patchline again
+func setPatchline(patchline string, rest []string, numServers int) error {
+	if len(rest) == 0 {
+		if patchline == "default" {
+			return usagef("expected a server number or 'all' with default")
+		}
+		if err := hytale.SetInstallationPatchline(patchline); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Servers without their own patchline now follow %s\n", patchline)
+	} else if rest[0] == "all" && patchline != "default" {
+		if err := hytale.SetInstallationPatchline(patchline); err != nil {
+			return err
+		}
+		servers, _ := parseTarget(rest, numServers)
+		if err := hytale.SetServerPatchlines(servers, ""); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "All servers now follow %s\n", patchline)
+	} else {
+		servers, err := parseTarget(rest, numServers)
+		if err != nil {
+			return err
+		}
+		if patchline == "default" {
+			patchline = ""
+		}
+		if err := hytale.SetServerPatchlines(servers, patchline); err != nil {
+			return err
+		}
+		manifest := hytale.LoadManifestOrDefault()
+		for _, server := range servers {
+			fmt.Fprintf(stdout, "Server %d now follows %s\n", server, hytale.ServerPatchline(manifest, server))
+		}
+	}
+	fmt.Fprintln(stdout, "Run 'hsm update game' (or --rolling for running servers) to move the servers to their patchline's game version")
+	return nil
+}
+
+// printPatchlines lists the installation's patchline and the one each server follows
+func printPatchlines(numServers int) error {
+	manifest := hytale.LoadManifestOrDefault()
+	fmt.Fprintf(stdout, "Installation patchline: %s\n\n", manifest.Patchline)
+	fmt.Fprintf(stdout, "%-8s %-22s %s\n", "SERVER", "PATCHLINE", "GAME VERSION")
+	for server := 1; server <= numServers; server++ {
+		patchline := hytale.ServerPatchline(manifest, server)
+		if manifest.ServerPatchlines[server] == "" {
+			patchline += " (default)"
+		}
+		gameVersion := manifest.ServerGameVersions[server]
+		if gameVersion == "" {
+			gameVersion = "master-install"
+		}
+		fmt.Fprintf(stdout, "%-8d %-22s %s\n", server, patchline, gameVersion)
+	}
+	return nil
+}
+
+// isServerTarget reports whether an argument names servers rather than a game version
+func isServerTarget(arg string) bool {
+	if arg == "all" {
+		return true
+	}
+	_, err := strconv.Atoi(arg)
+	return err == nil
+}
+
+// printGameVersions lists the downloaded game builds and the servers running each
+func printGameVersions(asJSON bool) error {
+	versions, err := hytale.ListGameVersions()
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if versions == nil {
+			versions = []hytale.GameVersion{}
+		}
+		return encoder.Encode(versions)
+	}
+
+	if len(versions) == 0 {
+		fmt.Fprintln(stdout, "No game versions downloaded yet (run 'hsm update game')")
+		return nil
+	}
+	fmt.Fprintf(stdout, "  %-30s %-12s %-20s %s\n", "VERSION", "PATCHLINE", "DOWNLOADED", "SERVERS")
+	for _, version := range versions {
+		marker := " "
+		if version.Active {
+			marker = "*"
+		}
+		servers := make([]string, len(version.Servers))
+		for i, server := range version.Servers {
+			servers[i] = strconv.Itoa(server)
+		}
+		fmt.Fprintf(stdout, "%s %-30s %-12s %-20s %s\n", marker, version.Version, version.Patchline, version.InstalledAt.Format("2006-01-02 15:04"), orNone(strings.Join(servers, ",")))
+	}
+	fmt.Fprintln(stdout, "\n* = the patchline's master-install (used by new servers)")
+	return nil
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	"github.com/sivert-io/hytale-server-manager/src/internal/hytale"
+)
+
+// runLogpipe writes a server's console output to its persistent console log
+// Without a command it reads stdin (tmux pipe-pane); with `-- command...` or `--argv file`
+// (a JSON array, as the systemd backend writes it) it runs the command, logs its output,
+// passes the output on to stdout (the journal) and exits with the command's exit status
+func runLogpipe(args []string) error {
+	var command []string
+	for i, arg := range args {
+		if arg == "--" {
+			command = args[i+1:]
+			args = args[:i]
+			break
+		}
+	}
+	if len(args) == 3 && args[1] == "--argv" && len(command) == 0 {
+		argv, err := hytale.ReadSystemdArgv(args[2])
+		if err != nil {
+			return err
+		}
+		command = argv
+		args = args[:1]
+	}
+	if len(args) != 1 {
+		return usagef("expected a server number")
+	}
+	server, err := parseServerNumber(args[0], hytale.DetectNumServers())
+	if err != nil {
+		return err
+	}
+
+	writer := hytale.NewConsoleLogWriter(server)
+	defer writer.Close()
+
+	if len(command) == 0 {
+		return hytale.CopyConsoleLines(writer, os.Stdin, nil, nil)
+	}
+
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("failed to create pipe: %w", err)
+	}
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+	if err := cmd.Start(); err != nil {
+		pr.Close()
+		pw.Close()
+		return fmt.Errorf("failed to start %s: %w", command[0], err)
+	}
+	pw.Close()
+
+	// Pass stop signals on to the server instead of dying before it does
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP)
+	go func() {
+		for sig := range signals {
+			_ = cmd.Process.Signal(sig)
+		}
+	}()
+
+	// Log errors are reported once as they happen; the server's output keeps flowing to the journal
+	reported := false
+	report := func(err error) {
+		reported = true
+		fmt.Fprintf(stderr, "Console log: %v (output still goes to the journal)\n", err)
+	}
+	copied := make(chan error, 1)
+	go func() {
+		copied <- hytale.CopyConsoleLines(writer, pr, stdout, report)
+	}()
+
+	waitErr := cmd.Wait()
+	// Drain what the server printed last (the reader ends once every writer has exited)
+	copyErr := <-copied
+	signal.Stop(signals)
+	close(signals)
+
+	if waitErr != nil {
+		var exitErr *exec.ExitError
+		if errors.As(waitErr, &exitErr) {
+			code := exitErr.ExitCode()
+			if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+				code = 128 + int(status.Signal())
+			}
+			return exitStatus{code: code}
+		}
+		return waitErr
+	}
+	if copyErr != nil && !reported && !errors.Is(copyErr, io.EOF) {
+		fmt.Fprintf(stderr, "Console log: %v\n", copyErr)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sivert-io/hytale-server-manager/src/internal/hytale"
+)
+
+func runSchedule(args []string) error {
+	fs := flag.NewFlagSet("schedule", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print output as JSON")
+	limit := fs.Int("n", 20, "history: number of runs to show")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		return usagef("expected list, history, run or check")
+	}
+	config, err := hytale.ReadSchedules()
+	if err != nil {
+		return err
+	}
+
+	action, rest := positional[0], positional[1:]
+	switch action {
+	case "list":
+		return printSchedules(config, *asJSON)
+
+	case "history":
+		job := ""
+		if len(rest) > 0 {
+			job = rest[0]
+		}
+		return printJobHistory(job, *limit, *asJSON)
+
+	case "run":
+		// Run a job now, outside its schedule; the run is recorded in the job history
+		if len(rest) != 1 {
+			return usagef("expected a job name")
+		}
+		job, err := config.Job(rest[0])
+		if err != nil {
+			return err
+		}
+		ctx, cancel := signalContext()
+		defer cancel()
+		run := hytale.JobRun{Job: job.Name, Action: job.Action, Started: time.Now(), Status: hytale.JobStatusOK}
+		output, runErr := hytale.RunScheduledJob(ctx, *job)
+		run.Finished = time.Now()
+		run.Output = output
+		if runErr != nil {
+			run.Status = hytale.JobStatusFailed
+			run.Output = strings.TrimSpace(output + "\n" + runErr.Error())
+		}
+		if output != "" {
+			fmt.Fprintln(stdout, output)
+		}
+		if err := hytale.RecordJobRun(run); err != nil {
+			fmt.Fprintf(stderr, "Failed to record the run: %v\n", err)
+		}
+		return runErr
+
+	case "check":
+		// ReadSchedules has validated the file; show when each job fires next
+		if len(config.Jobs) == 0 {
+			fmt.Fprintf(stdout, "No jobs in %s\n", hytale.GetSchedulesPath())
+			return nil
+		}
+		now := time.Now()
+		for _, job := range config.Jobs {
+			schedule, _ := hytale.ParseCron(job.Schedule)
+			fmt.Fprintf(stdout, "%s (%s, %s):", job.Name, job.Schedule, job.Action)
+			next := now
+			for i := 0; i < 3; i++ {
+				next = schedule.Next(next)
+				if next.IsZero() {
+					break
+				}
+				fmt.Fprintf(stdout, "  %s", next.Format("Mon 2006-01-02 15:04"))
+			}
+			fmt.Fprintln(stdout)
+		}
+		fmt.Fprintf(stdout, "%s is valid\n", hytale.GetSchedulesPath())
+		return nil
+
+	default:
+		return usagef("unknown schedule action %q", action)
+	}
+}
+
+// printSchedules lists the scheduled jobs with their last and next runs
+func printSchedules(config *hytale.ScheduleConfig, asJSON bool) error {
+	state := hytale.LoadSchedulerState()
+
+	if asJSON {
+		type jobWithState struct {
+			hytale.ScheduledJob
+			State *hytale.JobState `json:"state,omitempty"`
+		}
+		jobs := []jobWithState{}
+		for _, job := range config.Jobs {
+			jobs = append(jobs, jobWithState{job, state.Jobs[job.Name]})
+		}
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(jobs)
+	}
+
+	if len(config.Jobs) == 0 {
+		fmt.Fprintf(stdout, "No jobs in %s\n", hytale.GetSchedulesPath())
+		return nil
+	}
+	fmt.Fprintf(stdout, "%-20s %-16s %-15s %-8s %-24s %s\n", "JOB", "SCHEDULE", "ACTION", "SERVERS", "LAST RUN", "NEXT RUN")
+	for _, job := range config.Jobs {
+		servers := job.Servers
+		if servers == "" {
+			servers = "all"
+		}
+		last, next := "-", "-"
+		if js := state.Jobs[job.Name]; js != nil {
+			if !js.LastRun.IsZero() {
+				last = js.LastRun.Format("2006-01-02 15:04") + " " + js.LastStatus
+			}
+			if js.Running {
+				last = "running"
+			}
+			if !js.NextRun.IsZero() {
+				next = js.NextRun.Format("2006-01-02 15:04")
+			}
+		}
+		if job.Disabled {
+			next = "disabled"
+		}
+		fmt.Fprintf(stdout, "%-20s %-16s %-15s %-8s %-24s %s\n", job.Name, job.Schedule, job.Action, servers, last, next)
+	}
+	return nil
+}
+
+// printJobHistory prints the newest job runs, optionally for a single job
+func printJobHistory(job string, limit int, asJSON bool) error {
+	var runs []hytale.JobRun
+	history := hytale.LoadSchedulerState().History
+	for i := len(history) - 1; i >= 0 && len(runs) < limit; i-- {
+		if job == "" || history[i].Job == job {
+			runs = append(runs, history[i])
+		}
+	}
+
+	if asJSON {
+		if runs == nil {
+			runs = []hytale.JobRun{}
+		}
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(runs)
+	}
+
+	if len(runs) == 0 {
+		fmt.Fprintln(stdout, "No job runs recorded")
+		return nil
+	}
+	for _, run := range runs {
+		status := run.Status
+		if run.CatchUp {
+			status += " (catch-up)"
+		}
+		fmt.Fprintf(stdout, "%s  %-20s %-15s %-18s %s\n", run.Started.Format("2006-01-02 15:04:05"), run.Job, run.Action, status, run.Finished.Sub(run.Started).Round(time.Second))
+		if run.Output != "" {
+			fmt.Fprintf(stdout, "    %s\n", strings.ReplaceAll(run.Output, "\n", "\n    "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,480 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/sivert-io/hytale-server-manager/src/internal/hytale"
+)
+
+// logFollowInterval is how often `hsm logs --follow` polls for new output
+const logFollowInterval = 1 * time.Second
+
+// signalContext returns a context that is cancelled on SIGINT/SIGTERM
+func signalContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+func runStart(args []string) error {
+	fs := flag.NewFlagSet("start", flag.ContinueOnError)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	numServers, err := installedServers()
+	if err != nil {
+		return err
+	}
+	servers, err := parseTarget(positional, numServers)
+	if err != nil {
+		return err
+	}
+
+	manifest := hytale.LoadManifestOrDefault()
+	tm := hytale.NewTmuxManagerFromManifest(manifest)
+	settings := hytale.LoadLaunchSettings(manifest)
+
+	started, alreadyRunning, failed := 0, 0, 0
+	for _, server := range servers {
+		err := tm.StartServer(server, settings)
+		switch {
+		case err == nil:
+			fmt.Fprintf(stdout, "Server %d started\n", server)
+			started++
+		case errors.Is(err, hytale.ErrAlreadyRunning):
+			fmt.Fprintf(stdout, "Server %d is already running\n", server)
+			alreadyRunning++
+		default:
+			fmt.Fprintf(stderr, "Server %d failed to start: %v\n", server, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d server(s) failed to start", failed, len(servers))
+	}
+	if started == 0 && alreadyRunning > 0 {
+		return hytale.ErrAlreadyRunning
+	}
+	return nil
+}
+
+func runStop(args []string) error {
+	fs := flag.NewFlagSet("stop", flag.ContinueOnError)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	numServers, err := installedServers()
+	if err != nil {
+		return err
+	}
+	servers, err := parseTarget(positional, numServers)
+	if err != nil {
+		return err
+	}
+
+	tm := hytale.NewTmuxManagerFromManifest(hytale.LoadManifestOrDefault())
+
+	running := servers[:0:0]
+	for _, server := range servers {
+		if tm.HasSession(server) {
+			running = append(running, server)
+		} else {
+			// Stopping a stopped server is not an error (keeps scripts idempotent)
+			fmt.Fprintf(stdout, "Server %d is not running\n", server)
+		}
+	}
+
+	var results []hytale.StopResult
+	if len(servers) > 1 {
+		// All servers stop in parallel within one overall deadline; Ctrl+C skips the grace period
+		ctx, cancel := signalContext()
+		defer cancel()
+		results, _ = tm.StopAllWithContext(ctx, len(servers))
+	} else if len(running) == 1 {
+		result, err := tm.Stop(running[0])
+		if err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Error != "" {
+			fmt.Fprintf(stderr, "Server %d failed to stop: %s\n", result.Server, result.Error)
+			failed++
+			continue
+		}
+		fmt.Fprintf(stdout, "Server %d stopped (%s)\n", result.Server, result)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d server(s) failed to stop", failed, len(servers))
+	}
+	return nil
+}
+
+func runRestart(args []string) error {
+	fs := flag.NewFlagSet("restart", flag.ContinueOnError)
+	warn := fs.Bool("warn", false, "count down on the console before restarting (Ctrl+C cancels)")
+	warnings := fs.String("warnings", hytale.DefaultRestartWarnings, "countdown steps for --warn")
+	warningCommand := fs.String("warning-command", hytale.DefaultRestartWarningCommand, "console command broadcast at each step; {time} is the time left")
+	rolling := fs.Bool("rolling", false, "with --warn, restart the servers one after another")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	numServers, err := installedServers()
+	if err != nil {
+		return err
+	}
+	servers, err := parseTarget(positional, numServers)
+	if err != nil {
+		return err
+	}
+
+	manifest := hytale.LoadManifestOrDefault()
+	tm := hytale.NewTmuxManagerFromManifest(manifest)
+	settings := hytale.LoadLaunchSettings(manifest)
+
+	if *warn {
+		opts := hytale.DefaultWarnedRestartOptions()
+		if opts.Warnings, err = hytale.ParseRestartWarnings(*warnings); err != nil {
+			return usagef("%v", err)
+		}
+		opts.Command = *warningCommand
+		opts.Rolling = *rolling
+		opts.Progress = func(server int, message string) {
+			fmt.Fprintf(stdout, "%s Server %d: %s\n", time.Now().Format("15:04:05"), server, message)
+		}
+		ctx, cancel := signalContext()
+		defer cancel()
+
+		failed := 0
+		for _, result := range tm.WarnedRestartServers(ctx, servers, opts) {
+			if result.Err != nil {
+				fmt.Fprintf(stderr, "Server %d failed to restart: %v\n", result.Server, result.Err)
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d server(s) failed to restart", failed, len(servers))
+		}
+		return nil
+	}
+
+	failed := 0
+	for _, server := range servers {
+		if err := tm.RestartServer(server, settings); err != nil {
+			fmt.Fprintf(stderr, "Server %d failed to restart: %v\n", server, err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(stdout, "Server %d restarted\n", server)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d server(s) failed to restart", failed, len(servers))
+	}
+	return nil
+}
+
+func runStatus(args []string) error {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print status as JSON")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usagef("unexpected argument %q", positional[0])
+	}
+
+	numServers := hytale.DetectNumServers()
+	tm := hytale.NewTmuxManagerFromManifest(hytale.LoadManifestOrDefault())
+	statuses := tm.Status(numServers)
+
+	if *asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(statuses); err != nil {
+			return err
+		}
+	} else {
+		if numServers == 0 {
+			fmt.Fprintln(stdout, "No servers installed")
+		} else {
+			fmt.Fprintf(stdout, "%-8s %-12s %-8s %-20s %-12s %s\n", "SERVER", "STATUS", "PORT", "SESSION", "PATCHLINE", "GAME VERSION")
+			for _, st := range statuses {
+				gameVersion := st.GameVersion
+				if gameVersion == "" {
+					gameVersion = "master-install"
+				}
+				fmt.Fprintf(stdout, "%-8d %-12s %-8d %-20s %-12s %s\n", st.Server, st.Status, st.Port, st.Session, st.Patchline, gameVersion)
+			}
+		}
+	}
+
+	if numServers == 0 {
+		return hytale.ErrNotInstalled
+	}
+	return nil
+}
+
+func runLogs(args []string) error {
+	fs := flag.NewFlagSet("logs", flag.ContinueOnError)
+	lines := fs.Int("lines", 200, "number of lines to print")
+	follow := fs.Bool("follow", false, "keep printing new output until interrupted")
+	fs.BoolVar(follow, "f", false, "shorthand for --follow")
+	list := fs.Bool("list", false, "list the server's console log files and crash reports")
+	file := fs.String("file", "", "print a historic log file (see --list) instead of the latest output")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usagef("expected a server number")
+	}
+	if *lines < 1 {
+		return usagef("--lines must be at least 1")
+	}
+	numServers, err := installedServers()
+	if err != nil {
+		return err
+	}
+	server, err := parseServerNumber(positional[0], numServers)
+	if err != nil {
+		return err
+	}
+
+	if *list || *file != "" {
+		return printLogFiles(server, *file, *lines)
+	}
+
+	tm := hytale.NewTmuxManagerFromManifest(hytale.LoadManifestOrDefault())
+	output, err := tm.Logs(server, *lines)
+	if err != nil {
+		return err
+	}
+	previous := hytale.SplitLogLines(output)
+	for _, line := range previous {
+		fmt.Fprintln(stdout, line)
+	}
+	if !*follow {
+		return nil
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	ticker := time.NewTicker(logFollowInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		// Logs keeps reading the console log file after the server stops, so the session
+		// is checked separately; checking first means the last lines are still printed
+		running := tm.HasSession(server)
+		output, err := tm.Logs(server, *lines)
+		if errors.Is(err, hytale.ErrNotRunning) {
+			running = false
+		} else if err != nil {
+			return err
+		}
+		current := hytale.SplitLogLines(output)
+		for _, line := range hytale.NewLogLines(previous, current) {
+			fmt.Fprintln(stdout, line)
+		}
+		previous = current
+		if !running {
+			fmt.Fprintf(stderr, "Server %d stopped\n", server)
+			return nil
+		}
+	}
+}
+
+// printLogFiles lists a server's log files, or prints the last lines of the named one
+func printLogFiles(server int, name string, lines int) error {
+	files, err := hytale.ListConsoleLogs(server)
+	if err != nil {
+		return err
+	}
+
+	if name == "" {
+		if len(files) == 0 {
+			fmt.Fprintf(stdout, "No log files for server %d\n", server)
+			return nil
+		}
+		fmt.Fprintf(stdout, "%-36s %-18s %s\n", "FILE", "MODIFIED", "SIZE")
+		for _, f := range files {
+			fmt.Fprintf(stdout, "%-36s %-18s %d\n", f.Name, f.ModTime.Format("2006-01-02 15:04"), f.Size)
+		}
+		return nil
+	}
+
+	for _, f := range files {
+		if f.Name == name {
+			output, err := hytale.ReadLogFile(f.Path, lines)
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(stdout, output)
+			return nil
+		}
+	}
+	return fmt.Errorf("no log file %q for server %d (see hsm logs %d --list)", name, server, server)
+}
+
+func runUpdate(args []string) error {
+	fs := flag.NewFlagSet("update", flag.ContinueOnError)
+	check := fs.Bool("check", false, "only check whether a newer game version is available")
+	rolling := fs.Bool("rolling", false, "restart the servers onto the new version in batches, health checking each one")
+	batch := fs.Int("batch", 1, "with --rolling, how many servers to update at the same time")
+	warn := fs.Bool("warn", false, "with --rolling, count down on the console before stopping each server")
+	warnings := fs.String("warnings", hytale.DefaultRestartWarnings, "countdown steps for --warn")
+	warningCommand := fs.String("warning-command", hytale.DefaultRestartWarningCommand, "console command broadcast at each step; {time} is the time left")
+	healthTimeout := fs.Duration("health-timeout", 0, "with --rolling, how long a server gets to start (default from the manifest)")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 || positional[0] != "game" {
+		return usagef("expected 'game'")
+	}
+	if _, err := installedServers(); err != nil {
+		return err
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	if *check {
+		result, err := hytale.CheckGameUpdate(ctx)
+		if err != nil {
+			return err
+		}
+		if result.Available() {
+			fmt.Fprintf(stdout, "Game update available: %s → %s\n", orNone(result.Installed), result.Latest)
+		} else {
+			fmt.Fprintf(stdout, "Game is up to date (version %s)\n", result.Latest)
+		}
+		return nil
+	}
+
+	if *rolling {
+		manifest := hytale.LoadManifestOrDefault()
+		opts := hytale.DefaultRollingUpdateOptions(manifest)
+		if *batch < 1 {
+			return usagef("--batch must be at least 1")
+		}
+		opts.BatchSize = *batch
+		opts.Warn = *warn
+		if opts.Restart.Warnings, err = hytale.ParseRestartWarnings(*warnings); err != nil {
+			return usagef("%v", err)
+		}
+		opts.Restart.Command = *warningCommand
+		if *healthTimeout > 0 {
+			opts.HealthTimeout = *healthTimeout
+		}
+		opts.Progress = func(server int, message string) {
+			fmt.Fprintf(stdout, "%s Server %d: %s\n", time.Now().Format("15:04:05"), server, message)
+		}
+		opts.DownloadProgress = downloadProgress()
+		opts.DeviceCode = printDeviceCode
+
+		tm := hytale.NewTmuxManagerFromManifest(manifest)
+		version, results, err := tm.RollingUpdateGame(ctx, opts)
+		if err != nil {
+			return err
+		}
+		failed := 0
+		for _, result := range results {
+			if result.Err != nil {
+				fmt.Fprintf(stderr, "Server %d: %v\n", result.Server, result.Err)
+				failed++
+				continue
+			}
+			fmt.Fprintf(stdout, "Server %d: %s\n", result.Server, result.Detail)
+		}
+		if failed > 0 {
+			return fmt.Errorf("rollout of game version %s stopped: %d of %d server(s) not updated", version, failed, len(results))
+		}
+		return nil
+	}
+
+	out, err := hytale.UpdateGameWithProgress(ctx, downloadProgress(), printDeviceCode)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, out)
+	return nil
+}
+
+// downloadProgress prints a game download's progress in steps of 10%
+func downloadProgress() hytale.ProgressCallback {
+	lastStep := -1
+	lastLabel := ""
+	return func(percent float64, label string) {
+		step := int(percent * 10)
+		if label != lastLabel {
+			fmt.Fprintln(stdout, label)
+		} else if step != lastStep {
+			fmt.Fprintf(stdout, "%s %d%%\n", label, step*10)
+		}
+		lastStep, lastLabel = step, label
+	}
+}
+
+// printDeviceCode tells the user how to sign in when hytale-downloader has no credentials
+func printDeviceCode(code hytale.DeviceCode) {
+	fmt.Fprintf(stdout, "\nhytale-downloader needs you to sign in to your Hytale account:\n  1. Open %s\n  2. Enter the code %s\nThe download continues once you have signed in.\n\n", code.URL, code.Code)
+}
+
+func runAddServers(args []string) error {
+	fs := flag.NewFlagSet("add-servers", flag.ContinueOnError)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usagef("expected the number of servers to add")
+	}
+	count, err := strconv.Atoi(positional[0])
+	if err != nil || count < 1 {
+		return usagef("invalid server count %q", positional[0])
+	}
+	numServers, err := installedServers()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	for i := 0; i < count; i++ {
+		if err := hytale.AddServerInstanceWithContext(ctx, numServers+i); err != nil {
+			return fmt.Errorf("added %d/%d server(s): %w", i, count, err)
+		}
+		fmt.Fprintf(stdout, "Server %d added\n", numServers+i+1)
+	}
+	return nil
+}
+
+func runVersion(args []string) error {
+	fmt.Fprintf(stdout, "HSM %s\n", hytale.GetFullVersion())
+	return nil
+}
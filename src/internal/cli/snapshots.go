@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/sivert-io/hytale-server-manager/src/internal/hytale"
+)
+
+func runSnapshot(args []string) error {
+	fs := flag.NewFlagSet("snapshot", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print output as JSON")
+	to := fs.Int("to", 0, "server to restore the snapshot to (default: the server it was taken of)")
+	universeOnly := fs.Bool("universe-only", false, "restore only the universe, keeping config.json and mods")
+	noRestart := fs.Bool("no-restart", false, "leave the server stopped after restoring")
+	noUpload := fs.Bool("no-upload", false, "don't copy new snapshots to the backup targets")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		return usagef("expected create, list, verify, restore or delete")
+	}
+	numServers, err := installedServers()
+	if err != nil {
+		return err
+	}
+
+	action, rest := positional[0], positional[1:]
+	switch action {
+	case "create":
+		servers, err := parseTarget(rest, numServers)
+		if err != nil {
+			return err
+		}
+		tm := hytale.NewTmuxManagerFromManifest(hytale.LoadManifestOrDefault())
+		failed := 0
+		for _, server := range servers {
+			snapshot, err := tm.CreateSnapshot(server)
+			if err != nil {
+				fmt.Fprintf(stderr, "Server %d snapshot failed: %v\n", server, err)
+				failed++
+				continue
+			}
+			saved := "save not confirmed"
+			if snapshot.SaveConfirmed {
+				saved = "save confirmed"
+			}
+			fmt.Fprintf(stdout, "Server %d: %s (%d files, %d bytes, %s)\n", server, snapshot.Name, snapshot.Files, snapshot.Size, saved)
+			ctx, cancel := signalContext()
+			if !*noUpload {
+				lines, err := hytale.ReplicateToTargets(ctx, snapshot.Path, hytale.SnapshotRemoteName(snapshot))
+				for _, line := range lines {
+					fmt.Fprintf(stdout, "  %s\n", line)
+				}
+				if err != nil {
+					fmt.Fprintf(stderr, "Server %d snapshot upload failed: %v\n", server, err)
+					failed++
+				}
+			}
+			// Apply retention now that there is a new backup to count
+			results, err := hytale.PruneBackups(ctx, []int{server}, false)
+			cancel()
+			for _, r := range results {
+				fmt.Fprintf(stdout, "  %s\n", r)
+			}
+			if err != nil {
+				fmt.Fprintf(stderr, "Server %d retention failed: %v\n", server, err)
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d snapshot(s) failed", failed, len(servers))
+		}
+		return nil
+
+	case "list":
+		server := 0
+		if len(rest) > 0 && !strings.EqualFold(rest[0], "all") {
+			if server, err = parseServerNumber(rest[0], numServers); err != nil {
+				return err
+			}
+		}
+		snapshots, err := hytale.ListSnapshots(server)
+		if err != nil {
+			return err
+		}
+		return printSnapshots(snapshots, *asJSON)
+
+	case "verify", "restore", "delete":
+		if len(rest) != 1 {
+			return usagef("expected a snapshot name")
+		}
+		name := rest[0]
+		switch action {
+		case "verify":
+			snapshot, err := hytale.FindSnapshot(name)
+			if err != nil {
+				return err
+			}
+			manifest, err := hytale.VerifySnapshot(snapshot.Path)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(stdout, "%s: %d files verified\n", name, len(manifest.Files))
+			return nil
+		case "restore":
+			snapshot, err := hytale.FindSnapshot(name)
+			if err != nil {
+				return err
+			}
+			target := snapshot.Server
+			if *to != 0 {
+				target = *to
+			}
+			if target < 1 || target > numServers {
+				return fmt.Errorf("server %d does not exist (%d server(s) installed)", target, numServers)
+			}
+			tm := hytale.NewTmuxManagerFromManifest(hytale.LoadManifestOrDefault())
+			output, err := tm.RestoreSnapshot(name, target, hytale.SnapshotRestoreOptions{
+				UniverseOnly: *universeOnly,
+				NoRestart:    *noRestart,
+			})
+			fmt.Fprint(stdout, output)
+			return err
+		default:
+			if err := hytale.DeleteSnapshot(name); err != nil {
+				return err
+			}
+			fmt.Fprintf(stdout, "Deleted %s\n", name)
+			return nil
+		}
+
+	default:
+		return usagef("unknown snapshot action %q", action)
+	}
+}
+
+// printSnapshots lists HSM snapshots
+func printSnapshots(snapshots []hytale.Snapshot, asJSON bool) error {
+	if asJSON {
+		if snapshots == nil {
+			snapshots = []hytale.Snapshot{}
+		}
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(snapshots)
+	}
+
+	if len(snapshots) == 0 {
+		fmt.Fprintln(stdout, "No snapshots found")
+		return nil
+	}
+	fmt.Fprintf(stdout, "%-7s %-36s %-18s %-12s %s\n", "SERVER", "SNAPSHOT", "TIME", "SIZE", "WORLDS")
+	for _, s := range snapshots {
+		worlds := strings.Join(s.Worlds, ",")
+		if worlds == "" {
+			worlds = "-"
+		}
+		fmt.Fprintf(stdout, "%-7d %-36s %-18s %-12d %s\n", s.Server, s.Name, s.Created.Format("2006-01-02 15:04"), s.Size, worlds)
+	}
+	return nil
+}
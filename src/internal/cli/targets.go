@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sivert-io/hytale-server-manager/src/internal/hytale"
+)
+
+func runTargets(args []string) error {
+	fs := flag.NewFlagSet("targets", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print output as JSON")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		return usagef("expected list, test, ls, push, pull or rm")
+	}
+	config, err := hytale.ReadBackupConfig()
+	if err != nil {
+		return err
+	}
+
+	action, rest := positional[0], positional[1:]
+	if action == "list" {
+		if len(config.Targets) == 0 {
+			fmt.Fprintf(stdout, "No backup targets configured in %s\n", hytale.GetBackupConfigPath())
+			return nil
+		}
+		fmt.Fprintf(stdout, "%-16s %-6s %-10s %s\n", "NAME", "TYPE", "ENCRYPTED", "LOCATION")
+		for _, tc := range config.Targets {
+			location := tc.Path
+			switch tc.Type {
+			case hytale.TargetSFTP:
+				location = tc.Host + ":" + tc.Path
+			case hytale.TargetS3:
+				location = strings.TrimSuffix(tc.Endpoint, "/") + "/" + tc.Bucket + "/" + tc.Path
+			}
+			if tc.Disabled {
+				location += " (disabled)"
+			}
+			fmt.Fprintf(stdout, "%-16s %-6s %-10t %s\n", tc.Name, tc.Type, tc.EncryptionKeyFile != "", location)
+		}
+		return nil
+	}
+
+	if len(rest) == 0 {
+		return usagef("expected a target name")
+	}
+	target, tc, err := hytale.FindBackupTarget(config, rest[0])
+	if err != nil {
+		return err
+	}
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	switch action {
+	case "test":
+		// Round-trip a small file to check credentials, permissions and verification
+		tmp, err := os.CreateTemp("", "hsm-target-test-*")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(tmp.Name())
+		fmt.Fprintf(tmp, "HSM backup target test %s\n", time.Now().Format(time.RFC3339))
+		tmp.Close()
+
+		remote, err := hytale.UploadBackup(ctx, target, *tc, tmp.Name(), path.Join("hsm-test", filepath.Base(tmp.Name())), nil)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Uploaded and verified %s\n", remote)
+		back := tmp.Name() + ".download"
+		defer os.Remove(back)
+		if err := hytale.DownloadBackup(ctx, target, *tc, remote, back); err != nil {
+			return fmt.Errorf("download failed: %w", err)
+		}
+		fmt.Fprintln(stdout, "Downloaded it again")
+		if err := target.Delete(ctx, remote); err != nil {
+			return fmt.Errorf("delete failed: %w", err)
+		}
+		fmt.Fprintf(stdout, "Target %s works\n", tc.Name)
+		return nil
+
+	case "ls":
+		prefix := ""
+		if len(rest) > 1 {
+			prefix = rest[1]
+		}
+		objects, err := target.List(ctx, prefix)
+		if err != nil {
+			return err
+		}
+		if *asJSON {
+			if objects == nil {
+				objects = []hytale.RemoteObject{}
+			}
+			encoder := json.NewEncoder(stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(objects)
+		}
+		for _, obj := range objects {
+			fmt.Fprintf(stdout, "%-56s %-18s %d\n", obj.Name, obj.ModTime.Format("2006-01-02 15:04"), obj.Size)
+		}
+		return nil
+
+	case "push":
+		// Push an HSM snapshot (by name) or any local backup file
+		if len(rest) != 2 {
+			return usagef("expected a target name and a snapshot name or file")
+		}
+		localPath, remoteName := rest[1], path.Join("files", filepath.Base(rest[1]))
+		if snapshot, err := hytale.FindSnapshot(rest[1]); err == nil {
+			localPath, remoteName = snapshot.Path, hytale.SnapshotRemoteName(snapshot)
+		} else if _, err := os.Stat(localPath); err != nil {
+			return fmt.Errorf("%s is neither a snapshot nor a file", rest[1])
+		}
+		stored, err := hytale.UploadBackup(ctx, target, *tc, localPath, remoteName, printProgress)
+		fmt.Fprintln(stdout)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Uploaded and verified %s\n", stored)
+		return nil
+
+	case "pull":
+		// Download a snapshot into the local snapshot directory so it can be restored
+		if len(rest) != 2 {
+			return usagef("expected a target name and a remote snapshot name")
+		}
+		remoteName := rest[1]
+		name := strings.TrimSuffix(path.Base(remoteName), hytale.EncryptedSuffix)
+		if err := os.MkdirAll(hytale.GetSnapshotDir(), 0750); err != nil {
+			return err
+		}
+		localPath := filepath.Join(hytale.GetSnapshotDir(), name)
+		if err := hytale.DownloadBackup(ctx, target, *tc, remoteName, localPath); err != nil {
+			return err
+		}
+		manifest, err := hytale.VerifySnapshot(localPath)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Downloaded %s (%d files verified); restore it with: hsm snapshot restore %s\n", name, len(manifest.Files), name)
+		return nil
+
+	case "rm":
+		if len(rest) != 2 {
+			return usagef("expected a target name and a remote name")
+		}
+		if err := target.Delete(ctx, rest[1]); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Deleted %s from %s\n", rest[1], tc.Name)
+		return nil
+
+	default:
+		return usagef("unknown targets action %q", action)
+	}
+}
+
+// printProgress prints an updating progress line
+func printProgress(percent float64, label string) {
+	fmt.Fprintf(stdout, "\r%s: %3.0f%%", label, percent*100)
+}
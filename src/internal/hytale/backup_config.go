@@ -5,12 +5,45 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 )
 
 // BackupConfig holds backup configuration settings
 type BackupConfig struct {
 	Enabled  bool `json:"enabled"`
 	Frequency int `json:"frequency"` // in minutes
+
+	// HSM snapshots: console commands around the snapshot (empty = don't send)
+	SnapshotSaveCommand        string `json:"snapshot_save_command"`         // Flushes the world to disk
+	SnapshotHoldCommand        string `json:"snapshot_hold_command"`         // Pauses world saving while the snapshot is taken (save-off)
+	SnapshotReleaseCommand     string `json:"snapshot_release_command"`      // Resumes world saving (save-on)
+	SnapshotSaveTimeoutSeconds int    `json:"snapshot_save_timeout_seconds"` // How long to wait for the save to be confirmed
+
+	// Remote copies of snapshots (see backup_targets.go)
+	Targets []BackupTargetConfig `json:"targets,omitempty"`
+
+	// Retention for game backups and snapshots (see retention.go), optionally overridden per server
+	// ("1": {...}) and per target
+	Retention       RetentionPolicy            `json:"retention"`
+	ServerRetention map[string]RetentionPolicy `json:"server_retention,omitempty"`
+}
+
+// RetentionFor returns the retention policy that applies to a server's local backups
+func (c *BackupConfig) RetentionFor(server int) RetentionPolicy {
+	if policy, ok := c.ServerRetention[strconv.Itoa(server)]; ok {
+		return policy
+	}
+	return c.Retention
+}
+
+// DefaultBackupConfig returns the backup configuration used when backup.json is missing
+func DefaultBackupConfig() *BackupConfig {
+	return &BackupConfig{
+		Enabled:                    DefaultBackupEnabled,
+		Frequency:                  DefaultBackupFrequency,
+		SnapshotSaveCommand:        DefaultSnapshotSaveCommand,
+		SnapshotSaveTimeoutSeconds: DefaultSnapshotSaveTimeoutSeconds,
+	}
 }
 
 // GetBackupConfigPath returns the path to the shared backup config file
@@ -25,18 +58,16 @@ func ReadBackupConfig() (*BackupConfig, error) {
 	data, err := os.ReadFile(configPath)
 	if err != nil {
 		// File doesn't exist, return defaults
-		return &BackupConfig{
-			Enabled:  DefaultBackupEnabled,
-			Frequency: DefaultBackupFrequency,
-		}, nil
+		return DefaultBackupConfig(), nil
 	}
 
-	var config BackupConfig
-	if err := json.Unmarshal(data, &config); err != nil {
+	// Settings missing from the file (e.g. written by an older HSM) keep their defaults
+	config := DefaultBackupConfig()
+	if err := json.Unmarshal(data, config); err != nil {
 		return nil, fmt.Errorf("failed to parse backup config: %w", err)
 	}
 
-	return &config, nil
+	return config, nil
 }
 
 // WriteBackupConfig writes the backup configuration to the shared config file
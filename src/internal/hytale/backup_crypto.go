@@ -0,0 +1,190 @@
+package hytale
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Backups uploaded to an encrypted target are sealed in chunks with AES-256-GCM:
+//
+//	header: "HSMENC1\n" | 4-byte chunk size | 8-byte nonce prefix
+//	chunk:  ciphertext of up to chunk size bytes + 16-byte tag
+//
+// Each chunk's nonce is the prefix followed by a 4-byte chunk counter, and the last chunk is
+// sealed with additional data marking it final, so reordered or truncated files fail to decrypt.
+const (
+	encryptedMagic     = "HSMENC1\n"
+	encryptedChunkSize = 1 << 20
+
+	// EncryptedSuffix is appended to the remote name of encrypted backups
+	EncryptedSuffix = ".enc"
+)
+
+var (
+	aadChunk = []byte{0}
+	aadFinal = []byte{1}
+)
+
+// LoadEncryptionKey reads a target's encryption key file
+// A file holding 64 hex characters is used as the key directly; anything else (e.g. a passphrase
+// or 32 random bytes) is hashed with SHA-256 to get the key
+func LoadEncryptionKey(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encryption key: %w", err)
+	}
+	trimmed := strings.TrimSpace(string(data))
+	if len(trimmed) == 64 {
+		if key, err := hex.DecodeString(trimmed); err == nil {
+			return key, nil
+		}
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("encryption key file %s is empty", path)
+	}
+	sum := sha256.Sum256(data)
+	return sum[:], nil
+}
+
+// newBackupAEAD creates the AES-256-GCM cipher for a key
+func newBackupAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce builds the nonce for chunk n
+func chunkNonce(prefix []byte, n uint32) []byte {
+	nonce := make([]byte, 12)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[8:], n)
+	return nonce
+}
+
+// EncryptFile encrypts src into dst
+func EncryptFile(key []byte, src, dst string) error {
+	aead, err := newBackupAEAD(key)
+	if err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	prefix := make([]byte, 8)
+	if _, err := rand.Read(prefix); err != nil {
+		return err
+	}
+	header := make([]byte, 0, len(encryptedMagic)+12)
+	header = append(header, encryptedMagic...)
+	header = binary.BigEndian.AppendUint32(header, encryptedChunkSize)
+	header = append(header, prefix...)
+	if _, err := out.Write(header); err != nil {
+		return err
+	}
+
+	// Read one chunk ahead so the last chunk can be sealed as final
+	buf := make([]byte, encryptedChunkSize)
+	next := make([]byte, encryptedChunkSize)
+	n, err := io.ReadFull(in, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return err
+	}
+	for counter := uint32(0); ; counter++ {
+		m, nextErr := io.ReadFull(in, next)
+		if nextErr != nil && nextErr != io.ErrUnexpectedEOF && nextErr != io.EOF {
+			return nextErr
+		}
+		aad := aadChunk
+		if m == 0 {
+			aad = aadFinal
+		}
+		if _, err := out.Write(aead.Seal(nil, chunkNonce(prefix, counter), buf[:n], aad)); err != nil {
+			return err
+		}
+		if m == 0 {
+			break
+		}
+		buf, next = next, buf
+		n = m
+	}
+	return out.Close()
+}
+
+// DecryptFile decrypts src (written by EncryptFile) into dst
+func DecryptFile(key []byte, src, dst string) error {
+	aead, err := newBackupAEAD(key)
+	if err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	header := make([]byte, len(encryptedMagic)+12)
+	if _, err := io.ReadFull(in, header); err != nil || !bytes.HasPrefix(header, []byte(encryptedMagic)) {
+		return errors.New("not an HSM encrypted backup")
+	}
+	chunkSize := binary.BigEndian.Uint32(header[len(encryptedMagic):])
+	prefix := header[len(encryptedMagic)+4:]
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0640)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	buf := make([]byte, int(chunkSize)+aead.Overhead())
+	for counter := uint32(0); ; counter++ {
+		n, err := io.ReadFull(in, buf)
+		if err != nil && err != io.ErrUnexpectedEOF {
+			if err == io.EOF {
+				return errors.New("encrypted backup is truncated")
+			}
+			return err
+		}
+		// A short read can only be the final chunk; a full one may be final too
+		plain, openErr := aead.Open(nil, chunkNonce(prefix, counter), buf[:n], aadFinal)
+		final := openErr == nil
+		if !final {
+			if n < len(buf) {
+				return errors.New("failed to decrypt backup (wrong key or corrupted file)")
+			}
+			plain, openErr = aead.Open(nil, chunkNonce(prefix, counter), buf[:n], aadChunk)
+			if openErr != nil {
+				return errors.New("failed to decrypt backup (wrong key or corrupted file)")
+			}
+		}
+		if _, err := out.Write(plain); err != nil {
+			return err
+		}
+		if final {
+			// Nothing may follow the final chunk: appended data means the file was tampered with
+			if extra, _ := in.Read(make([]byte, 1)); extra > 0 {
+				return errors.New("failed to decrypt backup (data after the final chunk)")
+			}
+			return out.Close()
+		}
+	}
+}
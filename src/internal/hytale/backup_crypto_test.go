@@ -0,0 +1,132 @@
+package hytale
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEncryptDecryptRoundTrip(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 32)
+	sizes := []int{0, 1, encryptedChunkSize - 1, encryptedChunkSize, encryptedChunkSize + 1, 2*encryptedChunkSize + 5}
+	for _, size := range sizes {
+		dir := t.TempDir()
+		plain := writeBackupFile(t, size)
+		encrypted := filepath.Join(dir, "backup.enc")
+		decrypted := filepath.Join(dir, "backup")
+
+		if err := EncryptFile(key, plain, encrypted); err != nil {
+			t.Fatalf("%d bytes: EncryptFile: %v", size, err)
+		}
+		if err := DecryptFile(key, encrypted, decrypted); err != nil {
+			t.Fatalf("%d bytes: DecryptFile: %v", size, err)
+		}
+		want, _ := os.ReadFile(plain)
+		got, _ := os.ReadFile(decrypted)
+		if !bytes.Equal(got, want) {
+			t.Errorf("%d bytes: decrypted file differs from the original (%d bytes)", size, len(got))
+		}
+		sealed, _ := os.ReadFile(encrypted)
+		if size > 16 && bytes.Contains(sealed, want[:16]) {
+			t.Errorf("%d bytes: encrypted file contains plaintext", size)
+		}
+	}
+}
+
+func TestDecryptRejectsTampering(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 32)
+	dir := t.TempDir()
+	plain := writeBackupFile(t, 2*encryptedChunkSize+5)
+	encrypted := filepath.Join(dir, "backup.enc")
+	if err := EncryptFile(key, plain, encrypted); err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := os.ReadFile(encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := len(encryptedMagic) + 12
+	chunk := encryptedChunkSize + 16
+
+	flipped := append([]byte{}, sealed...)
+	flipped[header+10] ^= 0x01
+	reordered := append(append(append([]byte{}, sealed[:header]...), sealed[header+chunk:header+2*chunk]...), sealed[header:header+chunk]...)
+	reordered = append(reordered, sealed[header+2*chunk:]...)
+
+	tests := map[string]struct {
+		key  []byte
+		data []byte
+	}{
+		"wrong key":          {bytes.Repeat([]byte{0x43}, 32), sealed},
+		"flipped bit":        {key, flipped},
+		"reordered chunks":   {key, reordered},
+		"last chunk dropped": {key, sealed[:header+2*chunk]},
+		"truncated chunk":    {key, sealed[:len(sealed)-1]},
+		"appended byte":      {key, append(append([]byte{}, sealed...), 0)},
+		"appended chunk":     {key, append(append([]byte{}, sealed...), sealed[header:header+chunk]...)},
+		"not encrypted":      {key, []byte("just a tar.gz")},
+	}
+
+	// With a whole number of chunks the final chunk is full-size, so appended data isn't read
+	// as part of it and must be caught on its own
+	whole := writeBackupFile(t, 2*encryptedChunkSize)
+	wholeEncrypted := filepath.Join(dir, "whole.enc")
+	if err := EncryptFile(key, whole, wholeEncrypted); err != nil {
+		t.Fatal(err)
+	}
+	wholeSealed, err := os.ReadFile(wholeEncrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests["appended to full final chunk"] = struct {
+		key  []byte
+		data []byte
+	}{key, append(wholeSealed, "trailing garbage"...)}
+
+	for name, tt := range tests {
+		src := filepath.Join(dir, "tampered.enc")
+		if err := os.WriteFile(src, tt.data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := DecryptFile(tt.key, src, filepath.Join(dir, "out")); err == nil {
+			t.Errorf("%s: DecryptFile succeeded", name)
+		}
+	}
+}
+
+func TestLoadEncryptionKey(t *testing.T) {
+	dir := t.TempDir()
+	hexKey := strings.Repeat("ab", 32)
+	for name, tt := range map[string]struct {
+		content string
+		want    []byte
+	}{
+		"hex":        {hexKey + "\n", bytes.Repeat([]byte{0xab}, 32)},
+		"passphrase": {"correct horse battery staple", nil},
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		key, err := LoadEncryptionKey(path)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(key) != 32 {
+			t.Errorf("%s: key is %d bytes, want 32", name, len(key))
+		}
+		if tt.want != nil && !bytes.Equal(key, tt.want) {
+			t.Errorf("%s: key = %x, want %x", name, key, tt.want)
+		}
+	}
+
+	empty := filepath.Join(dir, "empty")
+	if err := os.WriteFile(empty, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadEncryptionKey(empty); err == nil {
+		t.Error("empty key file accepted")
+	}
+}
@@ -0,0 +1,497 @@
+package hytale
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// s3Target stores backups in S3-compatible object storage
+// Requests are signed with AWS Signature Version 4; uploads always use the multipart API so
+// they can be resumed part by part
+type s3Target struct {
+	config BackupTargetConfig
+	client *http.Client
+}
+
+func newS3Target(config BackupTargetConfig) *s3Target {
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	return &s3Target{config: config, client: &http.Client{Timeout: 10 * time.Minute}}
+}
+
+func (t *s3Target) Name() string { return t.config.Name }
+
+func (t *s3Target) key(remoteName string) string {
+	return strings.TrimPrefix(path.Join(t.config.Path, remoteName), "/")
+}
+
+// objectURL returns the URL of a key (or of the bucket if key is empty)
+func (t *s3Target) objectURL(key string, query url.Values) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSuffix(t.config.Endpoint, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint: %w", err)
+	}
+	if t.config.PathStyle {
+		u.Path = "/" + t.config.Bucket + "/" + key
+	} else {
+		u.Host = t.config.Bucket + "." + u.Host
+		u.Path = "/" + key
+	}
+	// Send the path encoded exactly as it is signed
+	u.RawPath = s3Escape(u.Path, true)
+	if query != nil {
+		u.RawQuery = s3CanonicalQuery(query)
+	}
+	return u, nil
+}
+
+// s3Escape percent-encodes a string the way SigV4 expects (RFC 3986 unreserved characters kept)
+func s3Escape(s string, keepSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (keepSlash && c == '/') {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// s3CanonicalQuery encodes query parameters sorted by name
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, s3Escape(k, false)+"="+s3Escape(v, false))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// sign adds SigV4 authentication headers to a request whose body hashes to payloadHash
+func (t *s3Target) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+	req.Header.Set("host", req.URL.Host)
+
+	// Canonical headers: host and every x-amz-* header, lowercased and sorted
+	var names []string
+	for name := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "host" || strings.HasPrefix(lower, "x-amz-") || lower == "content-md5" {
+			names = append(names, lower)
+		}
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(req.Header.Get(name)) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3Escape(req.URL.Path, true),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + t.config.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	signingKey := hmacSHA256([]byte("AWS4"+t.config.SecretKey), date)
+	signingKey = hmacSHA256(signingKey, t.config.Region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		t.config.AccessKey, scope, signedHeaders, signature))
+}
+
+// s3Error is the XML error body S3 returns
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// do sends a signed request with an in-memory body and returns the response
+// Non-2xx responses are turned into errors; the caller closes the body of successful ones
+func (t *s3Target) do(ctx context.Context, method, key string, query url.Values, body []byte, headers map[string]string) (*http.Response, error) {
+	u, err := t.objectURL(key, query)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	t.sign(req, sha256Hex(body), time.Now())
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		var s3err s3Error
+		if xml.Unmarshal(data, &s3err) == nil && s3err.Code != "" {
+			return nil, fmt.Errorf("%s %s: %s: %s", method, key, s3err.Code, s3err.Message)
+		}
+		return nil, fmt.Errorf("%s %s: %s", method, key, resp.Status)
+	}
+	return resp, nil
+}
+
+// doXML sends a request and decodes the XML response into out
+func (t *s3Target) doXML(ctx context.Context, method, key string, query url.Values, body []byte, out interface{}) error {
+	resp, err := t.do(ctx, method, key, query, body, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return xml.NewDecoder(resp.Body).Decode(out)
+}
+
+// s3UploadState is kept next to the local file while a multipart upload is in progress
+type s3UploadState struct {
+	Key      string    `json:"key"`
+	UploadID string    `json:"upload_id"`
+	PartSize int64     `json:"part_size"`
+	Size     int64     `json:"size"`
+	ModTime  time.Time `json:"mod_time"`
+	// The target's part ETags aren't MD5s (e.g. SSE-KMS), so Verify can only compare sizes
+	OpaqueETags bool `json:"opaque_etags,omitempty"`
+}
+
+func s3StatePath(localPath, target string) string {
+	return localPath + "." + target + ".upload.json"
+}
+
+type s3Part struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+	Size       int64  `xml:"Size"`
+}
+
+// Upload sends the file as a multipart upload. The upload ID is saved next to the local file,
+// and parts the target already has (per ListParts) are skipped when an upload is resumed
+func (t *s3Target) Upload(ctx context.Context, localPath, remoteName string, progress ProgressCallback) error {
+	key := t.key(remoteName)
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return err
+	}
+	partSize := t.config.partSize()
+	statePath := s3StatePath(localPath, t.config.Name)
+
+	// Resume the previous upload of this exact file if there is one
+	var state s3UploadState
+	uploaded := make(map[int]s3Part)
+	if data, err := os.ReadFile(statePath); err == nil && json.Unmarshal(data, &state) == nil &&
+		state.Key == key && state.Size == info.Size() && state.ModTime.Equal(info.ModTime()) {
+		partSize = state.PartSize
+		parts, err := t.listParts(ctx, key, state.UploadID)
+		if err == nil {
+			for _, p := range parts {
+				uploaded[p.PartNumber] = p
+			}
+		} else {
+			state.UploadID = "" // Upload expired or was aborted - start over
+		}
+	} else {
+		state = s3UploadState{}
+	}
+
+	if state.UploadID == "" {
+		var result struct {
+			UploadID string `xml:"UploadId"`
+		}
+		if err := t.doXML(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, nil, &result); err != nil {
+			return err
+		}
+		state = s3UploadState{Key: key, UploadID: result.UploadID, PartSize: partSize, Size: info.Size(), ModTime: info.ModTime()}
+		data, _ := json.Marshal(state)
+		if err := os.WriteFile(statePath, data, 0600); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	numParts := int((info.Size() + partSize - 1) / partSize)
+	if numParts == 0 {
+		numParts = 1
+	}
+	etags := make([]string, numParts)
+	buf := make([]byte, partSize)
+	for n := 1; n <= numParts; n++ {
+		offset := int64(n-1) * partSize
+		length := partSize
+		if offset+length > info.Size() {
+			length = info.Size() - offset
+		}
+		if _, err := file.ReadAt(buf[:length], offset); err != nil && err != io.EOF {
+			return err
+		}
+		part := buf[:length]
+		sum := md5.Sum(part)
+		etag := `"` + hex.EncodeToString(sum[:]) + `"`
+
+		// Parts can only be matched by an MD5 ETag, so targets with opaque ETags re-upload them all
+		if p, ok := uploaded[n]; ok && p.ETag == etag && p.Size == length {
+			etags[n-1] = etag
+		} else {
+			// The target checks the part against Content-MD5 and rejects it with BadDigest on a mismatch
+			query := url.Values{"partNumber": {strconv.Itoa(n)}, "uploadId": {state.UploadID}}
+			headers := map[string]string{"Content-MD5": base64.StdEncoding.EncodeToString(sum[:])}
+			resp, err := t.do(ctx, http.MethodPut, key, query, part, headers)
+			if err != nil {
+				return fmt.Errorf("part %d of %d: %w", n, numParts, err)
+			}
+			resp.Body.Close()
+			etags[n-1] = resp.Header.Get("ETag")
+			if etags[n-1] != etag {
+				state.OpaqueETags = true
+			}
+		}
+		if progress != nil {
+			progress(float64(n)/float64(numParts), fmt.Sprintf("Uploading to %s (part %d/%d)", t.config.Name, n, numParts))
+		}
+	}
+
+	var complete strings.Builder
+	complete.WriteString("<CompleteMultipartUpload>")
+	for i, etag := range etags {
+		fmt.Fprintf(&complete, "<Part><PartNumber>%d</PartNumber><ETag>%s</ETag></Part>", i+1, etag)
+	}
+	complete.WriteString("</CompleteMultipartUpload>")
+	if err := t.doXML(ctx, http.MethodPost, key, url.Values{"uploadId": {state.UploadID}}, []byte(complete.String()), nil); err != nil {
+		return err
+	}
+	// The upload state stays until Verify has used its part size and OpaqueETags
+	if state.OpaqueETags {
+		data, _ := json.Marshal(state)
+		if err := os.WriteFile(statePath, data, 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listParts returns the parts already uploaded for a multipart upload
+func (t *s3Target) listParts(ctx context.Context, key, uploadID string) ([]s3Part, error) {
+	var parts []s3Part
+	marker := ""
+	for {
+		query := url.Values{"uploadId": {uploadID}}
+		if marker != "" {
+			query.Set("part-number-marker", marker)
+		}
+		var result struct {
+			Parts       []s3Part `xml:"Part"`
+			IsTruncated bool     `xml:"IsTruncated"`
+			NextMarker  string   `xml:"NextPartNumberMarker"`
+		}
+		if err := t.doXML(ctx, http.MethodGet, key, query, nil, &result); err != nil {
+			return nil, err
+		}
+		parts = append(parts, result.Parts...)
+		if !result.IsTruncated || result.NextMarker == "" {
+			return parts, nil
+		}
+		marker = result.NextMarker
+	}
+}
+
+// Verify compares the object's size and multipart ETag (MD5 of the part MD5s) with the local file
+// Only the size is compared when the upload found the target's ETags aren't MD5s
+func (t *s3Target) Verify(ctx context.Context, localPath, remoteName string) error {
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return err
+	}
+	statePath := s3StatePath(localPath, t.config.Name)
+	var state s3UploadState
+	if data, err := os.ReadFile(statePath); err == nil {
+		_ = json.Unmarshal(data, &state)
+	}
+	resp, err := t.do(ctx, http.MethodHead, t.key(remoteName), nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.ContentLength != info.Size() {
+		return fmt.Errorf("%s is %d bytes on the target, expected %d", remoteName, resp.ContentLength, info.Size())
+	}
+
+	etag := strings.Trim(resp.Header.Get("ETag"), `"`)
+	dash := strings.LastIndex(etag, "-")
+	if dash < 0 || state.OpaqueETags {
+		// Not a multipart ETag (e.g. copied object) or not built from MD5s - size check only
+		os.Remove(statePath)
+		return nil
+	}
+	numParts, err := strconv.Atoi(etag[dash+1:])
+	if err != nil || numParts < 1 {
+		return nil
+	}
+	partSize := (info.Size() + int64(numParts) - 1) / int64(numParts)
+	if numParts > 1 {
+		// Parts are all the same size except the last; recover it from the configured size
+		partSize = t.config.partSize()
+		if state.PartSize > 0 {
+			partSize = state.PartSize
+		}
+	}
+	expected, err := multipartETag(localPath, partSize)
+	if err != nil {
+		return err
+	}
+	if expected != etag {
+		return fmt.Errorf("checksum mismatch for %s (ETag %s, expected %s)", remoteName, etag, expected)
+	}
+	os.Remove(statePath)
+	return nil
+}
+
+// multipartETag computes the ETag S3 gives a multipart upload of a file with the given part size
+func multipartETag(localPath string, partSize int64) (string, error) {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	var sums []byte
+	parts := 0
+	for {
+		hasher := md5.New()
+		n, err := io.CopyN(hasher, file, partSize)
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		if n > 0 || parts == 0 {
+			sums = append(sums, hasher.Sum(nil)...)
+			parts++
+		}
+		if n < partSize {
+			break
+		}
+	}
+	total := md5.Sum(sums)
+	return fmt.Sprintf("%s-%d", hex.EncodeToString(total[:]), parts), nil
+}
+
+func (t *s3Target) Download(ctx context.Context, remoteName, localPath string) error {
+	resp, err := t.do(ctx, http.MethodGet, t.key(remoteName), nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	out, err := os.OpenFile(localPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0640)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, resp.Body); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func (t *s3Target) List(ctx context.Context, prefix string) ([]RemoteObject, error) {
+	base := t.key("")
+	if base != "" {
+		base += "/"
+	}
+	var objects []RemoteObject
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {base + prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		var result struct {
+			Contents []struct {
+				Key          string    `xml:"Key"`
+				Size         int64     `xml:"Size"`
+				LastModified time.Time `xml:"LastModified"`
+			} `xml:"Contents"`
+			IsTruncated bool   `xml:"IsTruncated"`
+			NextToken   string `xml:"NextContinuationToken"`
+		}
+		if err := t.doXML(ctx, http.MethodGet, "", query, nil, &result); err != nil {
+			return nil, err
+		}
+		for _, c := range result.Contents {
+			objects = append(objects, RemoteObject{Name: strings.TrimPrefix(c.Key, base), Size: c.Size, ModTime: c.LastModified})
+		}
+		if !result.IsTruncated || result.NextToken == "" {
+			return objects, nil
+		}
+		token = result.NextToken
+	}
+}
+
+func (t *s3Target) Delete(ctx context.Context, remoteName string) error {
+	resp, err := t.do(ctx, http.MethodDelete, t.key(remoteName), nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,270 @@
+package hytale
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sftpTarget stores backups on a remote host through the OpenSSH sftp client in batch mode
+// Authentication is key-based (BatchMode=yes), so the host must already be in known_hosts
+type sftpTarget struct {
+	config BackupTargetConfig
+}
+
+func (t *sftpTarget) Name() string { return t.config.Name }
+
+func (t *sftpTarget) remotePath(remoteName string) string {
+	return path.Join(t.config.Path, remoteName)
+}
+
+// sftpQuote quotes a path for an sftp batch file
+func sftpQuote(p string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(p) + `"`
+}
+
+// run executes sftp batch commands and returns the output
+func (t *sftpTarget) run(ctx context.Context, batch []string) (string, error) {
+	binary := t.config.SFTPCommand
+	if binary == "" {
+		binary = "sftp"
+	}
+	args := []string{"-b", "-", "-o", "BatchMode=yes"}
+	if t.config.Port != 0 {
+		args = append(args, "-P", strconv.Itoa(t.config.Port))
+	}
+	if t.config.IdentityFile != "" {
+		args = append(args, "-i", t.config.IdentityFile)
+	}
+	host := t.config.Host
+	if t.config.User != "" {
+		host = t.config.User + "@" + host
+	}
+	args = append(args, host)
+
+	cmd := exec.CommandContext(ctx, binary, args...)
+	cmd.Stdin = strings.NewReader(strings.Join(batch, "\n") + "\n")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return stdout.String(), fmt.Errorf("sftp: %s", msg)
+	}
+	return stdout.String(), nil
+}
+
+// mkdirBatch returns batch commands creating every directory of dir ("-" ignores existing ones)
+func mkdirBatch(dir string) []string {
+	var batch []string
+	current := ""
+	if strings.HasPrefix(dir, "/") {
+		current = "/"
+	}
+	for _, part := range strings.Split(strings.Trim(dir, "/"), "/") {
+		if part == "" {
+			continue
+		}
+		current = path.Join(current, part)
+		batch = append(batch, "-mkdir "+sftpQuote(current))
+	}
+	return batch
+}
+
+// Upload uses reput to continue a partial <name>.partial, then renames it into place
+func (t *sftpTarget) Upload(ctx context.Context, localPath, remoteName string, progress ProgressCallback) error {
+	dest := t.remotePath(remoteName)
+	partial := dest + ".partial"
+
+	// reput fails if there is nothing to resume, so fall back to a plain put
+	batch := mkdirBatch(path.Dir(dest))
+	if _, err := t.stat(ctx, partial); err == nil {
+		batch = append(batch, fmt.Sprintf("reput %s %s", sftpQuote(localPath), sftpQuote(partial)))
+	} else {
+		batch = append(batch, fmt.Sprintf("put %s %s", sftpQuote(localPath), sftpQuote(partial)))
+	}
+	batch = append(batch, "-rm "+sftpQuote(dest), fmt.Sprintf("rename %s %s", sftpQuote(partial), sftpQuote(dest)))
+
+	if progress != nil {
+		progress(0, "Uploading to "+t.config.Name)
+	}
+	_, err := t.run(ctx, batch)
+	if err == nil && progress != nil {
+		progress(1, "Uploaded to "+t.config.Name)
+	}
+	return err
+}
+
+// stat returns the size of a remote file
+func (t *sftpTarget) stat(ctx context.Context, remotePath string) (int64, error) {
+	output, err := t.run(ctx, []string{"ls -ln " + sftpQuote(remotePath)})
+	if err != nil {
+		return 0, err
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if obj, ok := parseSFTPListing(line); ok {
+			return obj.Size, nil
+		}
+	}
+	return 0, fmt.Errorf("%s not found", remotePath)
+}
+
+// Verify compares sizes, then the SHA-256 of both copies
+// sftp can't checksum a remote file, so the remote SHA-256 comes from sha256sum over ssh or,
+// on hosts that only allow sftp, from downloading the file again
+func (t *sftpTarget) Verify(ctx context.Context, localPath, remoteName string) error {
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return err
+	}
+	size, err := t.stat(ctx, t.remotePath(remoteName))
+	if err != nil {
+		return err
+	}
+	if size != info.Size() {
+		return fmt.Errorf("%s is %d bytes on the target, expected %d", remoteName, size, info.Size())
+	}
+
+	want, err := fileSHA256(localPath)
+	if err != nil {
+		return err
+	}
+	got, err := t.remoteSHA256(ctx, t.remotePath(remoteName))
+	if err != nil {
+		got, err = t.downloadSHA256(ctx, localPath, remoteName)
+		if err != nil {
+			return fmt.Errorf("failed to checksum %s: %w", remoteName, err)
+		}
+	}
+	if got != want {
+		return fmt.Errorf("checksum mismatch for %s (sha256 %s, expected %s)", remoteName, got, want)
+	}
+	return nil
+}
+
+// remoteSHA256 runs sha256sum on the remote host over ssh
+func (t *sftpTarget) remoteSHA256(ctx context.Context, remotePath string) (string, error) {
+	binary := t.config.SSHCommand
+	if binary == "" {
+		binary = "ssh"
+	}
+	args := []string{"-o", "BatchMode=yes"}
+	if t.config.Port != 0 {
+		args = append(args, "-p", strconv.Itoa(t.config.Port))
+	}
+	if t.config.IdentityFile != "" {
+		args = append(args, "-i", t.config.IdentityFile)
+	}
+	host := t.config.Host
+	if t.config.User != "" {
+		host = t.config.User + "@" + host
+	}
+	// The remote shell parses the command, so the path is quoted for sh
+	quoted := "'" + strings.ReplaceAll(remotePath, "'", `'\''`) + "'"
+	args = append(args, host, "sha256sum -- "+quoted)
+
+	output, err := exec.CommandContext(ctx, binary, args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("ssh: %w", err)
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 || len(fields[0]) != 64 {
+		return "", fmt.Errorf("unexpected sha256sum output %q", strings.TrimSpace(string(output)))
+	}
+	return strings.ToLower(fields[0]), nil
+}
+
+// downloadSHA256 downloads a stored file next to the local copy and returns its SHA-256
+func (t *sftpTarget) downloadSHA256(ctx context.Context, localPath, remoteName string) (string, error) {
+	tmp := localPath + "." + t.config.Name + ".verify"
+	os.Remove(tmp) // Download would resume a leftover copy with reget
+	defer os.Remove(tmp)
+	if err := t.Download(ctx, remoteName, tmp); err != nil {
+		return "", err
+	}
+	return fileSHA256(tmp)
+}
+
+// Download uses reget so an interrupted download continues where it stopped
+func (t *sftpTarget) Download(ctx context.Context, remoteName, localPath string) error {
+	verb := "get"
+	if _, err := os.Stat(localPath); err == nil {
+		verb = "reget"
+	}
+	_, err := t.run(ctx, []string{fmt.Sprintf("%s %s %s", verb, sftpQuote(t.remotePath(remoteName)), sftpQuote(localPath))})
+	return err
+}
+
+// List walks the target directory recursively (one sftp session per directory)
+func (t *sftpTarget) List(ctx context.Context, prefix string) ([]RemoteObject, error) {
+	var objects []RemoteObject
+	var walk func(dir string) error
+	walk = func(dir string) error {
+		output, err := t.run(ctx, []string{"ls -ln " + sftpQuote(path.Join(t.config.Path, dir))})
+		if err != nil {
+			if dir == "" && strings.Contains(err.Error(), "not found") {
+				return nil
+			}
+			return err
+		}
+		for _, line := range strings.Split(output, "\n") {
+			obj, ok := parseSFTPListing(line)
+			if !ok {
+				continue
+			}
+			name := path.Join(dir, path.Base(obj.Name))
+			if strings.HasPrefix(line, "d") {
+				if err := walk(name); err != nil {
+					return err
+				}
+				continue
+			}
+			if strings.HasSuffix(name, ".partial") || !strings.HasPrefix(name, prefix) {
+				continue
+			}
+			obj.Name = name
+			objects = append(objects, obj)
+		}
+		return nil
+	}
+	return objects, walk("")
+}
+
+func (t *sftpTarget) Delete(ctx context.Context, remoteName string) error {
+	_, err := t.run(ctx, []string{"rm " + sftpQuote(t.remotePath(remoteName))})
+	return err
+}
+
+// parseSFTPListing parses a line of `ls -ln` output:
+// -rw-r--r--    1 1000     1000      1048576 Jan 10 12:00 /backups/snapshots/server-1.tar.gz
+func parseSFTPListing(line string) (RemoteObject, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 9 || (line[0] != '-' && line[0] != 'd') {
+		return RemoteObject{}, false
+	}
+	size, err := strconv.ParseInt(fields[4], 10, 64)
+	if err != nil {
+		return RemoteObject{}, false
+	}
+	stamp := strings.Join(fields[5:8], " ")
+	modTime, err := time.Parse("Jan _2 15:04", stamp)
+	if err == nil {
+		modTime = modTime.AddDate(time.Now().Year(), 0, 0)
+	} else {
+		modTime, _ = time.Parse("Jan _2 2006", stamp)
+	}
+	return RemoteObject{
+		Name:    strings.Join(fields[8:], " "),
+		Size:    size,
+		ModTime: modTime,
+	}, true
+}
@@ -0,0 +1,99 @@
+package hytale
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Fake sftp and ssh clients that treat the local filesystem as the remote host
+const (
+	fakeSFTP = `#!/bin/sh
+while read -r cmd rest; do
+	eval "set -- $rest"
+	case "$cmd" in
+	ls) shift; size=$(wc -c < "$1") || exit 1; echo "-rw-r--r--    1 1000     1000  $size Jan 10 12:00 $1" ;;
+	get|reget) cp "$1" "$2" || exit 1 ;;
+	*) echo "fake sftp: $cmd not supported" >&2; exit 1 ;;
+	esac
+done
+`
+	fakeSSH = `#!/bin/sh
+for arg; do command=$arg; done
+eval "$command"
+`
+	fakeSSHSFTPOnly = `#!/bin/sh
+echo "This service allows sftp connections only." >&2
+exit 1
+`
+)
+
+// newFakeSFTPTarget returns a target whose remote directory is a local one, and that directory
+func newFakeSFTPTarget(t *testing.T, ssh string) (*sftpTarget, string) {
+	t.Helper()
+	if _, err := exec.LookPath("sha256sum"); err != nil {
+		t.Skip("sha256sum not available")
+	}
+	bin := t.TempDir()
+	for name, script := range map[string]string{"sftp": fakeSFTP, "ssh": ssh} {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte(script), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	remote := t.TempDir()
+	return &sftpTarget{config: BackupTargetConfig{
+		Name:        "fake",
+		Type:        TargetSFTP,
+		Host:        "backup.example.com",
+		Path:        remote,
+		SFTPCommand: filepath.Join(bin, "sftp"),
+		SSHCommand:  filepath.Join(bin, "ssh"),
+	}}, remote
+}
+
+func TestSFTPVerify(t *testing.T) {
+	for name, ssh := range map[string]string{"sha256sum over ssh": fakeSSH, "sftp only": fakeSSHSFTPOnly} {
+		t.Run(name, func(t *testing.T) {
+			target, remote := newFakeSFTPTarget(t, ssh)
+			local := writeBackupFile(t, 4096)
+			data, err := os.ReadFile(local)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stored := filepath.Join(remote, "server 1's backup.tar.gz")
+			if err := os.WriteFile(stored, data, 0644); err != nil {
+				t.Fatal(err)
+			}
+			ctx := context.Background()
+
+			if err := target.Verify(ctx, local, filepath.Base(stored)); err != nil {
+				t.Fatalf("Verify of an identical copy: %v", err)
+			}
+
+			// Same size, different content
+			data[100] ^= 0xff
+			if err := os.WriteFile(stored, data, 0644); err != nil {
+				t.Fatal(err)
+			}
+			err = target.Verify(ctx, local, filepath.Base(stored))
+			if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+				t.Fatalf("Verify of a changed copy: got %v, want a checksum mismatch", err)
+			}
+
+			if err := os.WriteFile(stored, data[:100], 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := target.Verify(ctx, local, filepath.Base(stored)); err == nil {
+				t.Fatal("Verify of a truncated copy succeeded")
+			}
+
+			leftovers, _ := filepath.Glob(local + ".*")
+			if len(leftovers) > 0 {
+				t.Errorf("Verify left %v behind", leftovers)
+			}
+		})
+	}
+}
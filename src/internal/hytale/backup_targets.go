@@ -0,0 +1,389 @@
+package hytale
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Backup target types
+const (
+	TargetLocal = "local" // A second disk or NFS mount
+	TargetSFTP  = "sftp"  // A remote host reached with the sftp binary
+	TargetS3    = "s3"    // S3-compatible object storage (AWS, MinIO, Backblaze B2, ...)
+)
+
+// DefaultTargetPartSizeMB is the multipart upload part size used when a target doesn't set one
+const DefaultTargetPartSizeMB = 16
+
+// BackupTargetConfig configures a remote backup target in shared/backup.json
+type BackupTargetConfig struct {
+	Name string `json:"name"`
+	Type string `json:"type"` // local, sftp or s3
+	Path string `json:"path"` // Directory (local, sftp) or key prefix (s3)
+
+	// SFTP
+	Host         string `json:"host,omitempty"`
+	Port         int    `json:"port,omitempty"`
+	User         string `json:"user,omitempty"`
+	IdentityFile string `json:"identity_file,omitempty"`
+	SFTPCommand  string `json:"sftp_command,omitempty"` // sftp binary to run (default: sftp)
+	SSHCommand   string `json:"ssh_command,omitempty"`  // ssh binary used to checksum uploads (default: ssh)
+
+	// S3
+	Endpoint  string `json:"endpoint,omitempty"` // e.g. https://s3.eu-west-1.amazonaws.com or http://127.0.0.1:9000
+	Region    string `json:"region,omitempty"`
+	Bucket    string `json:"bucket,omitempty"`
+	AccessKey string `json:"access_key,omitempty"`
+	SecretKey string `json:"secret_key,omitempty"`
+	PathStyle bool   `json:"path_style,omitempty"` // Use endpoint/bucket/key URLs (MinIO) instead of bucket.endpoint/key
+
+	// Common
+	PartSizeMB        int              `json:"part_size_mb,omitempty"`
+	EncryptionKeyFile string           `json:"encryption_key_file,omitempty"` // Encrypt backups before they leave the host
+	Retention         *RetentionPolicy `json:"retention,omitempty"`           // Overrides the shared retention policy for this target
+	Disabled          bool             `json:"disabled,omitempty"`
+}
+
+// RemoteObject is a backup stored on a target
+type RemoteObject struct {
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// BackupTarget stores backup archives somewhere other than the servers' disk
+// Remote names are slash-separated paths relative to the target's configured path
+type BackupTarget interface {
+	// Name returns the target's configured name
+	Name() string
+	// Upload copies a local file to the target, resuming an earlier interrupted upload of the same file
+	Upload(ctx context.Context, localPath, remoteName string, progress ProgressCallback) error
+	// Verify checks that the stored copy matches the local file
+	Verify(ctx context.Context, localPath, remoteName string) error
+	// Download copies a stored backup to a local file
+	Download(ctx context.Context, remoteName, localPath string) error
+	// List returns the backups stored under prefix
+	List(ctx context.Context, prefix string) ([]RemoteObject, error)
+	// Delete removes a stored backup
+	Delete(ctx context.Context, remoteName string) error
+}
+
+// NewBackupTarget creates the target described by a config entry
+func NewBackupTarget(config BackupTargetConfig) (BackupTarget, error) {
+	if config.Name == "" {
+		return nil, fmt.Errorf("backup target has no name")
+	}
+	switch config.Type {
+	case TargetLocal:
+		if config.Path == "" {
+			return nil, fmt.Errorf("backup target %s: path is required", config.Name)
+		}
+		return &localTarget{config: config}, nil
+	case TargetSFTP:
+		if config.Host == "" {
+			return nil, fmt.Errorf("backup target %s: host is required", config.Name)
+		}
+		return &sftpTarget{config: config}, nil
+	case TargetS3:
+		if config.Endpoint == "" || config.Bucket == "" {
+			return nil, fmt.Errorf("backup target %s: endpoint and bucket are required", config.Name)
+		}
+		return newS3Target(config), nil
+	default:
+		return nil, fmt.Errorf("backup target %s: unknown type %q", config.Name, config.Type)
+	}
+}
+
+// FindBackupTarget returns the configured target with the given name
+func FindBackupTarget(config *BackupConfig, name string) (BackupTarget, *BackupTargetConfig, error) {
+	for i := range config.Targets {
+		if config.Targets[i].Name == name {
+			target, err := NewBackupTarget(config.Targets[i])
+			return target, &config.Targets[i], err
+		}
+	}
+	return nil, nil, fmt.Errorf("no backup target named %q in %s", name, GetBackupConfigPath())
+}
+
+// partSize returns a target's multipart part size in bytes
+func (c BackupTargetConfig) partSize() int64 {
+	if c.PartSizeMB <= 0 {
+		return DefaultTargetPartSizeMB << 20
+	}
+	return int64(c.PartSizeMB) << 20
+}
+
+// UploadBackup encrypts (if the target is configured to), uploads and verifies a backup file
+// Returns the remote name the backup was stored under
+func UploadBackup(ctx context.Context, target BackupTarget, config BackupTargetConfig, localPath, remoteName string, progress ProgressCallback) (string, error) {
+	uploadPath := localPath
+	if config.EncryptionKeyFile != "" {
+		key, err := LoadEncryptionKey(config.EncryptionKeyFile)
+		if err != nil {
+			return "", err
+		}
+		// The encrypted copy is kept until the upload has been verified so an interrupted
+		// upload resumes with identical bytes
+		uploadPath = localPath + "." + config.Name + EncryptedSuffix
+		if _, err := os.Stat(uploadPath); os.IsNotExist(err) {
+			if err := EncryptFile(key, localPath, uploadPath+".tmp"); err != nil {
+				os.Remove(uploadPath + ".tmp")
+				return "", fmt.Errorf("failed to encrypt backup: %w", err)
+			}
+			if err := os.Rename(uploadPath+".tmp", uploadPath); err != nil {
+				return "", err
+			}
+		}
+		remoteName += EncryptedSuffix
+	}
+
+	if err := target.Upload(ctx, uploadPath, remoteName, progress); err != nil {
+		return "", fmt.Errorf("upload to %s failed: %w", target.Name(), err)
+	}
+	if err := target.Verify(ctx, uploadPath, remoteName); err != nil {
+		return "", fmt.Errorf("verification on %s failed: %w", target.Name(), err)
+	}
+	if uploadPath != localPath {
+		os.Remove(uploadPath)
+	}
+	return remoteName, nil
+}
+
+// DownloadBackup downloads a backup from a target, decrypting it if it was encrypted
+func DownloadBackup(ctx context.Context, target BackupTarget, config BackupTargetConfig, remoteName, localPath string) error {
+	if !strings.HasSuffix(remoteName, EncryptedSuffix) {
+		return target.Download(ctx, remoteName, localPath)
+	}
+	if config.EncryptionKeyFile == "" {
+		return fmt.Errorf("%s is encrypted but target %s has no encryption_key_file", remoteName, config.Name)
+	}
+	key, err := LoadEncryptionKey(config.EncryptionKeyFile)
+	if err != nil {
+		return err
+	}
+	encrypted := localPath + EncryptedSuffix
+	if err := target.Download(ctx, remoteName, encrypted); err != nil {
+		return err
+	}
+	defer os.Remove(encrypted)
+	if err := DecryptFile(key, encrypted, localPath); err != nil {
+		os.Remove(localPath)
+		return err
+	}
+	return nil
+}
+
+// ReplicateToTargets uploads a backup file to every enabled target in the backup config
+// Returns one line per target describing the outcome; failures don't stop the other targets
+func ReplicateToTargets(ctx context.Context, localPath, remoteName string) ([]string, error) {
+	config, err := ReadBackupConfig()
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	failed := 0
+	for _, tc := range config.Targets {
+		if tc.Disabled {
+			continue
+		}
+		target, err := NewBackupTarget(tc)
+		if err == nil {
+			var stored string
+			stored, err = UploadBackup(ctx, target, tc, localPath, remoteName, nil)
+			if err == nil {
+				lines = append(lines, fmt.Sprintf("%s: uploaded %s (verified)", tc.Name, stored))
+				continue
+			}
+		}
+		lines = append(lines, fmt.Sprintf("%s: %v", tc.Name, err))
+		failed++
+	}
+	if failed > 0 {
+		return lines, fmt.Errorf("%d of %d backup target(s) failed", failed, len(lines))
+	}
+	return lines, nil
+}
+
+// SnapshotRemoteName returns the remote name a snapshot is stored under on targets
+func SnapshotRemoteName(snapshot *Snapshot) string {
+	return path.Join("snapshots", snapshot.Name)
+}
+
+// fileSHA256 returns the hex SHA-256 of a file
+func fileSHA256(p string) (string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// progressReader reports how much of a file has been read through a ProgressCallback
+type progressReader struct {
+	r        io.Reader
+	done     int64
+	total    int64
+	label    string
+	progress ProgressCallback
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.done += int64(n)
+	if p.progress != nil && p.total > 0 {
+		p.progress(float64(p.done)/float64(p.total), p.label)
+	}
+	return n, err
+}
+
+// localTarget stores backups in a directory, typically on a second disk or an NFS mount
+type localTarget struct {
+	config BackupTargetConfig
+}
+
+func (t *localTarget) Name() string { return t.config.Name }
+
+func (t *localTarget) remotePath(remoteName string) string {
+	return filepath.Join(t.config.Path, filepath.FromSlash(remoteName))
+}
+
+// Upload copies into <name>.partial, appending to what an interrupted copy left behind
+func (t *localTarget) Upload(ctx context.Context, localPath, remoteName string, progress ProgressCallback) error {
+	dest := t.remotePath(remoteName)
+	if err := os.MkdirAll(filepath.Dir(dest), 0750); err != nil {
+		return err
+	}
+	src, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	partial := dest + ".partial"
+	out, err := os.OpenFile(partial, os.O_CREATE|os.O_WRONLY, 0640)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	offset, err := out.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if offset > info.Size() {
+		// Left over from a different file - start again
+		if err := out.Truncate(0); err != nil {
+			return err
+		}
+		offset, _ = out.Seek(0, io.SeekStart)
+	}
+	if _, err := src.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	reader := &progressReader{r: src, done: offset, total: info.Size(), label: "Uploading to " + t.config.Name, progress: progress}
+	buf := make([]byte, 1<<20)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, readErr := reader.Read(buf)
+		if n > 0 {
+			if _, err := out.Write(buf[:n]); err != nil {
+				return err
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+	if err := out.Sync(); err != nil {
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(partial, dest)
+}
+
+// Verify compares the SHA-256 of both copies
+func (t *localTarget) Verify(ctx context.Context, localPath, remoteName string) error {
+	want, err := fileSHA256(localPath)
+	if err != nil {
+		return err
+	}
+	got, err := fileSHA256(t.remotePath(remoteName))
+	if err != nil {
+		return err
+	}
+	if want != got {
+		return fmt.Errorf("checksum mismatch for %s", remoteName)
+	}
+	return nil
+}
+
+func (t *localTarget) Download(ctx context.Context, remoteName, localPath string) error {
+	src, err := os.Open(t.remotePath(remoteName))
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	out, err := os.OpenFile(localPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0640)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, src); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func (t *localTarget) List(ctx context.Context, prefix string) ([]RemoteObject, error) {
+	root := t.config.Path
+	var objects []RemoteObject
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && p == root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if info.IsDir() || strings.HasSuffix(p, ".partial") {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, p)
+		rel = filepath.ToSlash(rel)
+		if strings.HasPrefix(rel, prefix) {
+			objects = append(objects, RemoteObject{Name: rel, Size: info.Size(), ModTime: info.ModTime()})
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return objects, nil
+}
+
+func (t *localTarget) Delete(ctx context.Context, remoteName string) error {
+	return os.Remove(t.remotePath(remoteName))
+}
@@ -0,0 +1,186 @@
+package hytale
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Integration tests against real backup targets. They only run when the target is configured:
+//
+//	HSM_TEST_SFTP_HOST      sshd to upload to (must be in known_hosts, key-based login)
+//	HSM_TEST_SFTP_PORT      optional
+//	HSM_TEST_SFTP_USER      optional
+//	HSM_TEST_SFTP_IDENTITY  optional private key
+//	HSM_TEST_SFTP_PATH      remote directory to test in (default: hsm-integration)
+//
+//	HSM_TEST_S3_ENDPOINT    e.g. http://127.0.0.1:9000 for MinIO
+//	HSM_TEST_S3_BUCKET      existing bucket to test in
+//	HSM_TEST_S3_ACCESS_KEY
+//	HSM_TEST_S3_SECRET_KEY
+//	HSM_TEST_S3_REGION      optional (default: us-east-1)
+//	HSM_TEST_S3_PATH_STYLE  optional, default true (MinIO); set to false for AWS
+//
+// Everything is stored under a unique prefix that is deleted afterwards.
+
+func integrationSFTPConfig(t *testing.T) BackupTargetConfig {
+	host := os.Getenv("HSM_TEST_SFTP_HOST")
+	if host == "" {
+		t.Skip("HSM_TEST_SFTP_HOST not set")
+	}
+	config := BackupTargetConfig{
+		Name:         "sftp-integration",
+		Type:         TargetSFTP,
+		Host:         host,
+		User:         os.Getenv("HSM_TEST_SFTP_USER"),
+		IdentityFile: os.Getenv("HSM_TEST_SFTP_IDENTITY"),
+		Path:         os.Getenv("HSM_TEST_SFTP_PATH"),
+	}
+	if config.Path == "" {
+		config.Path = "hsm-integration"
+	}
+	if port := os.Getenv("HSM_TEST_SFTP_PORT"); port != "" {
+		n, err := strconv.Atoi(port)
+		if err != nil {
+			t.Fatalf("invalid HSM_TEST_SFTP_PORT: %v", err)
+		}
+		config.Port = n
+	}
+	return config
+}
+
+func integrationS3Config(t *testing.T) BackupTargetConfig {
+	endpoint := os.Getenv("HSM_TEST_S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("HSM_TEST_S3_ENDPOINT not set")
+	}
+	return BackupTargetConfig{
+		Name:      "s3-integration",
+		Type:      TargetS3,
+		Endpoint:  endpoint,
+		Bucket:    os.Getenv("HSM_TEST_S3_BUCKET"),
+		AccessKey: os.Getenv("HSM_TEST_S3_ACCESS_KEY"),
+		SecretKey: os.Getenv("HSM_TEST_S3_SECRET_KEY"),
+		Region:    os.Getenv("HSM_TEST_S3_REGION"),
+		PathStyle: os.Getenv("HSM_TEST_S3_PATH_STYLE") != "false",
+		// S3 requires every part but the last to be at least 5 MB
+		PartSizeMB: 5,
+	}
+}
+
+func TestIntegrationSFTPTarget(t *testing.T) {
+	testIntegrationTarget(t, integrationSFTPConfig(t), 3<<20/2)
+}
+
+func TestIntegrationS3Target(t *testing.T) {
+	testIntegrationTarget(t, integrationS3Config(t), 11<<20) // 3 parts
+}
+
+// testIntegrationTarget runs a target through upload, verify, list, download, tamper detection
+// and an encrypted round trip, using names with spaces and quotes
+func testIntegrationTarget(t *testing.T, config BackupTargetConfig, size int) {
+	prefix := fmt.Sprintf("hsm-test-%d", time.Now().UnixNano())
+	config.Path = path.Join(config.Path, prefix)
+	target, err := NewBackupTarget(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	var stored []string
+	defer func() {
+		for _, name := range stored {
+			if err := target.Delete(context.Background(), name); err != nil {
+				t.Logf("cleanup: %v", err)
+			}
+		}
+		// sftp leaves the directories Upload created
+		if sftp, ok := target.(*sftpTarget); ok {
+			_, _ = sftp.run(context.Background(), []string{
+				"-rmdir " + sftpQuote(path.Join(config.Path, "snapshots")),
+				"-rmdir " + sftpQuote(config.Path),
+			})
+		}
+	}()
+
+	local := writeBackupFile(t, size)
+	want, err := os.ReadFile(local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remoteName := "snapshots/server 1's backup.tar.gz"
+
+	// Upload, interrupting S3 after its first part so the second Upload resumes
+	if config.Type == TargetS3 {
+		interrupted, stop := context.WithCancel(ctx)
+		err := target.Upload(interrupted, local, remoteName, func(percent float64, label string) { stop() })
+		stop()
+		if err == nil {
+			t.Fatal("Upload wasn't interrupted")
+		}
+	}
+	if err := target.Upload(ctx, local, remoteName, nil); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	stored = append(stored, remoteName)
+	if err := target.Verify(ctx, local, remoteName); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	objects, err := target.List(ctx, "snapshots/")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(objects) != 1 || objects[0].Name != remoteName || objects[0].Size != int64(size) {
+		t.Errorf("List = %+v, want %s (%d bytes)", objects, remoteName, size)
+	}
+
+	downloaded := filepath.Join(t.TempDir(), "downloaded.tar.gz")
+	if err := target.Download(ctx, remoteName, downloaded); err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if got, _ := os.ReadFile(downloaded); !bytes.Equal(got, want) {
+		t.Error("downloaded copy differs from the upload")
+	}
+
+	// A stored copy with the same size but other content must fail verification
+	changed := append([]byte{}, want...)
+	changed[len(changed)/2] ^= 0xff
+	other := filepath.Join(t.TempDir(), "other.tar.gz")
+	if err := os.WriteFile(other, changed, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := target.Upload(ctx, other, remoteName, nil); err != nil {
+		t.Fatalf("Upload of the changed copy: %v", err)
+	}
+	if err := target.Verify(ctx, local, remoteName); err == nil {
+		t.Error("Verify accepted a stored copy with different content")
+	}
+
+	// Encrypted round trip through UploadBackup and DownloadBackup
+	keyFile := filepath.Join(t.TempDir(), "backup.key")
+	if err := os.WriteFile(keyFile, []byte(strings.Repeat("ab", 32)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	config.EncryptionKeyFile = keyFile
+	encryptedName, err := UploadBackup(ctx, target, config, local, "snapshots/encrypted backup.tar.gz", nil)
+	if err != nil {
+		t.Fatalf("UploadBackup: %v", err)
+	}
+	stored = append(stored, encryptedName)
+	restored := filepath.Join(t.TempDir(), "restored.tar.gz")
+	if err := DownloadBackup(ctx, target, config, encryptedName, restored); err != nil {
+		t.Fatalf("DownloadBackup: %v", err)
+	}
+	if got, _ := os.ReadFile(restored); !bytes.Equal(got, want) {
+		t.Error("decrypted download differs from the original")
+	}
+}
@@ -0,0 +1,369 @@
+package hytale
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GameBackup is a backup archive written by the game's own --backup option
+type GameBackup struct {
+	Server int       `json:"server"`
+	Name   string    `json:"name"`
+	Path   string    `json:"path"`
+	Size   int64     `json:"size"`
+	Time   time.Time `json:"time"`
+	Worlds []string  `json:"worlds,omitempty"` // Only filled in for zip archives (tar.gz needs a full read - see InspectGameBackup)
+}
+
+// BackupInspection describes the contents of a backup archive
+type BackupInspection struct {
+	Entries          int      `json:"entries"`
+	UncompressedSize int64    `json:"uncompressed_size"`
+	Worlds           []string `json:"worlds"`
+	Root             string   `json:"root"` // Directory prefix the universe is stored under in the archive ("" if at the top)
+	TopLevel         []string `json:"top_level"`
+}
+
+// GetServerBackupDir returns the directory the game writes a server's backups to
+// HSM passes it to the server with --backup-dir so backups are always found in the same place
+func GetServerBackupDir(serverNum int) string {
+	return filepath.Join(GetServerDir(serverNum), "backups")
+}
+
+// isBackupArchive checks if a file name looks like a supported backup archive
+func isBackupArchive(name string) bool {
+	return strings.HasSuffix(name, ".zip") || strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, ".tgz")
+}
+
+// ListGameBackups returns a server's game backups, newest first
+func ListGameBackups(server int) ([]GameBackup, error) {
+	dir := GetServerBackupDir(server)
+	var backups []GameBackup
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if info.IsDir() || !isBackupArchive(info.Name()) {
+			return nil
+		}
+		rel, _ := filepath.Rel(dir, path)
+		backup := GameBackup{
+			Server: server,
+			Name:   rel,
+			Path:   path,
+			Size:   info.Size(),
+			Time:   info.ModTime(),
+		}
+		if strings.HasSuffix(path, ".zip") {
+			if inspection, err := InspectGameBackup(path); err == nil {
+				backup.Worlds = inspection.Worlds
+			}
+		}
+		backups = append(backups, backup)
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Time.After(backups[j].Time)
+	})
+	return backups, nil
+}
+
+// FindGameBackup looks up a backup of a server by name (as returned by ListGameBackups)
+func FindGameBackup(server int, name string) (*GameBackup, error) {
+	backups, err := ListGameBackups(server)
+	if err != nil {
+		return nil, err
+	}
+	for i := range backups {
+		if backups[i].Name == name {
+			return &backups[i], nil
+		}
+	}
+	return nil, fmt.Errorf("backup %q not found for server %d", name, server)
+}
+
+// walkArchive calls fn for every entry of a zip or tar.gz archive
+// r is only valid during the call and is nil for directories
+func walkArchive(path string, fn func(name string, size int64, mode os.FileMode, r io.Reader) error) error {
+	if strings.HasSuffix(path, ".zip") {
+		zr, err := zip.OpenReader(path)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", filepath.Base(path), err)
+		}
+		defer zr.Close()
+		for _, f := range zr.File {
+			if f.FileInfo().IsDir() {
+				if err := fn(f.Name, 0, f.Mode(), nil); err != nil {
+					return err
+				}
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", f.Name, err)
+			}
+			err = fn(f.Name, int64(f.UncompressedSize64), f.Mode(), rc)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", filepath.Base(path), err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = fn(hdr.Name, 0, os.FileMode(hdr.Mode)|os.ModeDir, nil)
+		case tar.TypeReg:
+			err = fn(hdr.Name, hdr.Size, os.FileMode(hdr.Mode), tr)
+		default:
+			// Links and special files are never part of a world backup
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// InspectGameBackup lists what a backup archive contains without extracting it
+// Lists entry names only: zip archives are read from their central directory, tar.gz in one pass
+func InspectGameBackup(path string) (*BackupInspection, error) {
+	inspection := &BackupInspection{}
+	worlds := make(map[string]bool)
+	topLevel := make(map[string]bool)
+	var names []string
+
+	collect := func(name string, size int64) {
+		name = strings.TrimPrefix(filepath.ToSlash(name), "./")
+		if name == "" {
+			return
+		}
+		inspection.Entries++
+		inspection.UncompressedSize += size
+		names = append(names, name)
+		topLevel[strings.SplitN(name, "/", 2)[0]] = true
+	}
+
+	if strings.HasSuffix(path, ".zip") {
+		zr, err := zip.OpenReader(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", filepath.Base(path), err)
+		}
+		for _, f := range zr.File {
+			collect(f.Name, int64(f.UncompressedSize64))
+		}
+		zr.Close()
+	} else {
+		err := walkArchive(path, func(name string, size int64, _ os.FileMode, _ io.Reader) error {
+			collect(name, size)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	inspection.Root = archiveUniverseRoot(names)
+	for _, name := range names {
+		rel := strings.TrimPrefix(name, inspection.Root)
+		parts := strings.Split(rel, "/")
+		if len(parts) >= 2 && parts[0] == "worlds" && parts[1] != "" {
+			worlds[parts[1]] = true
+		}
+	}
+
+	for world := range worlds {
+		inspection.Worlds = append(inspection.Worlds, world)
+	}
+	sort.Strings(inspection.Worlds)
+	for name := range topLevel {
+		inspection.TopLevel = append(inspection.TopLevel, name)
+	}
+	sort.Strings(inspection.TopLevel)
+	return inspection, nil
+}
+
+// archiveUniverseRoot works out where the universe starts inside an archive
+// Backups either hold the universe contents directly (worlds/, players/, ...) or
+// wrap them in a single directory such as universe/
+func archiveUniverseRoot(names []string) string {
+	for _, name := range names {
+		if idx := strings.Index(name, "worlds/"); idx >= 0 && (idx == 0 || name[idx-1] == '/') {
+			return name[:idx]
+		}
+	}
+	// No worlds directory - strip a single wrapping directory if everything is inside one
+	root := ""
+	for _, name := range names {
+		first := strings.SplitN(name, "/", 2)[0] + "/"
+		if !strings.Contains(name, "/") {
+			return ""
+		}
+		if root == "" {
+			root = first
+		} else if root != first {
+			return ""
+		}
+	}
+	return root
+}
+
+// extractArchive extracts the entries of an archive under root into dest
+func extractArchive(path, root, dest string) error {
+	destClean := filepath.Clean(dest) + string(os.PathSeparator)
+	return walkArchive(path, func(name string, _ int64, mode os.FileMode, r io.Reader) error {
+		name = strings.TrimPrefix(filepath.ToSlash(name), "./")
+		if !strings.HasPrefix(name, root) {
+			return nil
+		}
+		rel := strings.TrimPrefix(name, root)
+		if rel == "" {
+			return nil
+		}
+		target := filepath.Join(dest, filepath.FromSlash(rel))
+		// Refuse entries that would escape the destination (../ in names)
+		if !strings.HasPrefix(target+string(os.PathSeparator), destClean) {
+			return fmt.Errorf("archive entry %q escapes the destination", name)
+		}
+
+		if mode.IsDir() || r == nil {
+			return os.MkdirAll(target, 0750)
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+			return err
+		}
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0640)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", target, err)
+		}
+		if _, err := io.Copy(out, r); err != nil {
+			out.Close()
+			return fmt.Errorf("failed to extract %s: %w", name, err)
+		}
+		return out.Close()
+	})
+}
+
+// RestoreUniverseFromArchive replaces a server's universe with the contents of an archive
+// The current universe is moved aside to universe.pre-restore-<timestamp>, and put back if extraction fails
+// Returns the path the previous universe was moved to ("" if there was none)
+func RestoreUniverseFromArchive(server int, archivePath, root string) (string, error) {
+	universe := filepath.Join(GetServerDir(server), "universe")
+	aside := ""
+	if _, err := os.Stat(universe); err == nil {
+		aside = fmt.Sprintf("%s.pre-restore-%s", universe, time.Now().Format("20060102-150405"))
+		if err := os.Rename(universe, aside); err != nil {
+			return "", fmt.Errorf("failed to move current universe aside: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(universe, 0750); err != nil {
+		return aside, fmt.Errorf("failed to create universe directory: %w", err)
+	}
+	if err := extractArchive(archivePath, root, universe); err != nil {
+		// Put the previous universe back so the server is left as it was
+		os.RemoveAll(universe)
+		if aside != "" {
+			_ = os.Rename(aside, universe)
+		}
+		return "", fmt.Errorf("failed to extract backup: %w", err)
+	}
+
+	manifest := LoadManifestOrDefault()
+	if os.Geteuid() == 0 && SystemUserExists(manifest.HytaleUser) {
+		if err := ApplyServerOwnership(manifest.HytaleUser, server); err != nil {
+			return aside, err
+		}
+	}
+	return aside, nil
+}
+
+// RestoreGameBackup stops the server (if running), moves the current universe aside,
+// extracts the backup and starts the server again if it was running
+func (tm *TmuxManager) RestoreGameBackup(server int, name string) (string, error) {
+	backup, err := FindGameBackup(server, name)
+	if err != nil {
+		return "", err
+	}
+	inspection, err := InspectGameBackup(backup.Path)
+	if err != nil {
+		return "", err
+	}
+
+	wasRunning := tm.HasSession(server)
+	if wasRunning {
+		if _, err := tm.Stop(server); err != nil {
+			return "", fmt.Errorf("failed to stop server %d: %w", server, err)
+		}
+	}
+
+	aside, err := RestoreUniverseFromArchive(server, backup.Path, inspection.Root)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Restored %s to server %d\n", backup.Name, server))
+	if len(inspection.Worlds) > 0 {
+		b.WriteString(fmt.Sprintf("Worlds: %s\n", strings.Join(inspection.Worlds, ", ")))
+	}
+	if aside != "" {
+		b.WriteString(fmt.Sprintf("Previous universe kept at %s\n", aside))
+	}
+
+	if wasRunning {
+		if err := tm.StartServer(server, LoadLaunchSettings(LoadManifestOrDefault())); err != nil {
+			return b.String(), fmt.Errorf("backup restored but server %d failed to start: %w", server, err)
+		}
+		b.WriteString(fmt.Sprintf("Server %d restarted\n", server))
+	}
+	return b.String(), nil
+}
+
+// DeleteGameBackup deletes one of a server's game backups
+func DeleteGameBackup(server int, name string) error {
+	backup, err := FindGameBackup(server, name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(backup.Path); err != nil {
+		return fmt.Errorf("failed to delete backup: %w", err)
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 )
 
@@ -40,6 +41,15 @@ func BootstrapWithContextAndProgress(ctx context.Context, cfg BootstrapConfig, p
 		return "", fmt.Errorf("failed to create shared mods directory: %w", err)
 	}
 
+	// Save the installation manifest so runtime commands use the wizard's settings
+	// (ports, hostname prefix, JVM args, system user) instead of hardcoded defaults
+	if progressCallback != nil {
+		progressCallback(0.03, "Saving installation manifest...")
+	}
+	if err := SaveManifest(NewManifestFromBootstrap(cfg)); err != nil {
+		return "", fmt.Errorf("failed to save installation manifest: %w", err)
+	}
+
 	// 3. Validate all required dependencies
 	if progressCallback != nil {
 		progressCallback(0.05, "Validating system dependencies...")
@@ -48,6 +58,25 @@ func BootstrapWithContextAndProgress(ctx context.Context, cfg BootstrapConfig, p
 		return "", fmt.Errorf("dependency check failed: %w", err)
 	}
 
+	// The systemd backend needs systemctl to manage server units
+	if cfg.ProcessBackend == BackendSystemd {
+		if _, err := exec.LookPath("systemctl"); err != nil {
+			return "", fmt.Errorf("systemd process backend selected but systemctl was not found")
+		}
+	}
+
+	// Create the unprivileged system user servers run as
+	hytaleUser := cfg.HytaleUser
+	if hytaleUser == "" {
+		hytaleUser = DefaultHytaleUser
+	}
+	if progressCallback != nil {
+		progressCallback(0.07, fmt.Sprintf("Creating system user %s...", hytaleUser))
+	}
+	if err := EnsureSystemUser(hytaleUser); err != nil {
+		return "", err
+	}
+
 	// 4. Install Performance Saver plugin (default mod)
 	select {
 	case <-ctx.Done():
@@ -71,6 +100,7 @@ func BootstrapWithContextAndProgress(ctx context.Context, cfg BootstrapConfig, p
 	if progressCallback != nil {
 		progressCallback(0.25, "Ensuring hytale-downloader is installed...")
 	}
+	var gameVersion string
 	downloaderBinaryPath, err := EnsureHytaleDownloaderInstalled(ctx, progressCallback)
 	if err != nil {
 		// Failed to install hytale-downloader - check if files already exist
@@ -79,10 +109,13 @@ func BootstrapWithContextAndProgress(ctx context.Context, cfg BootstrapConfig, p
 			// Files don't exist and hytale-downloader not available
 			return "", fmt.Errorf("failed to install hytale-downloader and server files missing. %v. Please install hytale-downloader manually or copy server files to %s", err, masterDir)
 		}
-		// Files exist, continue with existing files
+		// Files exist, continue with existing files (kept as a game version like a download)
 		if progressCallback != nil {
 			progressCallback(0.3, "Using existing server files (hytale-downloader installation failed)")
 		}
+		if gameVersion, err = EnsureVersionedInstall(); err != nil {
+			return "", err
+		}
 	} else {
 		// hytale-downloader is available - download server files
 		if progressCallback != nil {
@@ -95,7 +128,13 @@ func BootstrapWithContextAndProgress(ctx context.Context, cfg BootstrapConfig, p
 			return "", fmt.Errorf("failed to create hytale-downloader instance: %w", err)
 		}
 
-		if err := downloader.Download(ctx, masterDir, progressCallback); err != nil {
+		// Each download is kept in versions/<game-version> with master-install pointing to it
+		// Best effort: without the reported version it is read from the JAR
+		latest, _ := downloader.PrintVersion(ctx)
+		gameVersion, err = InstallGameVersion(ctx, PatchlineProduction, latest, func(dir string) error {
+			return downloader.Download(ctx, dir, progressCallback)
+		})
+		if err != nil {
 			return "", fmt.Errorf("failed to download server files: %w", err)
 		}
 		if progressCallback != nil {
@@ -103,6 +142,11 @@ func BootstrapWithContextAndProgress(ctx context.Context, cfg BootstrapConfig, p
 		}
 	}
 
+	// Fleet-wide config values from the wizard; each server's config.json is rendered from them
+	if err := WriteInitialConfigDefaults(cfg.MaxPlayers, cfg.MaxViewRadius, cfg.GameMode, cfg.ServerPassword); err != nil {
+		return "", fmt.Errorf("failed to write config defaults: %w", err)
+	}
+
 	// 6. Create server instances
 	for i := 1; i <= cfg.NumServers; i++ {
 		select {
@@ -158,19 +202,37 @@ func BootstrapWithContextAndProgress(ctx context.Context, cfg BootstrapConfig, p
 		if err := UpdateServerConfig(i, port, hostname, cfg.MaxPlayers, cfg.MaxViewRadius, cfg.GameMode, cfg.ServerPassword); err != nil {
 			return "", fmt.Errorf("failed to create config for server %d: %w", i, err)
 		}
+		if _, err := RenderServerConfig(i); err != nil {
+			return "", fmt.Errorf("failed to apply config defaults to server %d: %w", i, err)
+		}
+	}
+
+	servers := make([]int, cfg.NumServers)
+	for i := range servers {
+		servers[i] = i + 1
+	}
+	if err := RecordServerGameVersions(servers, gameVersion); err != nil {
+		return "", fmt.Errorf("failed to record game versions: %w", err)
 	}
 
 	// 8. Save backup configuration to shared config
 	if progressCallback != nil {
 		progressCallback(0.95, "Saving backup configuration...")
 	}
-	backupConfig := &BackupConfig{
-		Enabled:  cfg.BackupEnabled,
-		Frequency: cfg.BackupFrequency,
-	}
+	backupConfig := DefaultBackupConfig()
+	backupConfig.Enabled = cfg.BackupEnabled
+	backupConfig.Frequency = cfg.BackupFrequency
 	if err := WriteBackupConfig(backupConfig); err != nil {
 		return "", fmt.Errorf("failed to save backup config: %w", err)
 	}
 
+	// 9. Hand master-install, shared and server directories to the system user
+	if progressCallback != nil {
+		progressCallback(0.97, fmt.Sprintf("Setting ownership and permissions for %s...", hytaleUser))
+	}
+	if err := ApplyInstallOwnership(hytaleUser); err != nil {
+		return "", fmt.Errorf("failed to set ownership: %w", err)
+	}
+
 	return fmt.Sprintf("Bootstrap completed: %d server(s) created", cfg.NumServers), nil
 }
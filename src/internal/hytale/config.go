@@ -1,10 +1,12 @@
 package hytale
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 )
 
 // HytaleConfig represents the Hytale server configuration
@@ -45,6 +47,16 @@ type HytaleConfig struct {
 		Type string `json:"Type,omitempty"`
 		Path string `json:"Path,omitempty"`
 	} `json:"AuthCredentialStore,omitempty"`
+
+	// raw is the document the config was read from, so WriteConfig can keep keys HSM
+	// doesn't model, the key order and explicit zero values (see config_json.go)
+	raw jsonObject
+}
+
+// hasKey reports whether a top-level key was present in the file the config was read from
+func (c *HytaleConfig) hasKey(key string) bool {
+	_, ok := c.raw.get(key)
+	return ok
 }
 
 // GetSharedConfigDir returns the path to the shared config directory
@@ -73,16 +85,29 @@ func ReadConfig(configPath string) (*HytaleConfig, error) {
 	if err := json.Unmarshal(data, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
+	if config.raw, err = parseJSONObject(data); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
 
 	return &config, nil
 }
 
 // WriteConfig writes a config.json file
+// A config from ReadConfig is merged into the document it was read from, so keys HytaleConfig
+// doesn't model, the key order and explicit zero values are kept
 func WriteConfig(configPath string, config *HytaleConfig) error {
-	data, err := json.MarshalIndent(config, "", "  ")
+	doc, err := mergeStruct(config.raw, reflect.ValueOf(config).Elem())
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	data := buf.Bytes()
 
 	if err := os.WriteFile(configPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write config: %w", err)
@@ -155,16 +180,17 @@ func UpdateServerConfig(serverNum int, port int, hostname string, maxPlayers int
 		}
 		
 		// Ensure optimization settings are set (use defaults if not present)
-		if config.MaxViewRadius == 0 {
+		// An explicit 0 in the file is the admin's choice and is kept
+		if config.MaxViewRadius == 0 && !config.hasKey("MaxViewRadius") {
 			config.MaxViewRadius = 12
 		}
-		if config.MaxEntitiesPerChunk == 0 {
+		if !config.hasKey("MaxEntitiesPerChunk") {
 			config.MaxEntitiesPerChunk = 50
 		}
-		if config.MobSpawnLimit == 0 {
+		if !config.hasKey("MobSpawnLimit") {
 			config.MobSpawnLimit = 100
 		}
-		if config.ItemDespawnTime == 0 {
+		if !config.hasKey("ItemDespawnTime") {
 			config.ItemDespawnTime = 300
 		}
 	}
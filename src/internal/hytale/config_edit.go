@@ -0,0 +1,264 @@
+package hytale
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config field kinds
+const (
+	ConfigFieldText   = "text"
+	ConfigFieldSecret = "secret" // Text that is masked while editing
+	ConfigFieldInt    = "int"
+	ConfigFieldChoice = "choice"
+)
+
+// GameModes are the game modes a server can default to
+var GameModes = []string{"Adventure", "Survival", "Creative"}
+
+// ConfigField is a config.json setting that can be edited as a form field
+type ConfigField struct {
+	Key      string // JSON path, e.g. "Defaults.GameMode"
+	Label    string
+	Help     string
+	Kind     string
+	Min, Max int      // Bounds for int fields; for text fields Min 1 means required and Max is the maximum length
+	Choices  []string // Values for choice fields
+
+	get func(c *HytaleConfig) string
+}
+
+// Get returns the field's value in a config as text
+func (f ConfigField) Get(c *HytaleConfig) string {
+	return f.get(c)
+}
+
+// JSON returns a validated value as it is written to a config layer
+func (f ConfigField) JSON(value string) json.RawMessage {
+	if f.Kind == ConfigFieldInt {
+		n, _ := strconv.Atoi(strings.TrimSpace(value))
+		return json.RawMessage(strconv.Itoa(n))
+	}
+	data, _ := json.Marshal(value)
+	return data
+}
+
+// Validate checks a value for the field
+func (f ConfigField) Validate(value string) error {
+	switch f.Kind {
+	case ConfigFieldInt:
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("%s must be a whole number", f.Label)
+		}
+		if n < f.Min || n > f.Max {
+			return fmt.Errorf("%s must be between %d and %d", f.Label, f.Min, f.Max)
+		}
+	case ConfigFieldChoice:
+		for _, choice := range f.Choices {
+			if value == choice {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s must be one of %s", f.Label, strings.Join(f.Choices, ", "))
+	default:
+		if f.Max > 0 && len(value) > f.Max {
+			return fmt.Errorf("%s must be at most %d characters", f.Label, f.Max)
+		}
+		if f.Min > 0 && strings.TrimSpace(value) == "" {
+			return fmt.Errorf("%s can't be empty", f.Label)
+		}
+	}
+	return nil
+}
+
+// intField builds an int field bound to a config struct member
+func intField(key, label, help string, min, max int, ptr func(c *HytaleConfig) *int) ConfigField {
+	return ConfigField{
+		Key: key, Label: label, Help: help, Kind: ConfigFieldInt, Min: min, Max: max,
+		get: func(c *HytaleConfig) string { return strconv.Itoa(*ptr(c)) },
+	}
+}
+
+// textField builds a text field bound to a config struct member
+func textField(key, label, help, kind string, required bool, maxLen int, ptr func(c *HytaleConfig) *string) ConfigField {
+	min := 0
+	if required {
+		min = 1
+	}
+	return ConfigField{
+		Key: key, Label: label, Help: help, Kind: kind, Min: min, Max: maxLen,
+		get: func(c *HytaleConfig) string { return *ptr(c) },
+	}
+}
+
+// ConfigFields lists the config.json settings the config editor offers, in form order
+var ConfigFields = []ConfigField{
+	textField("ServerName", "Server name", "Shown in the server list", ConfigFieldText, true, 64, func(c *HytaleConfig) *string { return &c.ServerName }),
+	textField("MOTD", "MOTD", "Message of the day", ConfigFieldText, false, 256, func(c *HytaleConfig) *string { return &c.MOTD }),
+	textField("Password", "Password", "Join password (empty = public)", ConfigFieldSecret, false, 64, func(c *HytaleConfig) *string { return &c.Password }),
+	intField("MaxPlayers", "Max players", "Players allowed at once", 1, 1000, func(c *HytaleConfig) *int { return &c.MaxPlayers }),
+	intField("MaxViewRadius", "Max view radius", "View distance in chunks (12 recommended)", 2, 64, func(c *HytaleConfig) *int { return &c.MaxViewRadius }),
+	intField("MaxEntitiesPerChunk", "Max entities per chunk", "50 recommended", 1, 1000, func(c *HytaleConfig) *int { return &c.MaxEntitiesPerChunk }),
+	intField("MobSpawnLimit", "Mob spawn limit", "100 recommended", 0, 10000, func(c *HytaleConfig) *int { return &c.MobSpawnLimit }),
+	intField("ItemDespawnTime", "Item despawn time", "Seconds before dropped items despawn (300 recommended)", 0, 86400, func(c *HytaleConfig) *int { return &c.ItemDespawnTime }),
+	{
+		Key: "Defaults.GameMode", Label: "Game mode", Help: "Default game mode for players", Kind: ConfigFieldChoice, Choices: GameModes,
+		get: func(c *HytaleConfig) string { return c.Defaults.GameMode },
+	},
+	textField("Defaults.World", "Default world", "World players join", ConfigFieldText, true, 64, func(c *HytaleConfig) *string { return &c.Defaults.World }),
+}
+
+// FindConfigField returns the editable field with the given key
+func FindConfigField(key string) (ConfigField, bool) {
+	for _, f := range ConfigFields {
+		if f.Key == key {
+			return f, true
+		}
+	}
+	return ConfigField{}, false
+}
+
+// ConfigChange is a field whose value an edit changes on one server
+type ConfigChange struct {
+	Key string
+	Old string
+	New string
+}
+
+// ServerConfigDiff lists what an edit changes in one server's config.json
+type ServerConfigDiff struct {
+	Server  int
+	Changes []ConfigChange
+	Backup  string // Copy of the previous config.json, once applied
+	Err     error  // Config could not be read or written
+}
+
+// ValidateConfigEdits checks every edited value (edits maps field keys to new values)
+func ValidateConfigEdits(edits map[string]string) error {
+	for key, value := range edits {
+		f, ok := FindConfigField(key)
+		if !ok {
+			return fmt.Errorf("unknown config field %q", key)
+		}
+		if err := f.Validate(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DiffConfigEdits previews what applying edits would change on each server
+func DiffConfigEdits(servers []int, edits map[string]string) []ServerConfigDiff {
+	diffs := make([]ServerConfigDiff, len(servers))
+	for i, server := range servers {
+		diffs[i].Server = server
+		config, err := ReadConfig(GetServerConfigPath(server))
+		if err != nil {
+			diffs[i].Err = err
+			continue
+		}
+		for _, f := range ConfigFields {
+			value, ok := edits[f.Key]
+			if !ok {
+				continue
+			}
+			if old := f.Get(config); old != value {
+				diffs[i].Changes = append(diffs[i].Changes, ConfigChange{Key: f.Key, Old: old, New: value})
+			}
+		}
+	}
+	return diffs
+}
+
+// GetConfigBackupDir returns the directory previous versions of a server's config.json are kept in
+func GetConfigBackupDir(serverNum int) string {
+	return filepath.Join(GetServerDir(serverNum), "config-backups")
+}
+
+// BackupServerConfig copies a server's config.json to config-backups/config-<timestamp>.json
+// Returns the backup path
+func BackupServerConfig(serverNum int) (string, error) {
+	data, err := os.ReadFile(GetServerConfigPath(serverNum))
+	if err != nil {
+		return "", fmt.Errorf("failed to read config: %w", err)
+	}
+	dir := GetConfigBackupDir(serverNum)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return "", fmt.Errorf("failed to create config backup directory: %w", err)
+	}
+	stamp := time.Now().Format("20060102-150405")
+	path := filepath.Join(dir, fmt.Sprintf("config-%s.json", stamp))
+	for i := 2; ; i++ {
+		// Several changes within a second must not overwrite each other's backup
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			break
+		}
+		path = filepath.Join(dir, fmt.Sprintf("config-%s-%d.json", stamp, i))
+	}
+	if err := os.WriteFile(path, data, 0640); err != nil {
+		return "", fmt.Errorf("failed to back up config: %w", err)
+	}
+	return path, nil
+}
+
+// ApplyConfigEdits writes edits to the config layers and re-renders the servers' config.json
+// An edit for every installed server goes to shared/config.defaults.json (and replaces any
+// override of the same key); an edit for some servers goes to their config.overrides.json.
+// Running servers pick the changes up on their next restart.
+func ApplyConfigEdits(servers []int, edits map[string]string) ([]ServerConfigDiff, error) {
+	if err := ValidateConfigEdits(edits); err != nil {
+		return nil, err
+	}
+	diffs := DiffConfigEdits(servers, edits)
+
+	keys := make([]string, 0, len(edits))
+	for key := range edits {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if len(servers) == DetectNumServers() {
+		for _, key := range keys {
+			f, _ := FindConfigField(key)
+			if err := setLayerKey(0, key, f.JSON(edits[key])); err != nil {
+				return diffs, err
+			}
+			for _, server := range servers {
+				if _, err := unsetLayerKey(server, key); err != nil {
+					return diffs, err
+				}
+			}
+		}
+	} else {
+		for i := range diffs {
+			for _, key := range keys {
+				f, _ := FindConfigField(key)
+				if err := setLayerKey(diffs[i].Server, key, f.JSON(edits[key])); err != nil {
+					diffs[i].Err = err
+					break
+				}
+			}
+		}
+	}
+
+	failed := 0
+	for i := range diffs {
+		diff := &diffs[i]
+		if diff.Err == nil {
+			diff.Backup, diff.Err = RenderServerConfig(diff.Server)
+		}
+		if diff.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return diffs, fmt.Errorf("%d of %d server config(s) could not be updated", failed, len(diffs))
+	}
+	return diffs, nil
+}
@@ -0,0 +1,239 @@
+package hytale
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// config.json is owned by the game: new versions add keys HytaleConfig doesn't model, and the
+// game writes zero values explicitly. To avoid losing either on read-modify-write, ReadConfig keeps
+// the document it parsed and WriteConfig merges the struct back into it instead of replacing it.
+
+// jsonMember is one key of a JSON object with its value as it appeared in the file
+type jsonMember struct {
+	Key   string
+	Value json.RawMessage
+}
+
+// jsonObject is a JSON object that keeps its keys in file order
+type jsonObject []jsonMember
+
+// parseJSONObject parses a JSON object, keeping key order and the raw value of every key
+func parseJSONObject(data []byte) (jsonObject, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("expected a JSON object")
+	}
+
+	obj := jsonObject{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected an object key")
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		// A repeated key replaces the earlier value, as it does for encoding/json
+		obj = obj.set(key, value)
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// get returns the raw value of a key
+func (o jsonObject) get(key string) (json.RawMessage, bool) {
+	for _, m := range o {
+		if m.Key == key {
+			return m.Value, true
+		}
+	}
+	return nil, false
+}
+
+// set replaces the value of a key in place, or appends the key if it isn't there yet
+func (o jsonObject) set(key string, value json.RawMessage) jsonObject {
+	for i := range o {
+		if o[i].Key == key {
+			o[i].Value = value
+			return o
+		}
+	}
+	return append(o, jsonMember{Key: key, Value: value})
+}
+
+// MarshalJSON writes the object with its keys in order
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := marshalJSON(m.Key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(m.Value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// mergeStruct writes the exported fields of a struct over the object it was read from
+// Keys the struct doesn't model keep their value and position, modelled keys that were in the
+// file keep their position and are written even when zero, and modelled keys that weren't
+// are appended unless they are tagged omitempty and empty.
+func mergeStruct(raw jsonObject, v reflect.Value) (jsonObject, error) {
+	out := append(jsonObject{}, raw...)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name, opts := field.Name, ""
+		if tag := field.Tag.Get("json"); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			if comma := strings.Index(tag, ","); comma >= 0 {
+				opts = tag[comma:]
+				tag = tag[:comma]
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+
+		old, present := out.get(name)
+		if !present && strings.Contains(opts, ",omitempty") && isEmptyJSONValue(v.Field(i)) {
+			continue
+		}
+		value, err := mergeValue(old, v.Field(i))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		out = out.set(name, value)
+	}
+	return out, nil
+}
+
+// mergeValue returns the JSON for a field, reusing the old value where it is unchanged
+func mergeValue(old json.RawMessage, v reflect.Value) (json.RawMessage, error) {
+	if v.Kind() == reflect.Struct {
+		raw, err := parseJSONObject(old)
+		if err != nil {
+			raw = nil
+		}
+		obj, err := mergeStruct(raw, v)
+		if err != nil {
+			return nil, err
+		}
+		return marshalJSON(obj)
+	}
+	return mergeAny(old, v.Interface())
+}
+
+// mergeAny returns the JSON for a value read through a map or interface{}
+// Objects keep the order of the old object, with new keys appended in sorted order.
+func mergeAny(old json.RawMessage, value interface{}) (json.RawMessage, error) {
+	if m, ok := value.(map[string]interface{}); ok && m != nil {
+		if raw, err := parseJSONObject(old); err == nil {
+			out := jsonObject{}
+			for _, member := range raw {
+				v, ok := m[member.Key]
+				if !ok {
+					continue // Removed from the map
+				}
+				merged, err := mergeAny(member.Value, v)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, jsonMember{Key: member.Key, Value: merged})
+			}
+			var added []string
+			for key := range m {
+				if _, ok := raw.get(key); !ok {
+					added = append(added, key)
+				}
+			}
+			sort.Strings(added)
+			for _, key := range added {
+				data, err := marshalJSON(m[key])
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, jsonMember{Key: key, Value: data})
+			}
+			return marshalJSON(out)
+		}
+	}
+
+	data, err := marshalJSON(value)
+	if err != nil {
+		return nil, err
+	}
+	if old != nil && jsonEqual(old, data) {
+		// Keep the file's spelling of the value, e.g. 1.0 or an escaped string
+		return old, nil
+	}
+	return data, nil
+}
+
+// marshalJSON is json.Marshal without HTML escaping: the game writes & < > as they are, and
+// json.Marshal would turn them into \u0026 and the like, in new values and kept ones alike
+func marshalJSON(v interface{}) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// jsonEqual reports whether two JSON values decode to the same value
+func jsonEqual(a, b json.RawMessage) bool {
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// isEmptyJSONValue reports whether encoding/json would drop a value tagged omitempty
+func isEmptyJSONValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
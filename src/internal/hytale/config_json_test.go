@@ -0,0 +1,176 @@
+package hytale
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// roundTrip reads a fixture with ReadConfig, lets edit change it and returns what WriteConfig wrote
+func roundTrip(t *testing.T, fixture string, edit func(*HytaleConfig)) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", fixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := ReadConfig(path)
+	if err != nil {
+		t.Fatalf("ReadConfig: %v", err)
+	}
+	if edit != nil {
+		edit(config)
+	}
+	if err := WriteConfig(path, config); err != nil {
+		t.Fatalf("WriteConfig: %v", err)
+	}
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return written
+}
+
+// compactFixture returns a fixture without whitespace, with replacements applied in order
+func compactFixture(t *testing.T, fixture string, replacements ...string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", fixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := compact(t, data)
+	for i := 0; i+1 < len(replacements); i += 2 {
+		if !strings.Contains(out, replacements[i]) {
+			t.Fatalf("fixture %s has no %s", fixture, replacements[i])
+		}
+		out = strings.Replace(out, replacements[i], replacements[i+1], 1)
+	}
+	return out
+}
+
+// compact strips the whitespace between tokens; key order and number spelling are kept
+func compact(t *testing.T, data []byte) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, data)
+	}
+	return buf.String()
+}
+
+func TestConfigRoundTripUnchanged(t *testing.T) {
+	for _, fixture := range []string{"config-default.json", "config-customized.json"} {
+		t.Run(fixture, func(t *testing.T) {
+			got := compact(t, roundTrip(t, fixture, nil))
+			if want := compactFixture(t, fixture); got != want {
+				t.Errorf("config changed by a round trip\ngot:  %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
+
+func TestConfigRoundTripEdit(t *testing.T) {
+	got := compact(t, roundTrip(t, "config-customized.json", func(c *HytaleConfig) {
+		c.MaxPlayers = 40
+		c.Defaults.GameMode = "Creative"
+		c.RateLimit["PacketsPerSecond"] = 2500
+	}))
+	want := compactFixture(t, "config-customized.json",
+		`"MaxPlayers":20`, `"MaxPlayers":40`,
+		`"GameMode":"Survival"`, `"GameMode":"Creative"`,
+		`"PacketsPerSecond":2000`, `"PacketsPerSecond":2500`,
+	)
+	if got != want {
+		t.Errorf("unexpected config after edit\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestConfigRoundTripKeepsUnknownKeys(t *testing.T) {
+	got := compact(t, roundTrip(t, "config-customized.json", func(c *HytaleConfig) {
+		c.ServerName = "Renamed"
+	}))
+	for _, member := range []string{
+		`"LocalCompressionEnabled":true`,     // Top-level
+		`"Difficulty":"Normal"`,              // In a modelled struct
+		`"InitialTimeout":"PT10S"`,           // In a modelled struct, before a modelled key
+		`"PathPlugin":{"Enabled":false,`,     // In a map
+		`"com.hypixel.hytale.server":"INFO"`, // Key with dots
+	} {
+		if !strings.Contains(got, member) {
+			t.Errorf("%s lost\ngot: %s", member, got)
+		}
+	}
+}
+
+func TestConfigRoundTripKeepsZeroValues(t *testing.T) {
+	got := compact(t, roundTrip(t, "config-customized.json", nil))
+	for _, member := range []string{
+		`"MaxViewRadius":0`,
+		`"MaxEntitiesPerChunk":0`,
+		`"Password":""`,
+		`"DisplayTmpTagsInStrings":false`,
+		`"Mods":{}`,
+	} {
+		if !strings.Contains(got, member) {
+			t.Errorf("explicit %s dropped\ngot: %s", member, got)
+		}
+	}
+}
+
+func TestConfigRoundTripKeepsNumberSpelling(t *testing.T) {
+	got := compact(t, roundTrip(t, "config-customized.json", func(c *HytaleConfig) {
+		c.MaxPlayers = 40
+	}))
+	for _, member := range []string{`"Multiplier":1.0`, `"Scale":1.50`} {
+		if !strings.Contains(got, member) {
+			t.Errorf("%s respelled\ngot: %s", member, got)
+		}
+	}
+}
+
+func TestConfigRoundTripNewConfig(t *testing.T) {
+	// A config that wasn't read from a file is written like encoding/json would
+	config := CreateDefaultConfig(5520, "Test", 20, 12, "", "", "")
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := WriteConfig(path, config); err != nil {
+		t.Fatalf("WriteConfig: %v", err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := json.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if compact(t, got) != string(want) {
+		t.Errorf("new config\ngot:  %s\nwant: %s", compact(t, got), want)
+	}
+}
+
+func TestConfigHasKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"MaxViewRadius": 0, "MobSpawnLimit": 100}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := ReadConfig(path)
+	if err != nil {
+		t.Fatalf("ReadConfig: %v", err)
+	}
+	for key, want := range map[string]bool{
+		"MaxViewRadius":       true,
+		"MobSpawnLimit":       true,
+		"MaxEntitiesPerChunk": false,
+	} {
+		if got := config.hasKey(key); got != want {
+			t.Errorf("hasKey(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
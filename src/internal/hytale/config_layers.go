@@ -0,0 +1,438 @@
+package hytale
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Each server's config.json is rendered from three layers, lowest first: config.json itself
+// (what the game and HSM wrote, such as ServerName and keys HSM doesn't manage), then
+// shared/config.defaults.json with the fleet-wide values, then server-N/config.overrides.json.
+// Layers are partial config.json documents; objects are merged key by key.
+const (
+	ConfigDefaultsFile  = "config.defaults.json"
+	ConfigOverridesFile = "config.overrides.json"
+)
+
+// Sources of an effective config value, as reported by ExplainServerConfig
+const (
+	ConfigSourceOverride = "override"
+	ConfigSourceDefaults = "defaults"
+	ConfigSourceFile     = "config.json" // Not set by a layer
+)
+
+// GetConfigDefaultsPath returns the path to the fleet-wide config defaults
+func GetConfigDefaultsPath() string {
+	return filepath.Join(GetSharedConfigDir(), ConfigDefaultsFile)
+}
+
+// GetConfigOverridesPath returns the path to a server's config overrides
+func GetConfigOverridesPath(serverNum int) string {
+	return filepath.Join(GetServerDir(serverNum), ConfigOverridesFile)
+}
+
+// configLayerPath returns the defaults path for server 0 and the overrides path otherwise
+func configLayerPath(serverNum int) string {
+	if serverNum == 0 {
+		return GetConfigDefaultsPath()
+	}
+	return GetConfigOverridesPath(serverNum)
+}
+
+// readConfigLayer reads a layer file; a missing file is an empty layer
+func readConfigLayer(path string) (jsonObject, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return jsonObject{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return jsonObject{}, nil
+	}
+	layer, err := parseJSONObject(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return layer, nil
+}
+
+// writeConfigLayer writes a layer file and hands it to the system user
+func writeConfigLayer(path string, layer jsonObject) error {
+	data, err := json.MarshalIndent(layer, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	chownConfigPath(path)
+	return nil
+}
+
+// applyConfigLayer merges a layer over a document; objects present in both are merged key by key
+func applyConfigLayer(base, layer jsonObject) jsonObject {
+	out := append(jsonObject{}, base...)
+	for _, m := range layer {
+		if old, ok := out.get(m.Key); ok {
+			oldObj, oldErr := parseJSONObject(old)
+			newObj, newErr := parseJSONObject(m.Value)
+			if oldErr == nil && newErr == nil {
+				merged, _ := json.Marshal(applyConfigLayer(oldObj, newObj))
+				out = out.set(m.Key, merged)
+				continue
+			}
+		}
+		out = out.set(m.Key, m.Value)
+	}
+	return out
+}
+
+// lookupConfigKey returns the value at a dotted key such as "Defaults.GameMode"
+func lookupConfigKey(obj jsonObject, key string) (json.RawMessage, bool) {
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		value, ok := obj.get(part)
+		if !ok {
+			return nil, false
+		}
+		if i == len(parts)-1 {
+			return value, true
+		}
+		var err error
+		if obj, err = parseJSONObject(value); err != nil {
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// setConfigKey sets the value at a dotted key, creating intermediate objects as needed
+func setConfigKey(obj jsonObject, key string, value json.RawMessage) (jsonObject, error) {
+	head, rest, nested := strings.Cut(key, ".")
+	if head == "" {
+		return nil, fmt.Errorf("invalid config key %q", key)
+	}
+	if !nested {
+		return append(jsonObject{}, obj...).set(head, value), nil
+	}
+	child := jsonObject{}
+	if old, ok := obj.get(head); ok {
+		var err error
+		if child, err = parseJSONObject(old); err != nil {
+			return nil, fmt.Errorf("%s is not an object", head)
+		}
+	}
+	child, err := setConfigKey(child, rest, value)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(child)
+	if err != nil {
+		return nil, err
+	}
+	return append(jsonObject{}, obj...).set(head, data), nil
+}
+
+// deleteConfigKey removes the value at a dotted key, and any object the removal leaves empty
+func deleteConfigKey(obj jsonObject, key string) (jsonObject, bool) {
+	head, rest, nested := strings.Cut(key, ".")
+	out := jsonObject{}
+	found := false
+	for _, m := range obj {
+		if m.Key != head {
+			out = append(out, m)
+			continue
+		}
+		if !nested {
+			found = true
+			continue
+		}
+		child, err := parseJSONObject(m.Value)
+		if err != nil {
+			out = append(out, m)
+			continue
+		}
+		child, found = deleteConfigKey(child, rest)
+		if len(child) > 0 {
+			data, _ := json.Marshal(child)
+			out = append(out, jsonMember{Key: m.Key, Value: data})
+		}
+	}
+	return out, found
+}
+
+// ParseConfigValue turns a command-line value into JSON: valid JSON is used as is
+// (numbers, true/false, quoted strings, objects), anything else becomes a string
+func ParseConfigValue(value string) json.RawMessage {
+	trimmed := strings.TrimSpace(value)
+	if trimmed != "" && json.Valid([]byte(trimmed)) {
+		return json.RawMessage(trimmed)
+	}
+	data, _ := json.Marshal(value)
+	return data
+}
+
+// renderServerConfig returns a server's config.json as it is on disk and as the layers render it
+func renderServerConfig(serverNum int) (current, rendered []byte, err error) {
+	current, err = os.ReadFile(GetServerConfigPath(serverNum))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	doc, err := parseJSONObject(current)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	defaults, err := readConfigLayer(GetConfigDefaultsPath())
+	if err != nil {
+		return nil, nil, err
+	}
+	overrides, err := readConfigLayer(GetConfigOverridesPath(serverNum))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	doc = applyConfigLayer(applyConfigLayer(doc, defaults), overrides)
+	rendered, err = json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	return current, rendered, nil
+}
+
+// RenderServerConfig writes a server's effective config.json from the defaults and its overrides
+// The previous file is backed up first; the backup path is empty when nothing changed.
+// Running servers pick the changes up on their next restart.
+func RenderServerConfig(serverNum int) (string, error) {
+	current, rendered, err := renderServerConfig(serverNum)
+	if err != nil {
+		return "", err
+	}
+	if jsonEqual(current, rendered) {
+		return "", nil
+	}
+	backup, err := BackupServerConfig(serverNum)
+	if err != nil {
+		return "", err
+	}
+	configPath := GetServerConfigPath(serverNum)
+	if err := os.WriteFile(configPath, rendered, 0644); err != nil {
+		return "", fmt.Errorf("failed to write config: %w", err)
+	}
+	chownConfigPath(configPath)
+	chownConfigPath(GetConfigBackupDir(serverNum))
+	return backup, nil
+}
+
+// RenderServerConfigs renders the config.json of several servers
+func RenderServerConfigs(servers []int) []ServerActionResult {
+	results := make([]ServerActionResult, len(servers))
+	for i, server := range servers {
+		results[i].Server = server
+		backup, err := RenderServerConfig(server)
+		switch {
+		case err != nil:
+			results[i].Err = err
+		case backup == "":
+			results[i].Detail = "up to date"
+		default:
+			results[i].Detail = "updated, previous config saved to " + backup
+		}
+	}
+	return results
+}
+
+// setLayerKey sets a key in the defaults (server 0) or a server's overrides without re-rendering
+func setLayerKey(serverNum int, key string, value json.RawMessage) error {
+	if !json.Valid(value) {
+		return fmt.Errorf("invalid value for %s", key)
+	}
+	path := configLayerPath(serverNum)
+	layer, err := readConfigLayer(path)
+	if err != nil {
+		return err
+	}
+	if layer, err = setConfigKey(layer, key, value); err != nil {
+		return err
+	}
+	return writeConfigLayer(path, layer)
+}
+
+// unsetLayerKey removes a key from the defaults (server 0) or a server's overrides without
+// re-rendering; it reports whether the key was set
+func unsetLayerKey(serverNum int, key string) (bool, error) {
+	path := configLayerPath(serverNum)
+	layer, err := readConfigLayer(path)
+	if err != nil {
+		return false, err
+	}
+	layer, found := deleteConfigKey(layer, key)
+	if !found {
+		return false, nil
+	}
+	return true, writeConfigLayer(path, layer)
+}
+
+// SetConfigValue sets a key in the defaults (server 0) or a server's overrides, then re-renders
+// the servers it applies to
+func SetConfigValue(serverNum int, key string, value json.RawMessage) ([]ServerActionResult, error) {
+	if err := setLayerKey(serverNum, key, value); err != nil {
+		return nil, err
+	}
+	return RenderServerConfigs(layerServers(serverNum)), nil
+}
+
+// UnsetConfigValue removes a key from the defaults (server 0) or a server's overrides, then
+// re-renders the servers it applied to
+// The key keeps its current value in config.json unless a lower layer still sets it.
+func UnsetConfigValue(serverNum int, key string) ([]ServerActionResult, error) {
+	found, err := unsetLayerKey(serverNum, key)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("%s is not set in %s", key, configLayerPath(serverNum))
+	}
+	return RenderServerConfigs(layerServers(serverNum)), nil
+}
+
+// layerServers returns the servers a layer applies to: all of them for the defaults
+func layerServers(serverNum int) []int {
+	if serverNum != 0 {
+		return []int{serverNum}
+	}
+	servers := make([]int, DetectNumServers())
+	for i := range servers {
+		servers[i] = i + 1
+	}
+	return servers
+}
+
+// ExplainedConfigValue is one effective config.json value and the layer it came from
+type ExplainedConfigValue struct {
+	Key      string          `json:"key"`
+	Value    json.RawMessage `json:"value"`
+	Source   string          `json:"source"`
+	Shadowed json.RawMessage `json:"shadowed_default,omitempty"` // Default hidden by an override
+}
+
+// ConfigExplanation lists where each of a server's effective config values comes from
+type ConfigExplanation struct {
+	Server int                    `json:"server"`
+	Values []ExplainedConfigValue `json:"values"`
+	Stale  bool                   `json:"stale"` // config.json on disk doesn't match the layers yet
+}
+
+// ExplainServerConfig renders a server's config in memory and reports the source of every value
+func ExplainServerConfig(serverNum int) (*ConfigExplanation, error) {
+	current, rendered, err := renderServerConfig(serverNum)
+	if err != nil {
+		return nil, err
+	}
+	defaults, err := readConfigLayer(GetConfigDefaultsPath())
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := readConfigLayer(GetConfigOverridesPath(serverNum))
+	if err != nil {
+		return nil, err
+	}
+	doc, err := parseJSONObject(rendered)
+	if err != nil {
+		return nil, err
+	}
+
+	explanation := &ConfigExplanation{Server: serverNum, Stale: !jsonEqual(current, rendered)}
+	for _, leaf := range flattenConfig(doc, "") {
+		value := ExplainedConfigValue{Key: leaf.Key, Value: leaf.Value, Source: ConfigSourceFile}
+		if _, ok := lookupConfigKey(overrides, leaf.Key); ok {
+			value.Source = ConfigSourceOverride
+			if def, ok := lookupConfigKey(defaults, leaf.Key); ok {
+				value.Shadowed = def
+			}
+		} else if _, ok := lookupConfigKey(defaults, leaf.Key); ok {
+			value.Source = ConfigSourceDefaults
+		}
+		explanation.Values = append(explanation.Values, value)
+	}
+	return explanation, nil
+}
+
+// flattenConfig lists the leaf values of a document under dotted keys, in document order
+// Empty objects and arrays are leaves
+func flattenConfig(obj jsonObject, prefix string) []jsonMember {
+	var leaves []jsonMember
+	for _, m := range obj {
+		key := prefix + m.Key
+		if child, err := parseJSONObject(m.Value); err == nil && len(child) > 0 {
+			leaves = append(leaves, flattenConfig(child, key+".")...)
+			continue
+		}
+		leaves = append(leaves, jsonMember{Key: key, Value: m.Value})
+	}
+	return leaves
+}
+
+// ConfigLayerKeys lists the dotted keys a layer sets, sorted (server 0 is the defaults)
+func ConfigLayerKeys(serverNum int) ([]string, error) {
+	layer, err := readConfigLayer(configLayerPath(serverNum))
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, leaf := range flattenConfig(layer, "") {
+		keys = append(keys, leaf.Key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// WriteInitialConfigDefaults creates shared/config.defaults.json from the setup wizard's values
+// An existing file is left alone, so reinstalling doesn't undo later changes
+func WriteInitialConfigDefaults(maxPlayers, maxViewRadius int, gameMode, serverPassword string) error {
+	path := GetConfigDefaultsPath()
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	layer := jsonObject{}
+	set := func(key string, value interface{}) {
+		data, _ := json.Marshal(value)
+		layer, _ = setConfigKey(layer, key, data)
+	}
+	if maxPlayers > 0 {
+		set("MaxPlayers", maxPlayers)
+	}
+	if maxViewRadius > 0 {
+		set("MaxViewRadius", maxViewRadius)
+	}
+	if gameMode != "" {
+		set("Defaults.GameMode", gameMode)
+	}
+	if serverPassword != "" {
+		set("Password", serverPassword)
+	}
+	return writeConfigLayer(path, layer)
+}
+
+// chownConfigPath hands a config file or directory to the system user when running as root
+func chownConfigPath(path string) {
+	if os.Geteuid() != 0 {
+		return
+	}
+	manifest := LoadManifestOrDefault()
+	if !SystemUserExists(manifest.HytaleUser) {
+		return
+	}
+	if uid, gid, err := lookupIDs(manifest.HytaleUser); err == nil {
+		_ = chownTree(path, uid, gid)
+	}
+}
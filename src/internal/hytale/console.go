@@ -0,0 +1,95 @@
+package hytale
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultCommandTimeout is how long SendCommand collects a command's output
+	DefaultCommandTimeout = 3 * time.Second
+
+	// commandOutputSettle ends output capture early once the console has been quiet this long
+	commandOutputSettle = 750 * time.Millisecond
+
+	// commandPollInterval is how often the console is read while capturing output
+	commandPollInterval = 250 * time.Millisecond
+
+	// commandCaptureLines is the console window diffed before and after a command
+	commandCaptureLines = 200
+)
+
+// consoleLinePrefix matches the timestamp HSM adds to console log lines and a console prompt
+var consoleLinePrefix = regexp.MustCompile(`^(\[\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}\] )?(> ?)?`)
+
+// SendCommand types a command into a running server's console and returns the console lines
+// printed after it (other output printed at the same time, e.g. chat, is included too)
+func (tm *TmuxManager) SendCommand(server int, command string) ([]string, error) {
+	return tm.SendCommandWithTimeout(server, command, DefaultCommandTimeout)
+}
+
+// SendCommandWithTimeout is SendCommand with a custom capture timeout
+// A timeout <= 0 sends the command without waiting for output
+func (tm *TmuxManager) SendCommandWithTimeout(server int, command string, timeout time.Duration) ([]string, error) {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return nil, fmt.Errorf("empty command")
+	}
+	if strings.ContainsAny(command, "\r\n") {
+		return nil, fmt.Errorf("command must be a single line")
+	}
+	if !tm.HasSession(server) {
+		return nil, fmt.Errorf("session %s does not exist: %w", tm.SessionName(server), ErrNotRunning)
+	}
+
+	if timeout <= 0 {
+		return nil, tm.backend.SendCommand(server, command)
+	}
+
+	// Marker: the console window just before the command; everything after it is the response
+	var previous []string
+	if output, err := tm.Logs(server, commandCaptureLines); err == nil {
+		previous = SplitLogLines(output)
+	}
+
+	if err := tm.backend.SendCommand(server, command); err != nil {
+		return nil, err
+	}
+
+	var captured []string
+	deadline := time.Now().Add(timeout)
+	lastOutput := time.Now()
+	for time.Now().Before(deadline) {
+		time.Sleep(commandPollInterval)
+
+		output, err := tm.Logs(server, commandCaptureLines)
+		if err != nil {
+			// Server went away (e.g. /stop) - return what was captured
+			break
+		}
+		current := SplitLogLines(output)
+		if newLines := NewLogLines(previous, current); len(newLines) > 0 {
+			captured = append(captured, newLines...)
+			lastOutput = time.Now()
+		} else if len(captured) > 0 && time.Since(lastOutput) >= commandOutputSettle {
+			break
+		}
+		previous = current
+	}
+
+	return stripCommandEcho(captured, command), nil
+}
+
+// stripCommandEcho drops the console's echo of the typed command from captured output
+func stripCommandEcho(lines []string, command string) []string {
+	var output []string
+	for _, line := range lines {
+		if strings.TrimSpace(consoleLinePrefix.ReplaceAllString(line, "")) == command {
+			continue
+		}
+		output = append(output, line)
+	}
+	return output
+}
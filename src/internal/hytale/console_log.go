@@ -0,0 +1,422 @@
+package hytale
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultConsoleLogMaxSizeMB is the size at which the current console log is rotated
+	DefaultConsoleLogMaxSizeMB = 50
+
+	// DefaultConsoleLogMaxAgeDays is how long rotated console logs are kept
+	DefaultConsoleLogMaxAgeDays = 30
+
+	// consoleLogPrefix starts every console log file name (console-<date>.log)
+	consoleLogPrefix = "console-"
+
+	// consoleLogDateFormat is the date in console log file names
+	consoleLogDateFormat = "2006-01-02"
+
+	// consoleLogTimeFormat prefixes every console log line
+	consoleLogTimeFormat = "2006-01-02 15:04:05"
+)
+
+// ansiEscape matches terminal escape sequences tmux passes through pipe-pane
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)|\x1b[()][0-9A-Za-z]|\x1b[=>]`)
+
+// ConsoleLogFile describes a console log or crash report in a server's logs directory
+type ConsoleLogFile struct {
+	Name       string
+	Path       string
+	Size       int64
+	ModTime    time.Time
+	Compressed bool
+}
+
+// ConsoleLogWriter writes timestamped console lines to server-N/logs/console-<date>.log,
+// rotating by date and size and compressing rotated files with gzip
+type ConsoleLogWriter struct {
+	dir     string
+	maxSize int64
+	maxAge  time.Duration
+
+	file *os.File
+	date string
+	size int64
+}
+
+// NewConsoleLogWriter creates a console log writer for a server using the manifest's rotation settings
+func NewConsoleLogWriter(server int) *ConsoleLogWriter {
+	manifest := LoadManifestOrDefault()
+	maxSize := manifest.ConsoleLogMaxSizeMB
+	if maxSize <= 0 {
+		maxSize = DefaultConsoleLogMaxSizeMB
+	}
+	maxAge := manifest.ConsoleLogMaxAgeDays
+	if maxAge <= 0 {
+		maxAge = DefaultConsoleLogMaxAgeDays
+	}
+	return &ConsoleLogWriter{
+		dir:     GetServerLogsDir(server),
+		maxSize: int64(maxSize) * 1024 * 1024,
+		maxAge:  time.Duration(maxAge) * 24 * time.Hour,
+	}
+}
+
+// currentPath returns the path of the console log for a date
+func (w *ConsoleLogWriter) currentPath(date string) string {
+	return filepath.Join(w.dir, consoleLogPrefix+date+".log")
+}
+
+// WriteLine cleans up a raw console line and appends it with a timestamp
+func (w *ConsoleLogWriter) WriteLine(raw string) error {
+	line := CleanConsoleLine(raw)
+	now := time.Now()
+	if err := w.open(now); err != nil {
+		return err
+	}
+
+	entry := fmt.Sprintf("[%s] %s\n", now.Format(consoleLogTimeFormat), line)
+	n, err := w.file.WriteString(entry)
+	w.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write console log: %w", err)
+	}
+
+	if w.size >= w.maxSize {
+		return w.rotate()
+	}
+	return nil
+}
+
+// open makes sure the console log for now's date is open, rotating the previous day's file
+func (w *ConsoleLogWriter) open(now time.Time) error {
+	date := now.Format(consoleLogDateFormat)
+	if w.file != nil && w.date == date {
+		return nil
+	}
+	if w.file != nil {
+		// New day: close yesterday's file; compressStale below compresses it
+		w.file.Close()
+		w.file = nil
+	}
+
+	if err := os.MkdirAll(w.dir, 0750); err != nil {
+		return fmt.Errorf("failed to create %s: %w", w.dir, err)
+	}
+
+	w.date = date
+	path := w.currentPath(date)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return fmt.Errorf("failed to open console log: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to stat console log: %w", err)
+	}
+	w.file = f
+	w.size = info.Size()
+
+	// Compress logs left uncompressed by earlier days or runs, and drop expired ones
+	w.compressStale()
+	w.prune(now)
+	return nil
+}
+
+// rotate moves the current file aside as console-<date>.N.log.gz and starts a new one
+func (w *ConsoleLogWriter) rotate() error {
+	if w.file == nil {
+		return nil
+	}
+	w.file.Close()
+	w.file = nil
+
+	current := w.currentPath(w.date)
+	var rotated string
+	for i := 1; ; i++ {
+		rotated = filepath.Join(w.dir, fmt.Sprintf("%s%s.%d.log", consoleLogPrefix, w.date, i))
+		if !fileExists(rotated) && !fileExists(rotated+".gz") {
+			break
+		}
+	}
+	if err := os.Rename(current, rotated); err != nil {
+		return fmt.Errorf("failed to rotate console log: %w", err)
+	}
+	if err := gzipFile(rotated); err != nil {
+		return err
+	}
+
+	// Reopen a fresh file for the same date
+	w.date = ""
+	return w.open(time.Now())
+}
+
+// compressStale gzips every uncompressed console log except the one being written
+func (w *ConsoleLogWriter) compressStale() {
+	matches, _ := filepath.Glob(filepath.Join(w.dir, consoleLogPrefix+"*.log"))
+	current := w.currentPath(w.date)
+	for _, path := range matches {
+		if path != current {
+			_ = gzipFile(path)
+		}
+	}
+}
+
+// prune removes compressed console logs older than the maximum age
+func (w *ConsoleLogWriter) prune(now time.Time) {
+	matches, _ := filepath.Glob(filepath.Join(w.dir, consoleLogPrefix+"*.log.gz"))
+	for _, path := range matches {
+		if info, err := os.Stat(path); err == nil && now.Sub(info.ModTime()) > w.maxAge {
+			os.Remove(path)
+		}
+	}
+}
+
+// Close closes the current console log
+func (w *ConsoleLogWriter) Close() error {
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// CleanConsoleLine strips terminal escape sequences and carriage-return overwrites from a console line
+func CleanConsoleLine(raw string) string {
+	line := strings.TrimRight(raw, "\r\n")
+	// A bare \r means the terminal overwrote the line - keep what was visible last
+	if idx := strings.LastIndex(line, "\r"); idx >= 0 {
+		line = line[idx+1:]
+	}
+	return ansiEscape.ReplaceAllString(line, "")
+}
+
+// CopyConsoleLines reads console output from r line by line into the console log
+// If tee is non-nil the raw output is also copied there (e.g. to the journal)
+// A failing console log must not stall the server writing to r, so r is always read to the end:
+// the first log error is passed to report (if non-nil) when it happens and returned after EOF
+func CopyConsoleLines(w *ConsoleLogWriter, r io.Reader, tee io.Writer, report func(error)) error {
+	reader := bufio.NewReader(r)
+	var logErr error
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			if tee != nil {
+				_, _ = io.WriteString(tee, line)
+			}
+			// Keep trying later lines: the log may recover (e.g. once disk space is freed)
+			if werr := w.WriteLine(line); werr != nil && logErr == nil {
+				logErr = werr
+				if report != nil {
+					report(werr)
+				}
+			}
+		}
+		if err == io.EOF {
+			return logErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// ListConsoleLogs returns a server's console logs and crash reports, newest first
+func ListConsoleLogs(server int) ([]ConsoleLogFile, error) {
+	entries, err := os.ReadDir(GetServerLogsDir(server))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read logs directory: %w", err)
+	}
+
+	var files []ConsoleLogFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !(strings.HasPrefix(name, consoleLogPrefix) || strings.HasPrefix(name, "crash-")) {
+			continue
+		}
+		if !strings.HasSuffix(name, ".log") && !strings.HasSuffix(name, ".log.gz") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, ConsoleLogFile{
+			Name:       name,
+			Path:       filepath.Join(GetServerLogsDir(server), name),
+			Size:       info.Size(),
+			ModTime:    info.ModTime(),
+			Compressed: strings.HasSuffix(name, ".gz"),
+		})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		if !files[i].ModTime.Equal(files[j].ModTime) {
+			return files[i].ModTime.After(files[j].ModTime)
+		}
+		// Same second: console-<date>.log sorts after console-<date>.N.log.gz by name, and is newer
+		return files[i].Name > files[j].Name
+	})
+	return files, nil
+}
+
+// ReadLogFile returns the last N lines of a log file (gzip-compressed or not); lines <= 0 reads everything
+func ReadLogFile(path string, lines int) (string, error) {
+	var data []byte
+	var err error
+	if strings.HasSuffix(path, ".gz") {
+		data, err = readGzipFile(path)
+	} else if lines > 0 {
+		data, err = tailFile(path, lines)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	return strings.Join(lastLines(SplitLogLines(string(data)), lines), "\n") + "\n", nil
+}
+
+// TailConsoleLog returns the last N console lines, reading back through rotated files as needed
+func TailConsoleLog(server int, lines int) (string, error) {
+	files, err := ListConsoleLogs(server)
+	if err != nil {
+		return "", err
+	}
+
+	var collected []string
+	found := false
+	for _, file := range files {
+		if !strings.HasPrefix(file.Name, consoleLogPrefix) {
+			continue
+		}
+		found = true
+		output, err := ReadLogFile(file.Path, lines-len(collected))
+		if err != nil {
+			return "", err
+		}
+		collected = append(SplitLogLines(output), collected...)
+		if len(collected) >= lines {
+			break
+		}
+	}
+	if !found {
+		return "", fmt.Errorf("no console logs for server %d", server)
+	}
+	return strings.Join(lastLines(collected, lines), "\n") + "\n", nil
+}
+
+// lastLines returns the last n lines (all of them if n <= 0)
+func lastLines(lines []string, n int) []string {
+	if n > 0 && len(lines) > n {
+		return lines[len(lines)-n:]
+	}
+	return lines
+}
+
+// tailFile reads backwards from the end of a file until it has N lines
+func tailFile(path string, lines int) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	const chunkSize = 64 * 1024
+	offset := info.Size()
+	var data []byte
+	for offset > 0 && bytes.Count(data, []byte("\n")) <= lines {
+		readSize := int64(chunkSize)
+		if offset < readSize {
+			readSize = offset
+		}
+		offset -= readSize
+		chunk := make([]byte, readSize)
+		if _, err := f.ReadAt(chunk, offset); err != nil && err != io.EOF {
+			return nil, err
+		}
+		data = append(chunk, data...)
+	}
+	return data, nil
+}
+
+// readGzipFile reads and decompresses a gzip file
+func readGzipFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	return io.ReadAll(gz)
+}
+
+// gzipFile compresses path to path.gz and removes the original, keeping its modification time
+func gzipFile(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	out, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0640)
+	if err != nil {
+		return fmt.Errorf("failed to create %s.gz: %w", path, err)
+	}
+	gz := gzip.NewWriter(out)
+	if _, err := io.Copy(gz, in); err != nil {
+		gz.Close()
+		out.Close()
+		os.Remove(path + ".gz")
+		return fmt.Errorf("failed to compress %s: %w", path, err)
+	}
+	if err := gz.Close(); err != nil {
+		out.Close()
+		os.Remove(path + ".gz")
+		return fmt.Errorf("failed to compress %s: %w", path, err)
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(path + ".gz")
+		return fmt.Errorf("failed to compress %s: %w", path, err)
+	}
+
+	_ = os.Chtimes(path+".gz", info.ModTime(), info.ModTime())
+	return os.Remove(path)
+}
+
+// fileExists checks if a path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,57 @@
+package hytale
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCopyConsoleLinesDrainsAfterLogError(t *testing.T) {
+	// A regular file where the logs directory should be, so every write fails
+	blocked := filepath.Join(t.TempDir(), "logs")
+	if err := os.WriteFile(blocked, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	w := &ConsoleLogWriter{dir: filepath.Join(blocked, "server-1"), maxSize: 1 << 20, maxAge: time.Hour}
+
+	var output strings.Builder
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&output, "[INFO] line %d\n", i)
+	}
+	var tee bytes.Buffer
+	var reports []error
+	err := CopyConsoleLines(w, strings.NewReader(output.String()), &tee, func(err error) {
+		reports = append(reports, err)
+	})
+
+	if err == nil {
+		t.Fatal("CopyConsoleLines returned no error although the log can't be written")
+	}
+	if len(reports) != 1 || reports[0] != err {
+		t.Errorf("log error reported %d times, want once with the returned error", len(reports))
+	}
+	if tee.String() != output.String() {
+		t.Errorf("tee got %d of %d bytes; the output must keep flowing after a log error", tee.Len(), output.Len())
+	}
+}
+
+func TestCopyConsoleLines(t *testing.T) {
+	w := &ConsoleLogWriter{dir: t.TempDir(), maxSize: 1 << 20, maxAge: time.Hour}
+	defer w.Close()
+
+	if err := CopyConsoleLines(w, strings.NewReader("\x1b[32mstarted\x1b[0m\nno newline"), nil, nil); err != nil {
+		t.Fatalf("CopyConsoleLines: %v", err)
+	}
+	data, err := os.ReadFile(w.currentPath(time.Now().Format(consoleLogDateFormat)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "] started") || !strings.HasSuffix(lines[1], "] no newline") {
+		t.Errorf("console log = %q", data)
+	}
+}
@@ -34,6 +34,21 @@ const (
 	// DefaultBackupFrequency in minutes (Host Havoc recommendation: 60)
 	DefaultBackupFrequency = 60
 
+	// DefaultSnapshotSaveCommand is sent to a running server to flush the world to disk before a snapshot
+	DefaultSnapshotSaveCommand = "/save"
+
+	// DefaultSnapshotSaveTimeoutSeconds is how long a snapshot waits for the save to be confirmed
+	DefaultSnapshotSaveTimeoutSeconds = 30
+
+	// DefaultRestartWarnings is the countdown of a warned restart (time left at each broadcast)
+	DefaultRestartWarnings = "15m,5m,1m,30s,10s"
+
+	// DefaultRestartWarningCommand is broadcast at each countdown step; {time} becomes the time left
+	DefaultRestartWarningCommand = "/say Server restarting in {time}"
+
+	// DefaultRestartCancelCommand is broadcast when a warned restart is cancelled
+	DefaultRestartCancelCommand = "/say Restart cancelled"
+
 	// DefaultJVMArgs for server launch
 	// Based on Host Havoc optimization guide: https://hosthavoc.com/blog/hytale-server-optimization-guide
 	// -Xms and -Xmx should match to avoid memory resizing
@@ -75,10 +90,13 @@ type BootstrapConfig struct {
 	MaxViewRadius    int    // View distance in chunks
 	GameMode         string // Adventure, Survival, or Creative
 	JVMArgs          string
+	ProcessBackend   string // "tmux" (default) or "systemd"
 	BackupEnabled    bool   // Enable automatic backups
 	BackupFrequency  int    // Backup frequency in minutes
 	// OAuth credentials for hytale-downloader authentication
 	OAuthClientID     string // OAuth client ID (optional)
 	OAuthClientSecret string // OAuth client secret (optional)
 	OAuthAccessToken  string // OAuth access token (optional, alternative to Client ID/Secret)
+	// Where hytale-downloader's sign-in prompt is shown when there are no credentials (set by the caller, not the wizard)
+	DeviceCodeCallback DeviceCodeCallback
 }
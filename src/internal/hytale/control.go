@@ -0,0 +1,81 @@
+package hytale
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+)
+
+// LaunchSettings bundles everything needed to launch a server besides its number
+type LaunchSettings struct {
+	JarPath         string
+	JVMArgs         string
+	BackupEnabled   bool
+	BackupFrequency int
+	SessionTokens   *SessionTokens
+}
+
+// LoadLaunchSettings gathers launch settings from the manifest, shared backup config and session tokens
+func LoadLaunchSettings(manifest *Manifest) LaunchSettings {
+	// Get backup settings from shared config file
+	backupConfig, err := ReadBackupConfig()
+	if err != nil {
+		// Fall back to defaults if config can't be read
+		backupConfig = DefaultBackupConfig()
+	}
+
+	// Try to load session tokens (for automatic authentication)
+	// Per Server Provider Authentication Guide: https://support.hytale.com/hc/en-us/articles/45328341414043
+	// If tokens exist and are valid, servers start authenticated without manual /auth
+	sessionTokens, _ := LoadSessionTokens()
+
+	return LaunchSettings{
+		// Fallback for servers without their own copy of the game (Start prefers the server's)
+		JarPath:         filepath.Join(DataDirBase, "master-install", "Server", "HytaleServer.jar"),
+		JVMArgs:         manifest.JVMArgs,
+		BackupEnabled:   backupConfig.Enabled,
+		BackupFrequency: backupConfig.Frequency,
+		SessionTokens:   sessionTokens,
+	}
+}
+
+// StartServer starts a single server instance with the given launch settings
+func (tm *TmuxManager) StartServer(server int, settings LaunchSettings) error {
+	if !ServerExists(server) {
+		return fmt.Errorf("server %d does not exist", server)
+	}
+	return tm.Start(server, GetServerDir(server), settings.JarPath, settings.JVMArgs, settings.BackupEnabled, settings.BackupFrequency, settings.SessionTokens)
+}
+
+// RestartServer stops a server (if running) and starts it again
+func (tm *TmuxManager) RestartServer(server int, settings LaunchSettings) error {
+	if tm.HasSession(server) {
+		if _, err := tm.Stop(server); err != nil {
+			return fmt.Errorf("failed to stop server %d: %w", server, err)
+		}
+	}
+	return tm.StartServer(server, settings)
+}
+
+// ServerActionResult is the outcome of an action run against one server
+type ServerActionResult struct {
+	Server int
+	Detail string // Optional summary of what the action did (e.g. how a server was stopped)
+	Err    error
+}
+
+// RunOnServers runs action concurrently for each server and returns the results in the order given
+func RunOnServers(servers []int, action func(server int) (string, error)) []ServerActionResult {
+	results := make([]ServerActionResult, len(servers))
+	var wg sync.WaitGroup
+	for i, server := range servers {
+		wg.Add(1)
+		go func(i, server int) {
+			defer wg.Done()
+			detail, err := action(server)
+			results[i] = ServerActionResult{Server: server, Detail: detail, Err: err}
+		}(i, server)
+	}
+	wg.Wait()
+	return results
+}
@@ -85,20 +85,24 @@ func CopyDir(ctx context.Context, src, dst string, excludeDirs []string) error {
 	})
 }
 
-// CopyMasterToServer copies files from master-install to a server instance
+// masterCopyExcludes are the master-install paths that are unique per server and never copied
+var masterCopyExcludes = []string{
+	"universe",
+	"logs",
+	"config.json", // We'll create this separately
+}
+
+// CopyMasterToServer copies files from the master-install of the server's patchline to a server instance
 // Excludes universe/, logs/, and server-specific configs
 func CopyMasterToServer(ctx context.Context, serverNum int) error {
-	masterDir := filepath.Join(DataDirBase, "master-install")
+	patchline := ServerPatchline(LoadManifestOrDefault(), serverNum)
+	masterDir := GetPatchlineMasterDir(patchline)
 	serverDir := GetServerDir(serverNum)
-
-	// Exclude directories that should be unique per server
-	excludeDirs := []string{
-		"universe",
-		"logs",
-		"config.json", // We'll create this separately
+	if _, err := os.Stat(masterDir); os.IsNotExist(err) {
+		return fmt.Errorf("no %s game version downloaded yet - run 'hsm update game'", patchline)
 	}
 
-	return CopyDir(ctx, masterDir, serverDir, excludeDirs)
+	return CopyDir(ctx, masterDir, serverDir, masterCopyExcludes)
 }
 
 // CopySharedToServer copies shared configs and mods to a server instance
@@ -106,20 +110,16 @@ func CopySharedToServer(ctx context.Context, serverNum int) error {
 	sharedDir := GetSharedConfigDir()
 	serverDir := GetServerDir(serverNum)
 
-	// Check if shared directory exists
-	if _, err := os.Stat(sharedDir); os.IsNotExist(err) {
-		// Shared directory doesn't exist yet, create it with defaults
-		if err := os.MkdirAll(sharedDir, 0755); err != nil {
-			return fmt.Errorf("failed to create shared directory: %w", err)
-		}
-		// Create default directories
-		os.MkdirAll(filepath.Join(sharedDir, "mods"), 0755)
-		return nil
+	// Create the shared directory with its default directories if they don't exist yet
+	// (it may already hold config.defaults.json from the setup wizard)
+	if err := os.MkdirAll(filepath.Join(sharedDir, "mods"), 0755); err != nil {
+		return fmt.Errorf("failed to create shared directory: %w", err)
 	}
 
 	// Copy shared configs (but not config.json - that's server-specific)
 	excludeDirs := []string{
-		"config.json", // Server-specific, handled separately
+		"config.json",      // Server-specific, handled separately
+		ConfigDefaultsFile, // Rendered into config.json instead
 	}
 
 	return CopyDir(ctx, sharedDir, serverDir, excludeDirs)
@@ -129,7 +129,7 @@ func CopySharedToServer(ctx context.Context, serverNum int) error {
 func UpdateAllServersFromMaster(ctx context.Context) error {
 	numServers := DetectNumServers()
 	if numServers == 0 {
-		return fmt.Errorf("no servers installed")
+		return ErrNotInstalled
 	}
 
 	for i := 1; i <= numServers; i++ {
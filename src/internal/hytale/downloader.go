@@ -1,13 +1,20 @@
 package hytale
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 )
 
 // DownloaderCredentials holds OAuth credentials for hytale-downloader
@@ -17,11 +24,23 @@ type DownloaderCredentials struct {
 	AccessToken  string `json:"access_token,omitempty"`
 }
 
+// DeviceCode is the sign-in prompt hytale-downloader prints when it has no valid credentials
+// (OAuth device code flow): the user opens URL and enters Code, and the download continues
+type DeviceCode struct {
+	URL  string `json:"url"`
+	Code string `json:"code"`
+}
+
+// DeviceCodeCallback receives the device code prompt so it can be shown to the user
+type DeviceCodeCallback func(code DeviceCode)
+
 // HytaleDownloader handles execution of the hytale-downloader CLI tool
 type HytaleDownloader struct {
 	binaryPath    string
 	credentials   DownloaderCredentials
 	credentialsPath string
+	patchline     string
+	deviceCode    DeviceCodeCallback
 }
 
 // NewHytaleDownloader creates a new HytaleDownloader instance
@@ -57,9 +76,21 @@ func NewHytaleDownloaderWithPath(cfg BootstrapConfig, binaryPath string) (*Hytal
 		binaryPath:      binaryPath,
 		credentials:     creds,
 		credentialsPath: credentialsPath,
+		patchline:       PatchlineProduction,
+		deviceCode:      cfg.DeviceCodeCallback,
 	}, nil
 }
 
+// SetPatchline selects the patchline to check and download (production by default)
+func (hd *HytaleDownloader) SetPatchline(patchline string) {
+	hd.patchline = patchline
+}
+
+// SetDeviceCodeCallback sets where sign-in prompts go; without one the user never sees them
+func (hd *HytaleDownloader) SetDeviceCodeCallback(callback DeviceCodeCallback) {
+	hd.deviceCode = callback
+}
+
 // SaveCredentials saves OAuth credentials to the credentials file
 // Returns path to credentials file and error
 func (hd *HytaleDownloader) SaveCredentials() (string, error) {
@@ -97,9 +128,9 @@ func (hd *HytaleDownloader) SaveCredentials() (string, error) {
 	return hd.credentialsPath, nil
 }
 
-// Download downloads Hytale server files to the specified directory
-// Returns error if download fails
-func (hd *HytaleDownloader) Download(ctx context.Context, outputDir string, progressCallback ProgressCallback) error {
+// resolveCredentialsPath returns the credentials file to pass to hytale-downloader, or "" to let it
+// use environment or cached credentials
+func (hd *HytaleDownloader) resolveCredentialsPath() (string, error) {
 	// Save credentials if provided
 	credPath, err := hd.SaveCredentials()
 	if err != nil {
@@ -113,9 +144,48 @@ func (hd *HytaleDownloader) Download(ctx context.Context, outputDir string, prog
 			credPath = ""
 		} else {
 			// Other error saving credentials
-			return fmt.Errorf("failed to save credentials: %w", err)
+			return "", fmt.Errorf("failed to save credentials: %w", err)
 		}
 	}
+	return credPath, nil
+}
+
+// PrintVersion asks hytale-downloader for the latest game version without downloading it
+func (hd *HytaleDownloader) PrintVersion(ctx context.Context) (string, error) {
+	credPath, err := hd.resolveCredentialsPath()
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{
+		"-print-version",
+		"-patchline", hd.patchline,
+		"-skip-update-check",
+	}
+	if credPath != "" {
+		args = append(args, "-credentials-path", credPath)
+	}
+
+	stdoutLines, output, err := hd.run(ctx, "", args, nil)
+	if err != nil {
+		return "", fmt.Errorf("hytale-downloader -print-version failed: %w\nOutput: %s", err, output)
+	}
+
+	// The version is the last line printed; anything before it is informational
+	// (a sign-in prompt, for example)
+	if len(stdoutLines) == 0 {
+		return "", fmt.Errorf("hytale-downloader -print-version printed no version")
+	}
+	return stdoutLines[len(stdoutLines)-1], nil
+}
+
+// Download downloads Hytale server files to the specified directory
+// Returns error if download fails
+func (hd *HytaleDownloader) Download(ctx context.Context, outputDir string, progressCallback ProgressCallback) error {
+	credPath, err := hd.resolveCredentialsPath()
+	if err != nil {
+		return err
+	}
 
 	// Ensure output directory exists
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
@@ -123,13 +193,13 @@ func (hd *HytaleDownloader) Download(ctx context.Context, outputDir string, prog
 	}
 
 	// Build command based on QUICKSTART.md:
-	// hytale-downloader -patchline production -download-path <path>
+	// hytale-downloader -patchline <patchline> -download-path <path>
 	// Note: According to QUICKSTART.md, -download-path is for a zip file
 	// But we need to download to a directory. We'll try without -download-path first,
 	// as the default behavior may download to current directory.
 	// If that doesn't work, we may need to adjust based on actual hytale-downloader behavior.
 	args := []string{
-		"-patchline", "production",
+		"-patchline", hd.patchline,
 		"-skip-update-check", // Skip auto-update check during automation
 	}
 
@@ -138,23 +208,27 @@ func (hd *HytaleDownloader) Download(ctx context.Context, outputDir string, prog
 		args = append(args, "-credentials-path", credPath)
 	}
 
-	// Create command
-	cmd := exec.CommandContext(ctx, hd.binaryPath, args...)
-	
-	// Set working directory (hytale-downloader may use cwd)
-	cmd.Dir = outputDir
-
-	// Capture output for progress tracking
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("hytale-downloader failed: %w\nOutput: %s", err, string(output))
+	label := "Downloading server files..."
+	if hd.patchline != PatchlineProduction {
+		label = fmt.Sprintf("Downloading %s server files...", hd.patchline)
+	}
+	if progressCallback != nil {
+		progressCallback(0, label)
 	}
 
-	// Parse output for progress (if callback provided)
-	if progressCallback != nil && len(output) > 0 {
-		// Try to extract progress from output
-		// hytale-downloader output format may vary, so we provide generic feedback
-		progressCallback(0.9, "Downloading server files...")
+	// Run in the output directory (hytale-downloader may use cwd), reporting the
+	// percentage it prints as it downloads
+	lastPercent := -1.0
+	_, output, err := hd.run(ctx, outputDir, args, func(line string) {
+		percent, ok := parseDownloadPercent(line)
+		if !ok || percent == lastPercent || progressCallback == nil {
+			return
+		}
+		lastPercent = percent
+		progressCallback(percent/100, label)
+	})
+	if err != nil {
+		return fmt.Errorf("hytale-downloader failed: %w\nOutput: %s", err, output)
 	}
 
 	// Verify downloaded files
@@ -169,6 +243,162 @@ func (hd *HytaleDownloader) Download(ctx context.Context, outputDir string, prog
 	return nil
 }
 
+// downloaderOutputLines is how much of hytale-downloader's output is kept for error messages
+const downloaderOutputLines = 20
+
+var (
+	// downloaderPercentPattern matches a progress percentage such as "45%" or "45.3 %"
+	downloaderPercentPattern = regexp.MustCompile(`(\d{1,3}(?:\.\d+)?)\s?%`)
+	// downloaderURLPattern matches a URL in hytale-downloader's output
+	downloaderURLPattern = regexp.MustCompile(`https?://[^\s"'<>]+`)
+	// downloaderCodePattern matches the code of a device code prompt, e.g. "Authorization code: ABCD-EFGH"
+	downloaderCodePattern = regexp.MustCompile(`(?i)\bcode\b\s*[:=]\s*([A-Za-z0-9][A-Za-z0-9-]{3,})`)
+)
+
+// run runs hytale-downloader, streaming stdout and stderr line by line
+// Each line goes to onLine (if set), and sign-in prompts go to the device code callback as soon
+// as they are printed, since hytale-downloader waits for the user to sign in before continuing.
+// Returns the non-empty stdout lines, and the last lines of all output for error messages.
+func (hd *HytaleDownloader) run(ctx context.Context, dir string, args []string, onLine func(line string)) ([]string, string, error) {
+	cmd := exec.CommandContext(ctx, hd.binaryPath, args...)
+	cmd.Dir = dir
+	stdoutPipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	stderrPipe, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create stderr pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, "", fmt.Errorf("failed to start hytale-downloader: %w", err)
+	}
+
+	type outputLine struct {
+		text   string
+		stdout bool
+	}
+	lines := make(chan outputLine)
+	var wg sync.WaitGroup
+	scan := func(r io.Reader, stdout bool) {
+		defer wg.Done()
+		scanner := bufio.NewScanner(r)
+		scanner.Split(scanDownloaderLines)
+		for scanner.Scan() {
+			lines <- outputLine{text: strings.TrimSpace(scanner.Text()), stdout: stdout}
+		}
+		// Keep draining so the process never blocks on a full pipe
+		io.Copy(io.Discard, r)
+	}
+	wg.Add(2)
+	go scan(stdoutPipe, true)
+	go scan(stderrPipe, false)
+	go func() {
+		wg.Wait()
+		close(lines)
+	}()
+
+	var stdoutLines, tail []string
+	var prompt deviceCodePrompt
+	lastWasProgress := false
+	for line := range lines {
+		if line.text == "" {
+			continue
+		}
+		if line.stdout {
+			stdoutLines = append(stdoutLines, line.text)
+		}
+		// Progress bars redraw the same line; only the last state is worth keeping
+		_, isProgress := parseDownloadPercent(line.text)
+		if isProgress && lastWasProgress {
+			tail[len(tail)-1] = line.text
+		} else {
+			tail = append(tail, line.text)
+		}
+		lastWasProgress = isProgress
+		if len(tail) > downloaderOutputLines {
+			tail = tail[1:]
+		}
+		if code, ok := prompt.parse(line.text); ok && hd.deviceCode != nil {
+			hd.deviceCode(code)
+		}
+		if onLine != nil {
+			onLine(line.text)
+		}
+	}
+	return stdoutLines, strings.Join(tail, "\n"), cmd.Wait()
+}
+
+// scanDownloaderLines splits output into lines at \n or \r, so progress bars redrawn
+// with a carriage return arrive as separate lines
+func scanDownloaderLines(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// parseDownloadPercent returns the progress percentage in a line of hytale-downloader output
+func parseDownloadPercent(line string) (float64, bool) {
+	match := downloaderPercentPattern.FindStringSubmatch(line)
+	if match == nil {
+		return 0, false
+	}
+	percent, err := strconv.ParseFloat(match[1], 64)
+	if err != nil || percent < 0 || percent > 100 {
+		return 0, false
+	}
+	return percent, true
+}
+
+// deviceCodePrompt collects a device code prompt spread over several lines of output
+type deviceCodePrompt struct {
+	current  DeviceCode
+	reported DeviceCode
+}
+
+// parse reads one line of output and returns the prompt once it is complete or has changed
+// The verification URL may carry the code itself (?user_code=...); download URLs are ignored
+func (p *deviceCodePrompt) parse(line string) (DeviceCode, bool) {
+	rawURL := downloaderURLPattern.FindString(line)
+	if rawURL != "" {
+		rawURL = strings.TrimRight(rawURL, ".,;:)")
+		lower := strings.ToLower(line)
+		isSignIn := false
+		for _, hint := range []string{"device", "verif", "activate", "login", "sign in", "sign-in", "auth"} {
+			if strings.Contains(lower, hint) {
+				isSignIn = true
+				break
+			}
+		}
+		if isSignIn {
+			if p.current.URL == "" || p.current.Code == "" {
+				p.current.URL = rawURL
+			}
+			if parsed, err := url.Parse(rawURL); err == nil {
+				if code := parsed.Query().Get("user_code"); code != "" {
+					p.current.Code = code
+				}
+			}
+		}
+	}
+	if match := downloaderCodePattern.FindStringSubmatch(strings.Replace(line, rawURL, "", 1)); match != nil {
+		p.current.Code = match[1]
+	}
+
+	if p.current.URL == "" || p.current.Code == "" || p.current == p.reported {
+		return DeviceCode{}, false
+	}
+	p.reported = p.current
+	return p.current, true
+}
+
 // VerifyDownload verifies that required files are present after download
 // Checks for HytaleServer.jar and Assets.zip
 func (hd *HytaleDownloader) VerifyDownload(outputDir string) error {
@@ -109,7 +109,7 @@ func extractHytaleDownloaderBinary(ctx context.Context, zipPath, extractDir stri
 	} else if runtime.GOOS == "windows" && runtime.GOARCH == "amd64" {
 		targetBinary = "hytale-downloader-windows-amd64.exe"
 	} else {
-		return "", fmt.Errorf("unsupported platform: %s/%s (only linux/amd64 and windows/amd64 are supported)", runtime.GOOS+"/"+runtime.GOARCH)
+		return "", fmt.Errorf("unsupported platform: %s/%s (only linux/amd64 and windows/amd64 are supported)", runtime.GOOS, runtime.GOARCH)
 	}
 
 	// Find and extract the target binary
@@ -0,0 +1,401 @@
+package hytale
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Kinds of drift a server can have
+const (
+	DriftConfig   = "config"   // config.json differs from its layers (or the baseline server)
+	DriftMods     = "mods"     // mods/ differs from shared/mods
+	DriftBinaries = "binaries" // Game files differ from the server's game version
+)
+
+// DriftItem is one difference between a server and what it should be
+// For config drift Path is a dotted config key and the values are JSON; for files Path is
+// relative to the server directory and the values are short SHA-256 hashes.
+// An empty Expected means the item shouldn't be there, an empty Actual that it is missing.
+type DriftItem struct {
+	Kind     string `json:"kind"`
+	Path     string `json:"path"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// ServerDrift is the drift report of one server
+type ServerDrift struct {
+	Server int         `json:"server"`
+	Items  []DriftItem `json:"items"`
+	Error  string      `json:"error,omitempty"` // The server could not be checked
+}
+
+// Count returns the number of drifted items of a kind ("" counts all)
+func (d ServerDrift) Count(kind string) int {
+	if kind == "" {
+		return len(d.Items)
+	}
+	n := 0
+	for _, item := range d.Items {
+		if item.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// Summary describes the drift in a few words, e.g. "2 config, 1 mods"
+func (d ServerDrift) Summary() string {
+	if d.Error != "" {
+		return "error: " + d.Error
+	}
+	var parts []string
+	for _, kind := range []string{DriftConfig, DriftMods, DriftBinaries} {
+		if n := d.Count(kind); n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, kind))
+		}
+	}
+	if len(parts) == 0 {
+		return "in line"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// DriftOptions chooses what config.json is compared against
+type DriftOptions struct {
+	// Baseline 0 compares config.json with what the config layers render; a server number
+	// compares it with that server's config.json, ignoring ServerName and the server's overrides
+	Baseline int
+}
+
+// CheckDrift compares the servers' config, mods and game files with what they should be
+// Each game version is hashed once for the whole report
+func CheckDrift(ctx context.Context, servers []int, opts DriftOptions) ([]ServerDrift, error) {
+	manifest := LoadManifestOrDefault()
+	games := make(map[string]map[string]string)
+	for _, server := range servers {
+		gameDir := ServerGameDir(manifest, server)
+		if _, ok := games[gameDir]; ok {
+			continue
+		}
+		game, err := hashTree(ctx, gameDir, masterCopyExcludes)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to hash %s: %w", gameDir, err)
+		}
+		// Files the shared directory puts over the game's copy (and JSON files the game rewrites
+		// while running) aren't expected to match the game version
+		for path := range game {
+			if _, err := os.Stat(filepath.Join(GetSharedConfigDir(), path)); err == nil || strings.HasSuffix(path, ".json") {
+				delete(game, path)
+			}
+		}
+		games[gameDir] = game
+	}
+	shared, err := hashTree(ctx, filepath.Join(GetSharedConfigDir(), "mods"), nil)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to hash shared mods: %w", err)
+	}
+
+	reports := make([]ServerDrift, len(servers))
+	for i, server := range servers {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		reports[i] = ServerDrift{Server: server, Items: []DriftItem{}}
+		items, err := configDrift(server, opts)
+		if err == nil {
+			reports[i].Items = append(reports[i].Items, items...)
+			items, err = modsDrift(ctx, server, shared)
+		}
+		if err == nil {
+			reports[i].Items = append(reports[i].Items, items...)
+			items, err = binariesDrift(ctx, server, games[ServerGameDir(manifest, server)])
+		}
+		if err != nil {
+			reports[i].Error = err.Error()
+			continue
+		}
+		reports[i].Items = append(reports[i].Items, items...)
+	}
+	return reports, nil
+}
+
+// configDrift compares a server's config.json with its rendered layers or the baseline server
+func configDrift(server int, opts DriftOptions) ([]DriftItem, error) {
+	var current, expected []byte
+	ignore := map[string]bool{}
+	if opts.Baseline == 0 || opts.Baseline == server {
+		var err error
+		if current, expected, err = renderServerConfig(server); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if current, err = os.ReadFile(GetServerConfigPath(server)); err != nil {
+			return nil, fmt.Errorf("failed to read config: %w", err)
+		}
+		if expected, err = os.ReadFile(GetServerConfigPath(opts.Baseline)); err != nil {
+			return nil, fmt.Errorf("failed to read server %d config: %w", opts.Baseline, err)
+		}
+		// The server name and overridden values are meant to differ between servers
+		ignore["ServerName"] = true
+		keys, err := ConfigLayerKeys(server)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			ignore[key] = true
+		}
+	}
+
+	currentDoc, err := parseJSONObject(current)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	expectedDoc, err := parseJSONObject(expected)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse baseline config: %w", err)
+	}
+
+	actual := make(map[string]json.RawMessage)
+	for _, leaf := range flattenConfig(currentDoc, "") {
+		actual[leaf.Key] = leaf.Value
+	}
+	var items []DriftItem
+	seen := make(map[string]bool)
+	for _, leaf := range flattenConfig(expectedDoc, "") {
+		seen[leaf.Key] = true
+		if ignore[leaf.Key] {
+			continue
+		}
+		value, ok := actual[leaf.Key]
+		if ok && jsonEqual(value, leaf.Value) {
+			continue
+		}
+		item := DriftItem{Kind: DriftConfig, Path: leaf.Key, Expected: compactConfigValue(leaf.Value)}
+		if ok {
+			item.Actual = compactConfigValue(value)
+		}
+		items = append(items, item)
+	}
+	for _, leaf := range flattenConfig(currentDoc, "") {
+		if !seen[leaf.Key] && !ignore[leaf.Key] {
+			items = append(items, DriftItem{Kind: DriftConfig, Path: leaf.Key, Actual: compactConfigValue(leaf.Value)})
+		}
+	}
+	return items, nil
+}
+
+// compactConfigValue renders a JSON value on one line
+func compactConfigValue(value json.RawMessage) string {
+	var v interface{}
+	if err := json.Unmarshal(value, &v); err != nil {
+		return string(value)
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// modsDrift compares a server's mods/ with shared/mods: missing, changed and extra files
+func modsDrift(ctx context.Context, server int, shared map[string]string) ([]DriftItem, error) {
+	actual, err := hashTree(ctx, filepath.Join(GetServerDir(server), "mods"), nil)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to hash mods: %w", err)
+	}
+	return diffHashes(DriftMods, "mods", shared, actual, true), nil
+}
+
+// binariesDrift compares the game files a server got from its game version with that version
+// Files the server has that the game version doesn't (worlds, logs, its own configs) are ignored
+func binariesDrift(ctx context.Context, server int, game map[string]string) ([]DriftItem, error) {
+	serverDir := GetServerDir(server)
+	actual := make(map[string]string, len(game))
+	for path := range game {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		hash, err := hashFile(filepath.Join(serverDir, path))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		actual[path] = hash
+	}
+	return diffHashes(DriftBinaries, "", game, actual, false), nil
+}
+
+// diffHashes lists the files whose hashes differ, sorted by path
+func diffHashes(kind, prefix string, expected, actual map[string]string, extras bool) []DriftItem {
+	var items []DriftItem
+	for path, hash := range expected {
+		if actual[path] != hash {
+			items = append(items, DriftItem{Kind: kind, Path: filepath.Join(prefix, path), Expected: shortHash(hash), Actual: shortHash(actual[path])})
+		}
+	}
+	if extras {
+		for path, hash := range actual {
+			if _, ok := expected[path]; !ok {
+				items = append(items, DriftItem{Kind: kind, Path: filepath.Join(prefix, path), Actual: shortHash(hash)})
+			}
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Path < items[j].Path })
+	return items
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
+
+// hashTree returns the SHA-256 of every regular file under root, keyed by relative path
+func hashTree(ctx context.Context, root string, exclude []string) (map[string]string, error) {
+	if _, err := os.Stat(root); err != nil {
+		return map[string]string{}, err
+	}
+	hashes := make(map[string]string)
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		for _, ex := range exclude {
+			if rel == ex || strings.HasPrefix(rel, ex+string(filepath.Separator)) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		hash, err := hashFile(path)
+		if err != nil {
+			return err
+		}
+		hashes[rel] = hash
+		return nil
+	})
+	return hashes, err
+}
+
+// hashFile returns the hex SHA-256 of a file
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// ReconcileDrift puts servers back in line with their config layers, shared/mods and game version
+// config.json is re-rendered (backing up the old file), mods are copied from shared/mods with extra
+// mods moved to mods.removed-<timestamp>/, and drifted game files are copied from the server's game version.
+// Game files of a running server are left alone; stop it first.
+func (tm *TmuxManager) ReconcileDrift(ctx context.Context, servers []int) []ServerActionResult {
+	reports, err := CheckDrift(ctx, servers, DriftOptions{})
+	if err != nil {
+		results := make([]ServerActionResult, len(servers))
+		for i, server := range servers {
+			results[i] = ServerActionResult{Server: server, Err: err}
+		}
+		return results
+	}
+
+	manifest := LoadManifestOrDefault()
+	results := make([]ServerActionResult, len(reports))
+	for i, report := range reports {
+		results[i].Server = report.Server
+		if report.Error != "" {
+			results[i].Err = fmt.Errorf("%s", report.Error)
+			continue
+		}
+		if report.Count("") == 0 {
+			results[i].Detail = "already in line"
+			continue
+		}
+		if results[i].Err = tm.reconcileServer(report); results[i].Err != nil {
+			continue
+		}
+		if os.Geteuid() == 0 && SystemUserExists(manifest.HytaleUser) {
+			if err := ApplyServerOwnership(manifest.HytaleUser, report.Server); err != nil {
+				results[i].Err = err
+				continue
+			}
+		}
+		results[i].Detail = "reconciled " + report.Summary()
+	}
+	return results
+}
+
+// reconcileServer fixes the drifted items of one server
+func (tm *TmuxManager) reconcileServer(report ServerDrift) error {
+	serverDir := GetServerDir(report.Server)
+	if report.Count(DriftConfig) > 0 {
+		if _, err := RenderServerConfig(report.Server); err != nil {
+			return err
+		}
+	}
+
+	removedDir := filepath.Join(serverDir, "mods.removed-"+time.Now().Format("20060102-150405"))
+	for _, item := range report.Items {
+		switch {
+		case item.Kind == DriftMods && item.Expected == "":
+			// Mods added by hand are moved aside rather than deleted
+			rel, _ := filepath.Rel("mods", item.Path)
+			dst := filepath.Join(removedDir, rel)
+			if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+				return err
+			}
+			if err := os.Rename(filepath.Join(serverDir, item.Path), dst); err != nil {
+				return fmt.Errorf("failed to move %s aside: %w", item.Path, err)
+			}
+		case item.Kind == DriftMods:
+			if err := CopyFile(filepath.Join(GetSharedConfigDir(), item.Path), filepath.Join(serverDir, item.Path)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if report.Count(DriftBinaries) == 0 {
+		return nil
+	}
+	if tm.HasSession(report.Server) {
+		return fmt.Errorf("%d game file(s) differ from the server's game version; stop the server to replace them", report.Count(DriftBinaries))
+	}
+	gameDir := ServerGameDir(LoadManifestOrDefault(), report.Server)
+	for _, item := range report.Items {
+		if item.Kind != DriftBinaries {
+			continue
+		}
+		if err := CopyFile(filepath.Join(gameDir, item.Path), filepath.Join(serverDir, item.Path)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package hytale
+
+import "errors"
+
+// Sentinel errors callers can check with errors.Is (e.g. to pick CLI exit codes)
+var (
+	// ErrNotInstalled is returned when an operation needs server instances but none exist
+	ErrNotInstalled = errors.New("no servers installed - run installation wizard first")
+
+	// ErrAlreadyRunning is returned when starting a server that is already running
+	ErrAlreadyRunning = errors.New("server is already running")
+
+	// ErrNotRunning is returned when an operation needs a running server
+	ErrNotRunning = errors.New("server is not running")
+)
@@ -0,0 +1,300 @@
+package hytale
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CurrentManifestVersion is the manifest schema version written by this build of HSM
+// Bump it together with a new entry in manifestMigrations whenever the schema changes
+const CurrentManifestVersion = 8
+
+// Manifest records how an installation was set up, so runtime commands use the
+// values chosen in the wizard instead of hardcoded defaults
+type Manifest struct {
+	Version        int       `json:"version"`
+	HytaleUser     string    `json:"hytale_user"`
+	BasePort       int       `json:"base_port"`
+	QueryPort      int       `json:"query_port"`
+	HostnamePrefix string    `json:"hostname_prefix"`
+	JVMArgs        string    `json:"jvm_args"`
+	InstalledAt    time.Time `json:"installed_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+
+	// Process backend (version 2+): "tmux" or "systemd"
+	ProcessBackend string `json:"process_backend"`
+	// systemd unit settings, only used by the systemd backend
+	SystemdMemoryMax string `json:"systemd_memory_max,omitempty"` // MemoryMax=, e.g. "8G" (empty = unlimited)
+	SystemdCPUQuota  string `json:"systemd_cpu_quota,omitempty"`  // CPUQuota=, e.g. "200%" (empty = unlimited)
+	SystemdRestart   string `json:"systemd_restart,omitempty"`    // Restart= policy (default "on-failure")
+
+	// Shutdown (version 3+)
+	StopTimeoutSeconds  int    `json:"stop_timeout_seconds"`  // Grace period after /stop before SIGTERM
+	SaveCompletePattern string `json:"save_complete_pattern"` // Regexp matching the console line logged once the world is saved
+
+	// Console log rotation (version 4+)
+	ConsoleLogMaxSizeMB  int `json:"console_log_max_size_mb"`  // Rotate server-N/logs/console-<date>.log at this size
+	ConsoleLogMaxAgeDays int `json:"console_log_max_age_days"` // Delete rotated console logs older than this
+
+	// Game builds (version 5+): the versions/<game-version> each server was installed from
+	ServerGameVersions map[int]string `json:"server_game_versions,omitempty"`
+
+	// Game updates (version 6+): the game version last downloaded, as hytale-downloader -print-version reports it
+	GameVersion string `json:"game_version,omitempty"`
+
+	// Rolling updates (version 7+)
+	StartedPattern      string `json:"started_pattern"`       // Regexp matching the console line logged once a server has started
+	StartTimeoutSeconds int    `json:"start_timeout_seconds"` // How long a restarted server gets to log it and bind its port
+
+	// Patchlines (version 8+)
+	Patchline             string            `json:"patchline"`                         // Patchline of servers without their own, "production" or "pre-release"
+	ServerPatchlines      map[int]string    `json:"server_patchlines,omitempty"`       // Servers following another patchline (canaries)
+	PatchlineGameVersions map[string]string `json:"patchline_game_versions,omitempty"` // Like GameVersion, for patchlines other than production
+}
+
+// manifestMigrations upgrades a manifest from version i to version i+1
+// Index 0 handles installations made before the manifest existed (or files without a version)
+var manifestMigrations = []func(m *Manifest) error{
+	migrateManifestV0ToV1,
+	migrateManifestV1ToV2,
+	migrateManifestV2ToV3,
+	migrateManifestV3ToV4,
+	migrateManifestV4ToV5,
+	migrateManifestV5ToV6,
+	migrateManifestV6ToV7,
+	migrateManifestV7ToV8,
+}
+
+// GetManifestPath returns the path to the installation manifest
+func GetManifestPath() string {
+	return filepath.Join(ConfigDir, "manifest.json")
+}
+
+// DefaultManifest returns a manifest populated with the built-in defaults
+func DefaultManifest() *Manifest {
+	return &Manifest{
+		Version:        CurrentManifestVersion,
+		HytaleUser:     DefaultHytaleUser,
+		BasePort:       DefaultBasePort,
+		QueryPort:      DefaultQueryPort,
+		HostnamePrefix: DefaultHostnamePrefix,
+		JVMArgs:        DefaultJVMArgs,
+		ProcessBackend: BackendTmux,
+		SystemdRestart: DefaultSystemdRestart,
+
+		StopTimeoutSeconds:  DefaultStopTimeoutSeconds,
+		SaveCompletePattern: DefaultSaveCompletePattern,
+
+		ConsoleLogMaxSizeMB:  DefaultConsoleLogMaxSizeMB,
+		ConsoleLogMaxAgeDays: DefaultConsoleLogMaxAgeDays,
+
+		StartedPattern:      DefaultStartedPattern,
+		StartTimeoutSeconds: DefaultStartTimeoutSeconds,
+
+		Patchline: PatchlineProduction,
+	}
+}
+
+// NewManifestFromBootstrap creates a manifest from the wizard's bootstrap configuration
+func NewManifestFromBootstrap(cfg BootstrapConfig) *Manifest {
+	m := DefaultManifest()
+	if cfg.HytaleUser != "" {
+		m.HytaleUser = cfg.HytaleUser
+	}
+	if cfg.BasePort != 0 {
+		m.BasePort = cfg.BasePort
+	}
+	if cfg.QueryPort != 0 {
+		m.QueryPort = cfg.QueryPort
+	}
+	if cfg.HostnamePrefix != "" {
+		m.HostnamePrefix = cfg.HostnamePrefix
+	}
+	if cfg.JVMArgs != "" {
+		m.JVMArgs = cfg.JVMArgs
+	}
+	if cfg.ProcessBackend != "" {
+		m.ProcessBackend = cfg.ProcessBackend
+	}
+	m.InstalledAt = time.Now()
+	return m
+}
+
+// ManifestExists checks if an installation manifest has been written
+func ManifestExists() bool {
+	_, err := os.Stat(GetManifestPath())
+	return err == nil
+}
+
+// LoadManifest reads the installation manifest, migrating it to the current version if needed
+// Installations without a manifest (created by older HSM versions) get the built-in defaults
+func LoadManifest() (*Manifest, error) {
+	data, err := os.ReadFile(GetManifestPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return DefaultManifest(), nil
+		}
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	if m.Version > CurrentManifestVersion {
+		return nil, fmt.Errorf("manifest version %d is newer than this HSM supports (%d) - please update HSM", m.Version, CurrentManifestVersion)
+	}
+
+	if m.Version < CurrentManifestVersion {
+		if err := migrateManifest(&m); err != nil {
+			return nil, err
+		}
+		// Persist the migrated manifest so the migration only runs once
+		// Best effort: the migrated manifest is still usable in memory if this fails
+		_ = SaveManifest(&m)
+	}
+
+	return &m, nil
+}
+
+// LoadManifestOrDefault reads the installation manifest, falling back to defaults if it can't be read
+func LoadManifestOrDefault() *Manifest {
+	m, err := LoadManifest()
+	if err != nil {
+		return DefaultManifest()
+	}
+	return m
+}
+
+// SaveManifest writes the installation manifest to ConfigDir
+func SaveManifest(m *Manifest) error {
+	manifestPath := GetManifestPath()
+
+	// Ensure directory exists
+	if err := os.MkdirAll(filepath.Dir(manifestPath), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	m.Version = CurrentManifestVersion
+	m.UpdatedAt = time.Now()
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	// Write to a temp file and rename so a crash never leaves a half-written manifest
+	tmpPath := manifestPath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := os.Rename(tmpPath, manifestPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	return nil
+}
+
+// migrateManifest runs all migrations from the manifest's version up to CurrentManifestVersion
+func migrateManifest(m *Manifest) error {
+	for m.Version < CurrentManifestVersion {
+		if m.Version < 0 || m.Version >= len(manifestMigrations) {
+			return fmt.Errorf("no migration available for manifest version %d", m.Version)
+		}
+		if err := manifestMigrations[m.Version](m); err != nil {
+			return fmt.Errorf("failed to migrate manifest from version %d: %w", m.Version, err)
+		}
+		m.Version++
+	}
+	return nil
+}
+
+// migrateManifestV0ToV1 fills in defaults for any values a version-less manifest is missing
+func migrateManifestV0ToV1(m *Manifest) error {
+	defaults := DefaultManifest()
+	if m.HytaleUser == "" {
+		m.HytaleUser = defaults.HytaleUser
+	}
+	if m.BasePort == 0 {
+		m.BasePort = defaults.BasePort
+	}
+	if m.QueryPort == 0 {
+		m.QueryPort = m.BasePort + 1
+	}
+	if m.HostnamePrefix == "" {
+		m.HostnamePrefix = defaults.HostnamePrefix
+	}
+	if m.JVMArgs == "" {
+		m.JVMArgs = defaults.JVMArgs
+	}
+	return nil
+}
+
+// migrateManifestV1ToV2 adds process backend selection; existing installations keep using tmux
+func migrateManifestV1ToV2(m *Manifest) error {
+	if m.ProcessBackend == "" {
+		m.ProcessBackend = BackendTmux
+	}
+	if m.SystemdRestart == "" {
+		m.SystemdRestart = DefaultSystemdRestart
+	}
+	return nil
+}
+
+// migrateManifestV2ToV3 adds the graceful shutdown settings
+func migrateManifestV2ToV3(m *Manifest) error {
+	if m.StopTimeoutSeconds <= 0 {
+		m.StopTimeoutSeconds = DefaultStopTimeoutSeconds
+	}
+	if m.SaveCompletePattern == "" {
+		m.SaveCompletePattern = DefaultSaveCompletePattern
+	}
+	return nil
+}
+
+// migrateManifestV3ToV4 adds console log rotation settings
+func migrateManifestV3ToV4(m *Manifest) error {
+	if m.ConsoleLogMaxSizeMB <= 0 {
+		m.ConsoleLogMaxSizeMB = DefaultConsoleLogMaxSizeMB
+	}
+	if m.ConsoleLogMaxAgeDays <= 0 {
+		m.ConsoleLogMaxAgeDays = DefaultConsoleLogMaxAgeDays
+	}
+	return nil
+}
+
+// migrateManifestV4ToV5 adds per-server game versions
+// Servers stay unrecorded (following master-install) until they are next updated or rolled back
+func migrateManifestV4ToV5(m *Manifest) error {
+	return nil
+}
+
+// migrateManifestV5ToV6 adds the installed game version
+// It stays empty until the next download, so the first update check after upgrading reports an update
+func migrateManifestV5ToV6(m *Manifest) error {
+	return nil
+}
+
+// migrateManifestV6ToV7 adds the health check settings of rolling game updates
+func migrateManifestV6ToV7(m *Manifest) error {
+	if m.StartedPattern == "" {
+		m.StartedPattern = DefaultStartedPattern
+	}
+	if m.StartTimeoutSeconds <= 0 {
+		m.StartTimeoutSeconds = DefaultStartTimeoutSeconds
+	}
+	return nil
+}
+
+// migrateManifestV7ToV8 adds patchlines; existing installations follow production
+func migrateManifestV7ToV8(m *Manifest) error {
+	if m.Patchline == "" {
+		m.Patchline = PatchlineProduction
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package hytale
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Process backend names stored in the installation manifest
+const (
+	BackendTmux    = "tmux"
+	BackendSystemd = "systemd"
+)
+
+// ProcessBackend controls the processes of an installation's server instances
+// TmuxManager builds the server command line and delegates process control here
+type ProcessBackend interface {
+	// Name returns the backend name (BackendTmux or BackendSystemd)
+	Name() string
+
+	// SessionName returns the backend's name for a server's process (tmux session or systemd unit)
+	SessionName(server int) string
+
+	// Start launches argv (argv[0] is the program) in dataDir for the given server
+	Start(server int, dataDir string, argv []string) error
+
+	// Stop tears down what the backend runs the server in (tmux session, systemd unit)
+	// TmuxManager calls it after the server process has exited, or to force it down
+	Stop(server int) error
+
+	// IsRunning reports whether the server's process is alive
+	IsRunning(server int) bool
+
+	// MainPID returns the PID of the server's JVM (0 if it isn't running)
+	MainPID(server int) (int, error)
+
+	// Logs returns the last lines of the server's console output
+	Logs(server int, lines int) (string, error)
+
+	// SendCommand types a command into the server console
+	SendCommand(server int, command string) error
+
+	// Remove forcibly tears down everything the backend created for a server
+	Remove(server int) error
+}
+
+// NewProcessBackend returns the process backend selected in the installation manifest
+func NewProcessBackend(manifest *Manifest) (ProcessBackend, error) {
+	switch manifest.ProcessBackend {
+	case BackendTmux, "":
+		return newTmuxBackend(manifest.HytaleUser), nil
+	case BackendSystemd:
+		return newSystemdBackend(manifest), nil
+	default:
+		return nil, fmt.Errorf("unknown process backend %q (expected %s or %s)", manifest.ProcessBackend, BackendTmux, BackendSystemd)
+	}
+}
+
+// ValidProcessBackends lists the backends an installation can be configured with
+func ValidProcessBackends() []string {
+	return []string{BackendTmux, BackendSystemd}
+}
+
+// hsmExecutable returns the absolute path of the running hsm binary
+// Backends use it to run `hsm logpipe`, which writes the persistent console logs
+func hsmExecutable() (string, error) {
+	path, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate hsm binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	return path, nil
+}
+
+// shellQuote quotes s for use as a single word in a /bin/sh command
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
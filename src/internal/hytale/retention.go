@@ -0,0 +1,294 @@
+package hytale
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetentionPolicy is a grandfather-father-son retention policy for backups
+// The newest backup of each of the last N hours, days, weeks and months is kept; a backup may
+// count for several of them. MaxTotalSizeMB then drops the oldest kept backups until the rest fit.
+// A policy with every field 0 keeps everything.
+type RetentionPolicy struct {
+	Hourly         int   `json:"hourly"`
+	Daily          int   `json:"daily"`
+	Weekly         int   `json:"weekly"`
+	Monthly        int   `json:"monthly"`
+	MaxTotalSizeMB int64 `json:"max_total_size_mb"`
+}
+
+// Enabled reports whether the policy removes anything at all
+func (p RetentionPolicy) Enabled() bool {
+	return p.Hourly > 0 || p.Daily > 0 || p.Weekly > 0 || p.Monthly > 0 || p.MaxTotalSizeMB > 0
+}
+
+// String summarises the policy, e.g. "24 hourly, 7 daily, 4 weekly, 6 monthly, max 50000 MB"
+func (p RetentionPolicy) String() string {
+	if !p.Enabled() {
+		return "keep everything"
+	}
+	var parts []string
+	for _, c := range []struct {
+		n    int
+		name string
+	}{{p.Hourly, "hourly"}, {p.Daily, "daily"}, {p.Weekly, "weekly"}, {p.Monthly, "monthly"}} {
+		if c.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", c.n, c.name))
+		}
+	}
+	if p.MaxTotalSizeMB > 0 {
+		parts = append(parts, fmt.Sprintf("max %d MB", p.MaxTotalSizeMB))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// RetentionItem is a backup the retention engine decides about
+type RetentionItem struct {
+	Name string    `json:"name"`
+	Time time.Time `json:"time"`
+	Size int64     `json:"size"`
+}
+
+// ApplyRetention splits items into the ones a policy keeps and the ones it removes (both newest first)
+func ApplyRetention(items []RetentionItem, policy RetentionPolicy) (keep, remove []RetentionItem) {
+	sorted := append([]RetentionItem(nil), items...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.After(sorted[j].Time)
+	})
+
+	kept := make([]bool, len(sorted))
+	hasCounts := policy.Hourly > 0 || policy.Daily > 0 || policy.Weekly > 0 || policy.Monthly > 0
+	if !hasCounts {
+		for i := range kept {
+			kept[i] = true
+		}
+	} else {
+		buckets := []struct {
+			count int
+			key   func(time.Time) string
+		}{
+			{policy.Hourly, func(t time.Time) string { return t.Format("2006010215") }},
+			{policy.Daily, func(t time.Time) string { return t.Format("20060102") }},
+			{policy.Weekly, func(t time.Time) string {
+				year, week := t.ISOWeek()
+				return fmt.Sprintf("%d-%02d", year, week)
+			}},
+			{policy.Monthly, func(t time.Time) string { return t.Format("200601") }},
+		}
+		for _, bucket := range buckets {
+			if bucket.count <= 0 {
+				continue
+			}
+			seen := make(map[string]bool)
+			for i, item := range sorted {
+				key := bucket.key(item.Time.Local())
+				if seen[key] {
+					continue
+				}
+				if len(seen) == bucket.count {
+					break
+				}
+				seen[key] = true
+				kept[i] = true
+			}
+		}
+	}
+
+	// The size cap never removes the newest backup
+	var total int64
+	limit := policy.MaxTotalSizeMB << 20
+	for i, item := range sorted {
+		if !kept[i] {
+			remove = append(remove, item)
+			continue
+		}
+		total += item.Size
+		if limit > 0 && total > limit && len(keep) > 0 {
+			remove = append(remove, item)
+			continue
+		}
+		keep = append(keep, item)
+	}
+	return keep, remove
+}
+
+// PruneResult reports what retention did (or would do) to one set of backups
+type PruneResult struct {
+	Scope   string          `json:"scope"` // e.g. "server 1 game backups", "server 1 snapshots on nas"
+	Policy  RetentionPolicy `json:"policy"`
+	Kept    []RetentionItem `json:"kept"`
+	Removed []RetentionItem `json:"removed"`
+	Err     string          `json:"error,omitempty"`
+}
+
+// String summarises a prune result on one line
+func (r PruneResult) String() string {
+	if r.Err != "" {
+		return fmt.Sprintf("%s: %s", r.Scope, r.Err)
+	}
+	var freed int64
+	for _, item := range r.Removed {
+		freed += item.Size
+	}
+	return fmt.Sprintf("%s: keeping %d, removing %d (%d MB) - %s", r.Scope, len(r.Kept), len(r.Removed), freed>>20, r.Policy)
+}
+
+// snapshotNamePattern matches snapshot file names, locally and on targets (optionally encrypted)
+// Snapshots taken within the same second get a -2, -3, ... suffix
+var snapshotNamePattern = regexp.MustCompile(`^server-(\d+)-(\d{8}-\d{6})(-\d+)?\.tar\.gz(\.enc)?$`)
+
+// parseSnapshotName returns the server and time encoded in a snapshot file name
+func parseSnapshotName(name string) (int, time.Time, bool) {
+	m := snapshotNamePattern.FindStringSubmatch(filepath.Base(name))
+	if m == nil {
+		return 0, time.Time{}, false
+	}
+	server, _ := strconv.Atoi(m[1])
+	t, err := time.ParseInLocation("20060102-150405", m[2], time.Local)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+	return server, t, true
+}
+
+// pruneSet applies a policy to one set of backups, deleting the removed ones unless dryRun is set
+func pruneSet(scope string, items []RetentionItem, policy RetentionPolicy, dryRun bool, del func(name string) error) PruneResult {
+	result := PruneResult{Scope: scope, Policy: policy}
+	result.Kept, result.Removed = ApplyRetention(items, policy)
+	if dryRun {
+		return result
+	}
+	var failed []string
+	for _, item := range result.Removed {
+		if err := del(item.Name); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", item.Name, err))
+		}
+	}
+	if len(failed) > 0 {
+		result.Err = "failed to delete " + strings.Join(failed, "; ")
+	}
+	return result
+}
+
+// PruneGameBackups applies the retention policy to a server's game backups
+func PruneGameBackups(server int, policy RetentionPolicy, dryRun bool) PruneResult {
+	scope := fmt.Sprintf("server %d game backups", server)
+	backups, err := ListGameBackups(server)
+	if err != nil {
+		return PruneResult{Scope: scope, Policy: policy, Err: err.Error()}
+	}
+	items := make([]RetentionItem, len(backups))
+	for i, b := range backups {
+		items[i] = RetentionItem{Name: b.Name, Time: b.Time, Size: b.Size}
+	}
+	return pruneSet(scope, items, policy, dryRun, func(name string) error {
+		return DeleteGameBackup(server, name)
+	})
+}
+
+// PruneSnapshots applies the retention policy to a server's local HSM snapshots
+func PruneSnapshots(server int, policy RetentionPolicy, dryRun bool) PruneResult {
+	scope := fmt.Sprintf("server %d snapshots", server)
+	snapshots, err := ListSnapshots(server)
+	if err != nil {
+		return PruneResult{Scope: scope, Policy: policy, Err: err.Error()}
+	}
+	items := make([]RetentionItem, len(snapshots))
+	for i, s := range snapshots {
+		items[i] = RetentionItem{Name: s.Name, Time: s.Created, Size: s.Size}
+	}
+	return pruneSet(scope, items, policy, dryRun, DeleteSnapshot)
+}
+
+// PruneTargetSnapshots applies a target's retention policy to the snapshots it holds, per server
+func PruneTargetSnapshots(ctx context.Context, tc BackupTargetConfig, policy RetentionPolicy, servers []int, dryRun bool) []PruneResult {
+	target, err := NewBackupTarget(tc)
+	if err != nil {
+		return []PruneResult{{Scope: "target " + tc.Name, Policy: policy, Err: err.Error()}}
+	}
+	objects, err := target.List(ctx, "snapshots/")
+	if err != nil {
+		return []PruneResult{{Scope: "target " + tc.Name, Policy: policy, Err: err.Error()}}
+	}
+
+	byServer := make(map[int][]RetentionItem)
+	for _, obj := range objects {
+		server, created, ok := parseSnapshotName(obj.Name)
+		if !ok {
+			continue
+		}
+		byServer[server] = append(byServer[server], RetentionItem{Name: obj.Name, Time: created, Size: obj.Size})
+	}
+
+	var results []PruneResult
+	for _, server := range servers {
+		scope := fmt.Sprintf("server %d snapshots on %s", server, tc.Name)
+		results = append(results, pruneSet(scope, byServer[server], policy, dryRun, func(name string) error {
+			return target.Delete(ctx, name)
+		}))
+	}
+	return results
+}
+
+// PruneBackups applies the configured retention policies to the given servers' game backups,
+// local snapshots and the snapshots on every enabled target
+// With dryRun set nothing is deleted; the results show what would be
+func PruneBackups(ctx context.Context, servers []int, dryRun bool) ([]PruneResult, error) {
+	config, err := ReadBackupConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []PruneResult
+	for _, server := range servers {
+		policy := config.RetentionFor(server)
+		if !policy.Enabled() {
+			continue
+		}
+		results = append(results, PruneGameBackups(server, policy, dryRun))
+		results = append(results, PruneSnapshots(server, policy, dryRun))
+	}
+	for _, tc := range config.Targets {
+		if tc.Disabled {
+			continue
+		}
+		policy := config.Retention
+		if tc.Retention != nil {
+			policy = *tc.Retention
+		}
+		if !policy.Enabled() {
+			continue
+		}
+		results = append(results, PruneTargetSnapshots(ctx, tc, policy, servers, dryRun)...)
+	}
+
+	for _, r := range results {
+		if r.Err != "" {
+			return results, fmt.Errorf("retention failed for %s", r.Scope)
+		}
+	}
+	return results, nil
+}
+
+// latestGameBackup returns the modification time of a server's newest game backup (zero if none)
+// It only stats files, so the daemon can call it on every pass
+func latestGameBackup(server int) time.Time {
+	var latest time.Time
+	_ = filepath.Walk(GetServerBackupDir(server), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !info.IsDir() && isBackupArchive(info.Name()) && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+		return nil
+	})
+	return latest
+}
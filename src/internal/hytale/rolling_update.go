@@ -0,0 +1,303 @@
+package hytale
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultStartedPattern matches the console line logged once a server has finished starting
+	DefaultStartedPattern = `(?i)(server (has )?(started|booted)|server is ready|done \([0-9.]+s\))`
+
+	// DefaultStartTimeoutSeconds is how long a started server gets to pass its health check
+	DefaultStartTimeoutSeconds = 300
+
+	// healthPollInterval is how often a starting server's console and port are checked
+	healthPollInterval = 2 * time.Second
+)
+
+// RollingUpdateOptions configures a rolling game update
+type RollingUpdateOptions struct {
+	BatchSize      int                  // Servers updated at the same time (1 = one after another)
+	Warn           bool                 // Count down on the console before stopping a running server
+	Restart        WarnedRestartOptions // Countdown and broadcast commands used with Warn
+	StartedPattern string               // Console line that says the server has started
+	HealthTimeout  time.Duration        // How long a started server gets to pass its health check
+
+	// Progress, if set, receives a line for each step of the update
+	Progress func(server int, message string)
+	// DownloadProgress and DeviceCode, if set, report on the download before the rollout starts
+	DownloadProgress ProgressCallback
+	DeviceCode       DeviceCodeCallback
+}
+
+// DefaultRollingUpdateOptions returns one-at-a-time updates without a countdown, using the
+// installation's health check settings
+func DefaultRollingUpdateOptions(manifest *Manifest) RollingUpdateOptions {
+	opts := RollingUpdateOptions{
+		BatchSize:      1,
+		Restart:        DefaultWarnedRestartOptions(),
+		StartedPattern: manifest.StartedPattern,
+		HealthTimeout:  time.Duration(manifest.StartTimeoutSeconds) * time.Second,
+	}
+	if opts.StartedPattern == "" {
+		opts.StartedPattern = DefaultStartedPattern
+	}
+	if opts.HealthTimeout <= 0 {
+		opts.HealthTimeout = DefaultStartTimeoutSeconds * time.Second
+	}
+	return opts
+}
+
+func (o RollingUpdateOptions) progress(server int, format string, args ...interface{}) {
+	if o.Progress != nil {
+		o.Progress(server, fmt.Sprintf(format, args...))
+	}
+}
+
+// RollingUpdateGame downloads the latest game version of each patchline in use once, then moves the
+// servers to their patchline's version in batches
+// Each running server is warned (with opts.Warn), stopped, synced from the new version, started and
+// health checked. A server that fails its health check is rolled back to its previous version and the
+// rollout stops, so the servers not reached yet keep running the old version.
+// Stopped servers get the new files and stay stopped. Returns the versions rolled out.
+func (tm *TmuxManager) RollingUpdateGame(ctx context.Context, opts RollingUpdateOptions) (string, []ServerActionResult, error) {
+	pattern, err := regexp.Compile(opts.StartedPattern)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid started pattern: %w", err)
+	}
+	numServers := DetectNumServers()
+	if numServers == 0 {
+		return "", nil, ErrNotInstalled
+	}
+
+	// Servers that were never recorded run what master-install pointed to before the download
+	previous := ActiveGameVersion()
+	manifest := LoadManifestOrDefault()
+	updates, err := downloadPatchlineUpdates(ctx, manifest, numServers, opts.DownloadProgress, opts.DeviceCode)
+	if err != nil {
+		return "", nil, err
+	}
+
+	settings := LoadLaunchSettings(manifest)
+	var results []ServerActionResult
+	var pending []int
+	for server := 1; server <= numServers; server++ {
+		version := updates[ServerPatchline(manifest, server)].version
+		if manifest.ServerGameVersions[server] == version {
+			results = append(results, ServerActionResult{Server: server, Detail: "already on " + version})
+			continue
+		}
+		pending = append(pending, server)
+	}
+
+	batchSize := opts.BatchSize
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	halted := 0
+	for start := 0; start < len(pending); start += batchSize {
+		end := start + batchSize
+		if end > len(pending) {
+			end = len(pending)
+		}
+		batch := pending[start:end]
+
+		if halted != 0 || ctx.Err() != nil {
+			reason := fmt.Errorf("not updated: rollout halted after server %d failed", halted)
+			if halted == 0 {
+				reason = fmt.Errorf("not updated: rollout cancelled")
+			}
+			for _, server := range batch {
+				results = append(results, ServerActionResult{Server: server, Err: reason})
+			}
+			continue
+		}
+
+		batchResults := RunOnServers(batch, func(server int) (string, error) {
+			from := manifest.ServerGameVersions[server]
+			if from == "" {
+				from = previous
+			}
+			to := updates[ServerPatchline(manifest, server)].version
+			return tm.rollServer(ctx, server, from, to, settings, pattern, opts)
+		})
+		for _, result := range batchResults {
+			if result.Err != nil {
+				if halted == 0 {
+					halted = result.Server
+				}
+				continue
+			}
+			version := updates[ServerPatchline(manifest, result.Server)].version
+			if err := RecordServerGameVersions([]int{result.Server}, version); err != nil {
+				return updates.versions(), results, fmt.Errorf("failed to record game versions: %w", err)
+			}
+		}
+		results = append(results, batchResults...)
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Server < results[j].Server })
+	return updates.versions(), results, nil
+}
+
+// rollServer moves one server from one game version to another, rolling it back if it doesn't come up healthy
+func (tm *TmuxManager) rollServer(ctx context.Context, server int, from, to string, settings LaunchSettings, pattern *regexp.Regexp, opts RollingUpdateOptions) (string, error) {
+	wasRunning := tm.HasSession(server)
+	if wasRunning {
+		if opts.Warn {
+			warn := opts.Restart
+			warn.Progress = opts.Progress
+			if err := tm.restartCountdown(ctx, server, warn); err != nil {
+				if warn.CancelCommand != "" && tm.HasSession(server) {
+					_ = tm.backend.SendCommand(server, warn.CancelCommand)
+				}
+				return "", fmt.Errorf("update cancelled: %w", err)
+			}
+		}
+		if tm.HasSession(server) {
+			opts.progress(server, "Stopping")
+			if _, err := tm.StopWithContext(ctx, server); err != nil {
+				return "", fmt.Errorf("failed to stop: %w", err)
+			}
+		}
+	}
+
+	opts.progress(server, "Copying game version %s", to)
+	if err := syncServerGame(ctx, server, to); err != nil {
+		return "", err
+	}
+	if !wasRunning {
+		return "updated to " + to + " (not running)", nil
+	}
+
+	opts.progress(server, "Starting and waiting for it to become healthy")
+	healthErr := tm.startHealthy(ctx, server, settings, pattern, opts.HealthTimeout)
+	if healthErr == nil {
+		opts.progress(server, "Healthy on %s", to)
+		return "updated to " + to + ", healthy", nil
+	}
+
+	// Roll back even if the rollout was cancelled, so the server isn't left on an unchecked version
+	opts.progress(server, "Health check failed (%v), rolling back to %s", healthErr, from)
+	if tm.HasSession(server) {
+		_, _ = tm.StopWithContext(context.Background(), server)
+	}
+	if from == "" || !fileExists(GetVersionDir(from)) {
+		return "", fmt.Errorf("%v; no previous game version to roll back to", healthErr)
+	}
+	if err := syncServerGame(context.Background(), server, from); err != nil {
+		return "", fmt.Errorf("%v; rollback to %s failed: %w", healthErr, from, err)
+	}
+	if err := tm.StartServer(server, settings); err != nil {
+		return "", fmt.Errorf("%v; rolled back to %s but failed to start: %w", healthErr, from, err)
+	}
+	return "", fmt.Errorf("%v; rolled back to %s", healthErr, from)
+}
+
+// syncServerGame copies a game version and the shared files to a stopped server
+func syncServerGame(ctx context.Context, server int, version string) error {
+	if err := CopyVersionToServer(ctx, server, version); err != nil {
+		return fmt.Errorf("failed to copy game files: %w", err)
+	}
+	if err := CopySharedToServer(ctx, server); err != nil {
+		return fmt.Errorf("failed to copy shared configs: %w", err)
+	}
+	manifest := LoadManifestOrDefault()
+	if os.Geteuid() == 0 && SystemUserExists(manifest.HytaleUser) {
+		return ApplyServerOwnership(manifest.HytaleUser, server)
+	}
+	return nil
+}
+
+// startHealthy starts a server and waits until it logs the started line and its port is bound
+func (tm *TmuxManager) startHealthy(ctx context.Context, server int, settings LaunchSettings, pattern *regexp.Regexp, timeout time.Duration) error {
+	// Snapshot the console so only lines printed by this run count
+	var previous []string
+	if output, err := tm.Logs(server, commandCaptureLines); err == nil {
+		previous = SplitLogLines(output)
+	}
+	if err := tm.StartServer(server, settings); err != nil {
+		return fmt.Errorf("failed to start: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	port := tm.basePort + (server - 1)
+	logged, bound := false, false
+	for {
+		if !tm.HasSession(server) {
+			return fmt.Errorf("server exited while starting")
+		}
+		if !logged {
+			if output, err := tm.Logs(server, commandCaptureLines); err == nil {
+				current := SplitLogLines(output)
+				for _, line := range NewLogLines(previous, current) {
+					if pattern.MatchString(line) {
+						logged = true
+						break
+					}
+				}
+				previous = current
+			}
+		}
+		if !bound {
+			bound = portBound(port)
+		}
+		if logged && bound {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			var missing []string
+			if !logged {
+				missing = append(missing, "no started line on the console")
+			}
+			if !bound {
+				missing = append(missing, fmt.Sprintf("port %d not bound", port))
+			}
+			return fmt.Errorf("not healthy after %s: %s", timeout, strings.Join(missing, ", "))
+		case <-time.After(healthPollInterval):
+		}
+	}
+}
+
+// portBound reports whether a UDP or TCP socket is bound to a local port
+// Where /proc/net isn't available the check can't be made and counts as passed
+func portBound(port int) bool {
+	checked := false
+	for _, name := range []string{"udp", "udp6", "tcp", "tcp6"} {
+		f, err := os.Open("/proc/net/" + name)
+		if err != nil {
+			continue
+		}
+		checked = true
+		scanner := bufio.NewScanner(f)
+		scanner.Scan() // Header
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 2 {
+				continue
+			}
+			// local_address is hex IP:port, e.g. 00000000:1590
+			colon := strings.LastIndex(fields[1], ":")
+			if colon < 0 {
+				continue
+			}
+			if p, err := strconv.ParseUint(fields[1][colon+1:], 16, 16); err == nil && int(p) == port {
+				f.Close()
+				return true
+			}
+		}
+		f.Close()
+	}
+	return !checked
+}
@@ -0,0 +1,532 @@
+package hytale
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scheduled job actions
+const (
+	JobRestart       = "restart"        // Restart the running servers in the set
+	JobSnapshot      = "snapshot"       // Take HSM snapshots, replicate them and apply retention
+	JobUpdateGame    = "update-game"    // UpdateGame, or RollingUpdateGame with rolling (all servers)
+	JobUpdatePlugins = "update-plugins" // UpdatePlugins (all servers)
+	JobCommand       = "command"        // Send a console command to the running servers in the set
+)
+
+// Job run outcomes
+const (
+	JobStatusOK      = "ok"
+	JobStatusFailed  = "failed"
+	JobStatusSkipped = "skipped" // The previous run was still going
+	JobStatusMissed  = "missed"  // Due while the daemon was down and catch-up is off
+)
+
+const (
+	// SchedulerStateFile holds the scheduler's last/next runs and job history (in DataDirBase,
+	// not shared/, which is copied into every server)
+	SchedulerStateFile = ".hsm-scheduler.json"
+
+	// maxJobHistory is how many job runs the scheduler state keeps
+	maxJobHistory = 100
+
+	// maxJobOutput is how much of a job's output is kept in its history entry
+	maxJobOutput = 2000
+
+	// jobLateAfter is how overdue a job must be to count as missed rather than just due
+	jobLateAfter = 2 * time.Minute
+)
+
+// JobActions lists the actions a scheduled job can run
+var JobActions = []string{JobRestart, JobSnapshot, JobUpdateGame, JobUpdatePlugins, JobCommand}
+
+// ScheduledJob is a job in shared/schedules.json
+type ScheduledJob struct {
+	Name      string `json:"name"`
+	Schedule  string `json:"schedule"` // Cron expression, e.g. "0 4 * * *"
+	Action    string `json:"action"`
+	Servers   string `json:"servers,omitempty"` // "all" (default), "2" or "1,3-4"
+	Command   string `json:"command,omitempty"` // Console command for the command action
+	NoCatchUp bool   `json:"no_catch_up,omitempty"`
+	Disabled  bool   `json:"disabled,omitempty"`
+
+	// Restart and rolling update-game only: warn players with a countdown first, e.g. "15m,5m,1m,30s,10s"
+	Warnings       string `json:"warnings,omitempty"`
+	WarningCommand string `json:"warning_command,omitempty"` // Defaults to DefaultRestartWarningCommand
+	Rolling        bool   `json:"rolling,omitempty"`         // Restart (or update and health check) the servers one after another
+}
+
+// ScheduleConfig is the contents of shared/schedules.json
+type ScheduleConfig struct {
+	Jobs []ScheduledJob `json:"jobs"`
+}
+
+// JobState is what the scheduler remembers about a job
+type JobState struct {
+	Schedule   string    `json:"schedule"` // Expression NextRun was computed from
+	NextRun    time.Time `json:"next_run"`
+	LastRun    time.Time `json:"last_run,omitempty"`
+	LastStatus string    `json:"last_status,omitempty"`
+	Running    bool      `json:"running,omitempty"`
+}
+
+// JobRun is an entry in the job history
+type JobRun struct {
+	Job      string    `json:"job"`
+	Action   string    `json:"action"`
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+	Status   string    `json:"status"`
+	Output   string    `json:"output,omitempty"`
+	CatchUp  bool      `json:"catch_up,omitempty"`
+}
+
+// SchedulerState is the scheduler's persisted state
+type SchedulerState struct {
+	Jobs    map[string]*JobState `json:"jobs"`
+	History []JobRun             `json:"history"` // Newest last
+}
+
+// schedulerStateMu serialises read-modify-write cycles of the scheduler state
+var schedulerStateMu sync.Mutex
+
+// GetSchedulesPath returns the path to the shared schedule config
+func GetSchedulesPath() string {
+	return filepath.Join(GetSharedConfigDir(), "schedules.json")
+}
+
+// GetSchedulerStatePath returns the path to the scheduler state
+func GetSchedulerStatePath() string {
+	return filepath.Join(DataDirBase, SchedulerStateFile)
+}
+
+// ReadSchedules reads and validates shared/schedules.json (no jobs if it doesn't exist)
+func ReadSchedules() (*ScheduleConfig, error) {
+	config := &ScheduleConfig{}
+	data, err := os.ReadFile(GetSchedulesPath())
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schedules: %w", err)
+	}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse schedules: %w", err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Validate checks every job for a unique name, a valid schedule, action and server set
+func (c *ScheduleConfig) Validate() error {
+	seen := make(map[string]bool)
+	for i, job := range c.Jobs {
+		if job.Name == "" {
+			return fmt.Errorf("schedule job %d has no name", i+1)
+		}
+		if seen[job.Name] {
+			return fmt.Errorf("schedule job %q is defined twice", job.Name)
+		}
+		seen[job.Name] = true
+		if _, err := ParseCron(job.Schedule); err != nil {
+			return fmt.Errorf("schedule job %q: %w", job.Name, err)
+		}
+		if !isJobAction(job.Action) {
+			return fmt.Errorf("schedule job %q: unknown action %q (expected %s)", job.Name, job.Action, strings.Join(JobActions, ", "))
+		}
+		if job.Action == JobCommand && strings.TrimSpace(job.Command) == "" {
+			return fmt.Errorf("schedule job %q: the command action needs a command", job.Name)
+		}
+		if _, err := ParseServerSet(job.Servers, 0); err != nil {
+			return fmt.Errorf("schedule job %q: %w", job.Name, err)
+		}
+		if job.Warnings != "" {
+			if _, err := ParseRestartWarnings(job.Warnings); err != nil {
+				return fmt.Errorf("schedule job %q: %w", job.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Job returns the job with the given name
+func (c *ScheduleConfig) Job(name string) (*ScheduledJob, error) {
+	for i := range c.Jobs {
+		if c.Jobs[i].Name == name {
+			return &c.Jobs[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no schedule job named %q in %s", name, GetSchedulesPath())
+}
+
+func isJobAction(action string) bool {
+	for _, a := range JobActions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseServerSet parses a server set: "all" or "" for every server, or numbers and ranges like "1,3-4"
+// numServers limits the set to installed servers (0 skips the check)
+func ParseServerSet(spec string, numServers int) ([]int, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" || strings.EqualFold(spec, "all") {
+		servers := make([]int, numServers)
+		for i := range servers {
+			servers[i] = i + 1
+		}
+		return servers, nil
+	}
+
+	seen := make(map[int]bool)
+	var servers []int
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		lo, hi := part, part
+		if i := strings.Index(part, "-"); i > 0 {
+			lo, hi = part[:i], part[i+1:]
+		}
+		first, err1 := strconv.Atoi(lo)
+		last, err2 := strconv.Atoi(hi)
+		if err1 != nil || err2 != nil || first < 1 || last < first {
+			return nil, fmt.Errorf("invalid server set %q", spec)
+		}
+		for server := first; server <= last; server++ {
+			if numServers > 0 && server > numServers {
+				return nil, fmt.Errorf("server %d does not exist (%d server(s) installed)", server, numServers)
+			}
+			if !seen[server] {
+				seen[server] = true
+				servers = append(servers, server)
+			}
+		}
+	}
+	sort.Ints(servers)
+	return servers, nil
+}
+
+// LoadSchedulerState reads the scheduler state (empty state if there is none)
+func LoadSchedulerState() *SchedulerState {
+	state := &SchedulerState{}
+	if data, err := os.ReadFile(GetSchedulerStatePath()); err == nil {
+		_ = json.Unmarshal(data, state)
+	}
+	if state.Jobs == nil {
+		state.Jobs = make(map[string]*JobState)
+	}
+	return state
+}
+
+// saveSchedulerState writes the scheduler state
+func saveSchedulerState(state *SchedulerState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal scheduler state: %w", err)
+	}
+	path := GetSchedulerStatePath()
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write scheduler state: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write scheduler state: %w", err)
+	}
+	return nil
+}
+
+// RecordJobRun adds a run to the job history and updates the job's last run
+func RecordJobRun(run JobRun) error {
+	schedulerStateMu.Lock()
+	defer schedulerStateMu.Unlock()
+
+	if len(run.Output) > maxJobOutput {
+		run.Output = run.Output[:maxJobOutput] + "..."
+	}
+	state := LoadSchedulerState()
+	if js, ok := state.Jobs[run.Job]; ok {
+		js.LastRun = run.Started
+		js.LastStatus = run.Status
+		if run.Status != JobStatusSkipped {
+			js.Running = false
+		}
+	}
+	state.History = append(state.History, run)
+	if len(state.History) > maxJobHistory {
+		state.History = state.History[len(state.History)-maxJobHistory:]
+	}
+	return saveSchedulerState(state)
+}
+
+// RunScheduledJob runs a job's action now and returns a summary of what it did
+func RunScheduledJob(ctx context.Context, job ScheduledJob) (string, error) {
+	manifest := LoadManifestOrDefault()
+	tm := NewTmuxManagerFromManifest(manifest)
+	servers, err := ParseServerSet(job.Servers, DetectNumServers())
+	if err != nil {
+		return "", err
+	}
+
+	switch job.Action {
+	case JobRestart:
+		// A stopped server was stopped on purpose; a scheduled restart shouldn't start it
+		var running []int
+		for _, server := range servers {
+			if tm.HasSession(server) {
+				running = append(running, server)
+			}
+		}
+		if len(running) == 0 {
+			return "No servers running, nothing to restart", nil
+		}
+		if job.Warnings != "" {
+			opts := DefaultWarnedRestartOptions()
+			opts.Warnings, _ = ParseRestartWarnings(job.Warnings) // Validated by ReadSchedules
+			opts.Rolling = job.Rolling
+			if job.WarningCommand != "" {
+				opts.Command = job.WarningCommand
+			}
+			return summarizeJobResults(tm.WarnedRestartServers(ctx, running, opts))
+		}
+		settings := LoadLaunchSettings(manifest)
+		return summarizeJobResults(RunOnServers(running, func(server int) (string, error) {
+			return "restarted", tm.RestartServer(server, settings)
+		}))
+
+	case JobSnapshot:
+		var results []ServerActionResult
+		for _, server := range servers {
+			snapshot, err := tm.CreateSnapshot(server)
+			if err != nil {
+				results = append(results, ServerActionResult{Server: server, Err: err})
+				continue
+			}
+			detail := snapshot.Name
+			lines, err := ReplicateToTargets(ctx, snapshot.Path, SnapshotRemoteName(snapshot))
+			if len(lines) > 0 {
+				detail += " (" + strings.Join(lines, "; ") + ")"
+			}
+			results = append(results, ServerActionResult{Server: server, Detail: detail, Err: err})
+		}
+		output, err := summarizeJobResults(results)
+		pruned, pruneErr := PruneBackups(ctx, servers, false)
+		for _, r := range pruned {
+			if len(r.Removed) > 0 || r.Err != "" {
+				output += "\n" + r.String()
+			}
+		}
+		if err == nil {
+			err = pruneErr
+		}
+		return output, err
+
+	case JobUpdateGame:
+		if !job.Rolling {
+			return UpdateGame(ctx)
+		}
+		opts := DefaultRollingUpdateOptions(manifest)
+		if job.Warnings != "" {
+			opts.Warn = true
+			opts.Restart.Warnings, _ = ParseRestartWarnings(job.Warnings) // Validated by ReadSchedules
+			if job.WarningCommand != "" {
+				opts.Restart.Command = job.WarningCommand
+			}
+		}
+		version, results, err := tm.RollingUpdateGame(ctx, opts)
+		if err != nil {
+			return "", err
+		}
+		output, err := summarizeJobResults(results)
+		return fmt.Sprintf("Game version %s\n%s", version, output), err
+
+	case JobUpdatePlugins:
+		return UpdatePlugins(ctx)
+
+	case JobCommand:
+		return summarizeJobResults(RunOnServers(servers, func(server int) (string, error) {
+			if !tm.HasSession(server) {
+				return "not running, skipped", nil
+			}
+			lines, err := tm.SendCommand(server, job.Command)
+			if len(lines) == 0 {
+				return "sent " + job.Command, err
+			}
+			return strings.Join(lines, " | "), err
+		}))
+
+	default:
+		return "", fmt.Errorf("unknown action %q", job.Action)
+	}
+}
+
+// summarizeJobResults turns per-server results into job output, failing if any server failed
+func summarizeJobResults(results []ServerActionResult) (string, error) {
+	var lines []string
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			lines = append(lines, fmt.Sprintf("Server %d: %v", r.Server, r.Err))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("Server %d: %s", r.Server, r.Detail))
+	}
+	if failed > 0 {
+		return strings.Join(lines, "\n"), fmt.Errorf("%d of %d server(s) failed", failed, len(results))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// Scheduler runs the jobs in shared/schedules.json from the daemon
+// A job that is still running when it comes due again is skipped; a job that came due while
+// the daemon was down runs once when it starts again, unless the job sets no_catch_up
+type Scheduler struct {
+	// Logf receives the scheduler's activity log
+	Logf func(format string, args ...interface{})
+
+	mu        sync.Mutex
+	running   map[string]bool
+	wg        sync.WaitGroup
+	lastError string
+}
+
+// NewScheduler creates a scheduler
+func NewScheduler() *Scheduler {
+	return &Scheduler{
+		Logf:    func(string, ...interface{}) {},
+		running: make(map[string]bool),
+	}
+}
+
+// Check starts the jobs that are due at now
+func (s *Scheduler) Check(ctx context.Context, now time.Time) {
+	config, err := ReadSchedules()
+	if err != nil {
+		// Log a broken schedules.json once, not on every pass
+		if err.Error() != s.lastError {
+			s.Logf("Scheduler: %v", err)
+			s.lastError = err.Error()
+		}
+		return
+	}
+	s.lastError = ""
+
+	schedulerStateMu.Lock()
+	defer schedulerStateMu.Unlock()
+	state := LoadSchedulerState()
+
+	active := make(map[string]bool)
+	for _, job := range config.Jobs {
+		if job.Disabled {
+			continue
+		}
+		active[job.Name] = true
+		schedule, _ := ParseCron(job.Schedule) // Validated by ReadSchedules
+
+		js, ok := state.Jobs[job.Name]
+		if !ok {
+			js = &JobState{}
+			state.Jobs[job.Name] = js
+		}
+		s.mu.Lock()
+		js.Running = s.running[job.Name]
+		s.mu.Unlock()
+
+		// New job or changed schedule: start counting from now
+		if js.Schedule != job.Schedule || js.NextRun.IsZero() {
+			js.Schedule = job.Schedule
+			js.NextRun = schedule.Next(now)
+			continue
+		}
+		if now.Before(js.NextRun) {
+			continue
+		}
+
+		late := now.Sub(js.NextRun) > jobLateAfter
+		due := js.NextRun
+		js.NextRun = schedule.Next(now)
+		run := JobRun{Job: job.Name, Action: job.Action, Started: now, Finished: now, CatchUp: late}
+		switch {
+		case js.Running:
+			run.Status = JobStatusSkipped
+			run.Output = "previous run still in progress"
+			s.Logf("Scheduler: skipping %s, the previous run is still in progress", job.Name)
+			state.History = append(state.History, run)
+		case late && job.NoCatchUp:
+			run.Status = JobStatusMissed
+			run.Output = fmt.Sprintf("was due at %s while the daemon was down", due.Format("2006-01-02 15:04"))
+			s.Logf("Scheduler: %s was due at %s, not catching up", job.Name, due.Format("2006-01-02 15:04"))
+			js.LastRun, js.LastStatus = now, JobStatusMissed
+			state.History = append(state.History, run)
+		default:
+			js.Running = true
+			s.start(ctx, job, run, due)
+		}
+	}
+
+	// Forget jobs that were removed from schedules.json
+	for name := range state.Jobs {
+		if !active[name] {
+			delete(state.Jobs, name)
+		}
+	}
+	if len(state.History) > maxJobHistory {
+		state.History = state.History[len(state.History)-maxJobHistory:]
+	}
+	if err := saveSchedulerState(state); err != nil {
+		s.Logf("Scheduler: %v", err)
+	}
+}
+
+// start runs a job in the background and records the run when it finishes
+func (s *Scheduler) start(ctx context.Context, job ScheduledJob, run JobRun, due time.Time) {
+	s.mu.Lock()
+	s.running[job.Name] = true
+	s.mu.Unlock()
+
+	if run.CatchUp {
+		s.Logf("Scheduler: running %s (%s), catching up the run due at %s", job.Name, job.Action, due.Format("2006-01-02 15:04"))
+	} else {
+		s.Logf("Scheduler: running %s (%s)", job.Name, job.Action)
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		output, err := RunScheduledJob(ctx, job)
+		run.Finished = time.Now()
+		run.Status = JobStatusOK
+		run.Output = output
+		if err != nil {
+			run.Status = JobStatusFailed
+			run.Output = strings.TrimSpace(output + "\n" + err.Error())
+			s.Logf("Scheduler: %s failed after %s: %v", job.Name, run.Finished.Sub(run.Started).Round(time.Second), err)
+		} else {
+			s.Logf("Scheduler: %s finished in %s", job.Name, run.Finished.Sub(run.Started).Round(time.Second))
+		}
+
+		s.mu.Lock()
+		delete(s.running, job.Name)
+		s.mu.Unlock()
+		if err := RecordJobRun(run); err != nil {
+			s.Logf("Scheduler: %v", err)
+		}
+	}()
+}
+
+// Wait waits for running jobs to finish
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
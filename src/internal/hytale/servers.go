@@ -8,8 +8,9 @@ import (
 )
 
 // AddServerInstanceWithContext adds a new server instance
+// Port and hostname are derived from the installation manifest
 // Enforces MaxServersPerLicense limit per Hytale Server Manual
-func AddServerInstanceWithContext(ctx context.Context, numServers int, basePort int) error {
+func AddServerInstanceWithContext(ctx context.Context, numServers int) error {
 	newServerNum := numServers + 1
 	
 	// Enforce server limit per Hytale Server Manual
@@ -40,27 +41,31 @@ func AddServerInstanceWithContext(ctx context.Context, numServers int, basePort
 	}
 
 	// Create server-specific config.json
-	// Read config from server-1 to get defaults, or use defaults
-	port := basePort + (newServerNum - 1)
-	hostname := fmt.Sprintf("%s-%d", DefaultHostnamePrefix, newServerNum)
-	
-	// Try to get defaults from server-1
-	maxPlayers := DefaultMaxPlayers
-	maxViewRadius := DefaultMaxViewRadius
-	gameMode := DefaultGameMode
-	serverPassword := ""
-	if config, err := ReadConfig(GetServerConfigPath(1)); err == nil {
-		maxPlayers = config.MaxPlayers
-		maxViewRadius = config.MaxViewRadius
-		if config.Defaults.GameMode != "" {
-			gameMode = config.Defaults.GameMode
-		}
-		serverPassword = config.Password
+	// Fleet-wide values come from shared/config.defaults.json, not from another server,
+	// so changes made to one server for testing don't spread to new ones
+	manifest, err := LoadManifest()
+	if err != nil {
+		return fmt.Errorf("failed to load installation manifest: %w", err)
 	}
+	port := manifest.BasePort + (newServerNum - 1)
+	hostname := fmt.Sprintf("%s-%d", manifest.HostnamePrefix, newServerNum)
 
-	if err := UpdateServerConfig(newServerNum, port, hostname, maxPlayers, maxViewRadius, gameMode, serverPassword); err != nil {
+	if err := UpdateServerConfig(newServerNum, port, hostname, DefaultMaxPlayers, DefaultMaxViewRadius, DefaultGameMode, ""); err != nil {
 		return fmt.Errorf("failed to create config: %w", err)
 	}
+	if _, err := RenderServerConfig(newServerNum); err != nil {
+		return fmt.Errorf("failed to apply config defaults: %w", err)
+	}
+	if err := RecordServerGameVersions([]int{newServerNum}, ActivePatchlineVersion(ServerPatchline(manifest, newServerNum))); err != nil {
+		return fmt.Errorf("failed to record game version: %w", err)
+	}
+
+	// Hand the new server directory to the system user
+	if SystemUserExists(manifest.HytaleUser) {
+		if err := ApplyServerOwnership(manifest.HytaleUser, newServerNum); err != nil {
+			return fmt.Errorf("failed to set ownership: %w", err)
+		}
+	}
 
 	return nil
 }
@@ -75,10 +80,11 @@ func RemoveLastServerInstance(numServers int) error {
 	dataDir := fmt.Sprintf("%s/server-%d", DataDirBase, lastServer)
 
 	// Stop server if running
-	tm := NewTmuxManager(DefaultBasePort)
+	tm := NewTmuxManagerFromManifest(LoadManifestOrDefault())
 	if tm.HasSession(lastServer) {
-		_ = tm.Stop(lastServer) // Continue even if stop fails
+		_, _ = tm.Stop(lastServer) // Continue even if stop fails
 	}
+	_ = tm.Remove(lastServer) // Clean up backend state (e.g. systemd unit instance)
 
 	// Remove directory
 	if err := os.RemoveAll(dataDir); err != nil {
@@ -109,7 +115,11 @@ func ServerExists(serverNum int) bool {
 }
 
 // GetServerJarPath returns the path to the server JAR for a server instance
+// Each server runs its own copy (servers can be on different game versions); master-install is the fallback
 func GetServerJarPath(serverNum int) string {
-	// Servers share the same JAR from master-install or server-1
+	serverJar := filepath.Join(GetServerDir(serverNum), "Server", "HytaleServer.jar")
+	if fileExists(serverJar) {
+		return serverJar
+	}
 	return filepath.Join(DataDirBase, "master-install", "Server", "HytaleServer.jar")
 }
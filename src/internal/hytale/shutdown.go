@@ -0,0 +1,331 @@
+package hytale
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// DefaultStopTimeoutSeconds is how long a server gets to exit after /stop before SIGTERM
+	DefaultStopTimeoutSeconds = 60
+
+	// DefaultSaveCompletePattern matches the console line logged once the world save finishes
+	DefaultSaveCompletePattern = `(?i)(saved|save complete|finished saving)`
+
+	// stopTermTimeout is how long a server gets to exit after SIGTERM before SIGKILL
+	stopTermTimeout = 20 * time.Second
+
+	// stopKillTimeout is how long to wait for the process to disappear after SIGKILL
+	stopKillTimeout = 5 * time.Second
+
+	// stopPollInterval is how often the process and console are checked while stopping
+	stopPollInterval = 500 * time.Millisecond
+)
+
+// StopMethod describes how a server was brought down
+type StopMethod string
+
+const (
+	// StopGraceful means the server exited on its own after /stop
+	StopGraceful StopMethod = "graceful"
+	// StopSigterm means the server had to be sent SIGTERM
+	StopSigterm StopMethod = "sigterm"
+	// StopSigkill means the server had to be killed with SIGKILL
+	StopSigkill StopMethod = "sigkill"
+	// StopSession means no server PID was found, so only the session was torn down
+	StopSession StopMethod = "session"
+)
+
+// StopResult reports how a server was stopped
+type StopResult struct {
+	Server        int           `json:"server"`
+	Method        StopMethod    `json:"method"`
+	SaveConfirmed bool          `json:"save_confirmed"` // The save-complete line was seen on the console
+	Duration      time.Duration `json:"duration"`
+	Error         string        `json:"error,omitempty"` // Set by StopAll when this server failed to stop
+}
+
+// String returns a short human-readable summary, e.g. "graceful, save confirmed, 12s"
+func (r StopResult) String() string {
+	parts := []string{string(r.Method)}
+	if r.SaveConfirmed {
+		parts = append(parts, "save confirmed")
+	} else {
+		parts = append(parts, "save not confirmed")
+	}
+	parts = append(parts, r.Duration.Round(time.Second).String())
+	return strings.Join(parts, ", ")
+}
+
+// Stop gracefully stops a server, escalating to SIGTERM and SIGKILL only when needed
+func (tm *TmuxManager) Stop(server int) (StopResult, error) {
+	return tm.StopWithContext(context.Background(), server)
+}
+
+// StopWithContext sends /stop, waits for the JVM to exit (watching the console for the
+// save-complete line) and escalates through SIGTERM and SIGKILL if the stop timeout passes
+// Cancelling ctx skips the remaining grace period and escalates immediately
+func (tm *TmuxManager) StopWithContext(ctx context.Context, server int) (result StopResult, err error) {
+	result.Server = server
+	started := time.Now()
+	// result is a named return, so the duration lands in the value the caller gets
+	defer func() { result.Duration = time.Since(started) }()
+
+	if !tm.HasSession(server) {
+		return result, fmt.Errorf("session %s does not exist: %w", tm.SessionName(server), ErrNotRunning)
+	}
+
+	// Tell the supervisor this exit is intentional
+	markStopIntent(server)
+
+	pid, err := tm.backend.MainPID(server)
+	if err != nil || pid <= 0 {
+		// Can't watch the JVM - fall back to asking the server to stop and tearing down the session
+		_ = tm.backend.SendCommand(server, "/stop")
+		time.Sleep(2 * time.Second)
+		result.Method = StopSession
+		return result, tm.backend.Stop(server)
+	}
+
+	savePattern, err := regexp.Compile(tm.savePattern)
+	if err != nil {
+		savePattern = regexp.MustCompile(DefaultSaveCompletePattern)
+	}
+
+	// Snapshot the console so only save lines printed after /stop count
+	var previous []string
+	if output, err := tm.backend.Logs(server, 200); err == nil {
+		previous = SplitLogLines(output)
+	}
+
+	if err := tm.backend.SendCommand(server, "/stop"); err != nil {
+		return result, fmt.Errorf("failed to send /stop: %w", err)
+	}
+
+	// Phase 1: wait for the JVM to exit on its own
+	graceCtx, cancel := context.WithTimeout(ctx, tm.stopTimeout)
+	exited := waitForExit(graceCtx, pid, func() {
+		if result.SaveConfirmed {
+			return
+		}
+		output, err := tm.backend.Logs(server, 200)
+		if err != nil {
+			return
+		}
+		current := SplitLogLines(output)
+		for _, line := range NewLogLines(previous, current) {
+			if savePattern.MatchString(line) {
+				result.SaveConfirmed = true
+				break
+			}
+		}
+		previous = current
+	})
+	cancel()
+	result.Method = StopGraceful
+
+	// Phase 2: SIGTERM lets the JVM run its shutdown hooks
+	if !exited {
+		result.Method = StopSigterm
+		_ = syscall.Kill(pid, syscall.SIGTERM)
+		termCtx, cancel := context.WithTimeout(context.Background(), stopTermTimeout)
+		exited = waitForExit(termCtx, pid, nil)
+		cancel()
+	}
+
+	// Phase 3: SIGKILL as a last resort
+	if !exited {
+		result.Method = StopSigkill
+		_ = syscall.Kill(pid, syscall.SIGKILL)
+		killCtx, cancel := context.WithTimeout(context.Background(), stopKillTimeout)
+		exited = waitForExit(killCtx, pid, nil)
+		cancel()
+	}
+
+	// Tear down whatever the backend left behind (tmux session, systemd unit)
+	if err := tm.backend.Stop(server); err != nil {
+		return result, err
+	}
+	if !exited {
+		return result, fmt.Errorf("server %d (pid %d) did not exit after SIGKILL", server, pid)
+	}
+	return result, nil
+}
+
+// StopAll stops all running servers in parallel
+// The whole operation is bounded by an overall deadline after which remaining servers are escalated
+func (tm *TmuxManager) StopAll(numServers int) ([]StopResult, error) {
+	return tm.StopAllWithContext(context.Background(), numServers)
+}
+
+// StopAllWithContext stops all running servers in parallel within the overall stop deadline
+func (tm *TmuxManager) StopAllWithContext(ctx context.Context, numServers int) ([]StopResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, tm.stopTimeout+stopTermTimeout+stopKillTimeout)
+	defer cancel()
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results []StopResult
+		errs    []string
+	)
+	for i := 1; i <= numServers; i++ {
+		if !tm.HasSession(i) {
+			continue
+		}
+		wg.Add(1)
+		go func(server int) {
+			defer wg.Done()
+			result, err := tm.StopWithContext(ctx, server)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				result.Error = err.Error()
+				errs = append(errs, fmt.Sprintf("server %d: %v", server, err))
+			}
+			results = append(results, result)
+		}(i)
+	}
+	wg.Wait()
+
+	// Report in server order regardless of which finished first
+	for i := 1; i < len(results); i++ {
+		for j := i; j > 0 && results[j].Server < results[j-1].Server; j-- {
+			results[j], results[j-1] = results[j-1], results[j]
+		}
+	}
+
+	if len(errs) > 0 {
+		return results, fmt.Errorf("failed to stop %d server(s): %s", len(errs), strings.Join(errs, "; "))
+	}
+	return results, nil
+}
+
+// waitForExit polls until pid has exited or ctx is done, calling onTick on every poll
+// Returns true if the process exited
+func waitForExit(ctx context.Context, pid int, onTick func()) bool {
+	ticker := time.NewTicker(stopPollInterval)
+	defer ticker.Stop()
+	for {
+		if !processAlive(pid) {
+			return true
+		}
+		if onTick != nil {
+			onTick()
+		}
+		select {
+		case <-ctx.Done():
+			return !processAlive(pid)
+		case <-ticker.C:
+		}
+	}
+}
+
+// processAlive checks if a process exists and isn't a zombie
+func processAlive(pid int) bool {
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return false
+	}
+	// Format: pid (comm) state ... - comm may contain spaces, so split after the last ')'
+	stat := string(data)
+	if idx := strings.LastIndex(stat, ")"); idx >= 0 && idx+2 < len(stat) {
+		return stat[idx+2] != 'Z'
+	}
+	return true
+}
+
+// processParent returns the parent PID of a process (0 if unknown)
+func processParent(pid int) int {
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return 0
+	}
+	stat := string(data)
+	idx := strings.LastIndex(stat, ")")
+	if idx < 0 {
+		return 0
+	}
+	fields := strings.Fields(stat[idx+1:])
+	if len(fields) < 2 {
+		return 0
+	}
+	ppid, _ := strconv.Atoi(fields[1])
+	return ppid
+}
+
+// processName returns a process's command name from /proc/<pid>/comm
+func processName(pid int) string {
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "comm"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// findJavaPID returns root if it is the JVM, otherwise the first java process descended from root
+// (e.g. when the server runs under a shell wrapper); 0 if none is found
+func findJavaPID(root int) int {
+	if processName(root) == "java" {
+		return root
+	}
+
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return 0
+	}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || processName(pid) != "java" {
+			continue
+		}
+		// Walk up the parent chain looking for root
+		for p := processParent(pid); p > 1; p = processParent(p) {
+			if p == root {
+				return pid
+			}
+		}
+	}
+	return 0
+}
+
+// SplitLogLines splits console output into lines, dropping trailing blank lines
+// (tmux pads the bottom of the pane with them)
+func SplitLogLines(output string) []string {
+	lines := strings.Split(output, "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// NewLogLines returns the lines in current that were not in previous
+// Both are windows over the same console, so current is previous shifted by
+// some number of lines with new output appended
+func NewLogLines(previous, current []string) []string {
+	for shift := 0; shift <= len(previous); shift++ {
+		overlap := previous[shift:]
+		if len(overlap) > len(current) {
+			continue
+		}
+		matches := true
+		for i := range overlap {
+			if overlap[i] != current[i] {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return current[len(overlap):]
+		}
+	}
+	return current
+}
@@ -0,0 +1,535 @@
+package hytale
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SnapshotManifestName is the name of the manifest stored at the end of every snapshot archive
+// (and next to it as <snapshot>.json so listing doesn't have to read the archive)
+const SnapshotManifestName = "hsm-snapshot.json"
+
+// snapshotVersion is the manifest format version written by this HSM
+const snapshotVersion = 1
+
+// snapshotPaths are the parts of a server directory that go into a snapshot
+var snapshotPaths = []string{"universe", "config.json", "mods"}
+
+// SnapshotFile is one file in a snapshot with its checksum
+type SnapshotFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// SnapshotManifest describes the contents of a snapshot archive
+type SnapshotManifest struct {
+	Version       int            `json:"version"`
+	Server        int            `json:"server"`
+	Created       time.Time      `json:"created"`
+	HSMVersion    string         `json:"hsm_version"`
+	WasRunning    bool           `json:"was_running"`
+	SaveConfirmed bool           `json:"save_confirmed"` // The save command's completion line was seen before the snapshot
+	Files         []SnapshotFile `json:"files"`
+}
+
+// Snapshot is an HSM snapshot of a server's universe, config.json and mods
+type Snapshot struct {
+	Name          string    `json:"name"`
+	Path          string    `json:"path"`
+	Server        int       `json:"server"`
+	Created       time.Time `json:"created"`
+	Size          int64     `json:"size"`
+	Files         int       `json:"files"`
+	Worlds        []string  `json:"worlds,omitempty"`
+	SaveConfirmed bool      `json:"save_confirmed"`
+}
+
+// SnapshotRestoreOptions selects what a restore replaces
+type SnapshotRestoreOptions struct {
+	UniverseOnly bool // Keep the target's config.json and mods (e.g. when cloning a world to another shard)
+	NoRestart    bool // Leave the server stopped after the restore
+}
+
+// GetSnapshotDir returns the directory HSM snapshots are stored in
+func GetSnapshotDir() string {
+	return filepath.Join(DataDirBase, "snapshots")
+}
+
+// Worlds returns the world names contained in a snapshot
+func (sm *SnapshotManifest) Worlds() []string {
+	seen := make(map[string]bool)
+	var worlds []string
+	for _, f := range sm.Files {
+		parts := strings.Split(f.Path, "/")
+		if len(parts) >= 3 && parts[0] == "universe" && parts[1] == "worlds" && !seen[parts[2]] {
+			seen[parts[2]] = true
+			worlds = append(worlds, parts[2])
+		}
+	}
+	sort.Strings(worlds)
+	return worlds
+}
+
+// holdAndSave runs the configured save sequence on a running server before a snapshot
+// Returns whether the save was confirmed on the console and a function that resumes saving
+func (tm *TmuxManager) holdAndSave(server int, config *BackupConfig) (bool, func()) {
+	release := func() {}
+	if !tm.HasSession(server) {
+		return false, release
+	}
+
+	if config.SnapshotHoldCommand != "" {
+		_, _ = tm.SendCommand(server, config.SnapshotHoldCommand)
+		if config.SnapshotReleaseCommand != "" {
+			release = func() { _, _ = tm.SendCommand(server, config.SnapshotReleaseCommand) }
+		}
+	}
+	return tm.saveWorld(server, config), release
+}
+
+// saveWorld sends the configured save command and waits for the console to confirm the save
+func (tm *TmuxManager) saveWorld(server int, config *BackupConfig) bool {
+	if config.SnapshotSaveCommand == "" {
+		return false
+	}
+
+	savePattern, err := regexp.Compile(tm.savePattern)
+	if err != nil {
+		savePattern = regexp.MustCompile(DefaultSaveCompletePattern)
+	}
+
+	// Snapshot the console so only save lines printed after the command count
+	var previous []string
+	if output, err := tm.Logs(server, commandCaptureLines); err == nil {
+		previous = SplitLogLines(output)
+	}
+	if err := tm.backend.SendCommand(server, config.SnapshotSaveCommand); err != nil {
+		return false
+	}
+
+	timeout := time.Duration(config.SnapshotSaveTimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = DefaultSnapshotSaveTimeoutSeconds * time.Second
+	}
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(commandPollInterval)
+		output, err := tm.Logs(server, commandCaptureLines)
+		if err != nil {
+			continue
+		}
+		current := SplitLogLines(output)
+		for _, line := range NewLogLines(previous, current) {
+			if savePattern.MatchString(line) {
+				return true
+			}
+		}
+		previous = current
+	}
+	return false
+}
+
+// CreateSnapshot writes a compressed snapshot of a server's universe, config.json and mods
+// A running server is asked to save first (and to hold saving while the files are read, if configured)
+func (tm *TmuxManager) CreateSnapshot(server int) (*Snapshot, error) {
+	serverDir := GetServerDir(server)
+	if _, err := os.Stat(serverDir); err != nil {
+		return nil, fmt.Errorf("server %d does not exist", server)
+	}
+	config, err := ReadBackupConfig()
+	if err != nil {
+		config = DefaultBackupConfig()
+	}
+
+	if err := os.MkdirAll(GetSnapshotDir(), 0750); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	manifest := SnapshotManifest{
+		Version:    snapshotVersion,
+		Server:     server,
+		Created:    time.Now(),
+		HSMVersion: GetShortVersion(),
+		WasRunning: tm.HasSession(server),
+	}
+	confirmed, release := tm.holdAndSave(server, config)
+	defer release()
+	manifest.SaveConfirmed = confirmed
+
+	stamp := manifest.Created.Format("20060102-150405")
+	path := filepath.Join(GetSnapshotDir(), fmt.Sprintf("server-%d-%s.tar.gz", server, stamp))
+	for i := 2; ; i++ {
+		// Several snapshots within a second (a scheduled one and a manual one, a retry) must not
+		// overwrite each other; writeSnapshotArchive refuses names that are taken
+		err := writeSnapshotArchive(path, serverDir, &manifest)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		path = filepath.Join(GetSnapshotDir(), fmt.Sprintf("server-%d-%s-%d.tar.gz", server, stamp, i))
+	}
+	if err := writeSnapshotSidecar(path, &manifest); err != nil {
+		return nil, err
+	}
+	return loadSnapshot(path)
+}
+
+// writeSnapshotArchive tars the snapshot paths of serverDir into path, filling in manifest.Files
+// The archive is written to a temporary file and renamed into place once complete
+// Returns an error wrapping os.ErrExist if path or its temporary file already exists
+func writeSnapshotArchive(path, serverDir string, manifest *SnapshotManifest) (err error) {
+	tmpPath := path + ".partial"
+	// O_EXCL reserves the name against a concurrent snapshot
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0640)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			file.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+	// Checked after the reservation, so a snapshot renamed into place meanwhile is seen
+	if _, statErr := os.Stat(path); statErr == nil {
+		return fmt.Errorf("snapshot %s: %w", filepath.Base(path), os.ErrExist)
+	}
+
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+
+	for _, rel := range snapshotPaths {
+		root := filepath.Join(serverDir, rel)
+		if _, statErr := os.Stat(root); os.IsNotExist(statErr) {
+			continue
+		}
+		walkErr := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			// Only directories and regular files are part of a server's data
+			if !info.IsDir() && !info.Mode().IsRegular() {
+				return nil
+			}
+			name, _ := filepath.Rel(serverDir, p)
+			name = filepath.ToSlash(name)
+
+			hdr, err := tar.FileInfoHeader(info, "")
+			if err != nil {
+				return err
+			}
+			hdr.Name = name
+			hdr.Uname, hdr.Gname = "", ""
+			if info.IsDir() {
+				hdr.Name += "/"
+				return tw.WriteHeader(hdr)
+			}
+			if err := tw.WriteHeader(hdr); err != nil {
+				return err
+			}
+
+			src, err := os.Open(p)
+			if err != nil {
+				return err
+			}
+			defer src.Close()
+			hasher := sha256.New()
+			n, err := io.Copy(io.MultiWriter(tw, hasher), io.LimitReader(src, info.Size()))
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", name, err)
+			}
+			if n != info.Size() {
+				return fmt.Errorf("%s changed while the snapshot was taken", name)
+			}
+			manifest.Files = append(manifest.Files, SnapshotFile{
+				Path:   name,
+				Size:   n,
+				SHA256: hex.EncodeToString(hasher.Sum(nil)),
+			})
+			return nil
+		})
+		if walkErr != nil {
+			return fmt.Errorf("failed to snapshot %s: %w", rel, walkErr)
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot manifest: %w", err)
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Name:     SnapshotManifestName,
+		Mode:     0640,
+		Size:     int64(len(data)),
+		ModTime:  manifest.Created,
+		Typeflag: tar.TypeReg,
+	}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finish snapshot: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to finish snapshot: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to finish snapshot: %w", err)
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// snapshotSidecarPath returns the path of the manifest copy kept next to a snapshot
+func snapshotSidecarPath(path string) string {
+	return strings.TrimSuffix(path, ".tar.gz") + ".json"
+}
+
+// writeSnapshotSidecar writes the manifest copy next to a snapshot
+func writeSnapshotSidecar(path string, manifest *SnapshotManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot manifest: %w", err)
+	}
+	if err := os.WriteFile(snapshotSidecarPath(path), data, 0640); err != nil {
+		return fmt.Errorf("failed to write snapshot manifest: %w", err)
+	}
+	return nil
+}
+
+// ReadSnapshotManifest returns a snapshot's manifest, from the copy next to it if present
+func ReadSnapshotManifest(path string) (*SnapshotManifest, error) {
+	var manifest SnapshotManifest
+	if data, err := os.ReadFile(snapshotSidecarPath(path)); err == nil {
+		if err := json.Unmarshal(data, &manifest); err == nil {
+			return &manifest, nil
+		}
+	}
+
+	found := false
+	err := walkArchive(path, func(name string, _ int64, _ os.FileMode, r io.Reader) error {
+		if name != SnapshotManifestName || r == nil {
+			return nil
+		}
+		found = true
+		return json.NewDecoder(r).Decode(&manifest)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("%s is not an HSM snapshot (no %s)", filepath.Base(path), SnapshotManifestName)
+	}
+	return &manifest, nil
+}
+
+// loadSnapshot builds the Snapshot summary for a snapshot archive
+func loadSnapshot(path string) (*Snapshot, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := ReadSnapshotManifest(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{
+		Name:          filepath.Base(path),
+		Path:          path,
+		Server:        manifest.Server,
+		Created:       manifest.Created,
+		Size:          info.Size(),
+		Files:         len(manifest.Files),
+		Worlds:        manifest.Worlds(),
+		SaveConfirmed: manifest.SaveConfirmed,
+	}, nil
+}
+
+// ListSnapshots returns the snapshots taken of a server (0 = all servers), newest first
+func ListSnapshots(server int) ([]Snapshot, error) {
+	entries, err := os.ReadDir(GetSnapshotDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	var snapshots []Snapshot
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".tar.gz") {
+			continue
+		}
+		snapshot, err := loadSnapshot(filepath.Join(GetSnapshotDir(), entry.Name()))
+		if err != nil {
+			continue
+		}
+		if server == 0 || snapshot.Server == server {
+			snapshots = append(snapshots, *snapshot)
+		}
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Created.After(snapshots[j].Created)
+	})
+	return snapshots, nil
+}
+
+// FindSnapshot looks up a snapshot by name
+func FindSnapshot(name string) (*Snapshot, error) {
+	if name != filepath.Base(name) {
+		return nil, fmt.Errorf("invalid snapshot name %q", name)
+	}
+	path := filepath.Join(GetSnapshotDir(), name)
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("snapshot %q not found", name)
+	}
+	return loadSnapshot(path)
+}
+
+// VerifySnapshot checks every file in a snapshot against the checksums in its manifest
+func VerifySnapshot(path string) (*SnapshotManifest, error) {
+	manifest, err := ReadSnapshotManifest(path)
+	if err != nil {
+		return nil, err
+	}
+	expected := make(map[string]SnapshotFile, len(manifest.Files))
+	for _, f := range manifest.Files {
+		expected[f.Path] = f
+	}
+
+	seen := 0
+	err = walkArchive(path, func(name string, _ int64, mode os.FileMode, r io.Reader) error {
+		if mode.IsDir() || r == nil || name == SnapshotManifestName {
+			return nil
+		}
+		want, ok := expected[name]
+		if !ok {
+			return fmt.Errorf("%s is not listed in the snapshot manifest", name)
+		}
+		hasher := sha256.New()
+		n, err := io.Copy(hasher, r)
+		if err != nil {
+			return err
+		}
+		if n != want.Size || hex.EncodeToString(hasher.Sum(nil)) != want.SHA256 {
+			return fmt.Errorf("%s does not match its checksum", name)
+		}
+		seen++
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("snapshot verification failed: %w", err)
+	}
+	if seen != len(manifest.Files) {
+		return nil, fmt.Errorf("snapshot verification failed: %d of %d files present", seen, len(manifest.Files))
+	}
+	return manifest, nil
+}
+
+// RestoreSnapshot restores a snapshot to a server, which may be a different instance than the
+// one it was taken of. The snapshot is verified and extracted to a staging directory first; the
+// server is then stopped, its current universe (and config.json/mods) moved aside and the
+// snapshot moved into place. The server is started again if it was running.
+func (tm *TmuxManager) RestoreSnapshot(name string, server int, opts SnapshotRestoreOptions) (string, error) {
+	snapshot, err := FindSnapshot(name)
+	if err != nil {
+		return "", err
+	}
+	serverDir := GetServerDir(server)
+	if _, err := os.Stat(serverDir); err != nil {
+		return "", fmt.Errorf("server %d does not exist", server)
+	}
+	if _, err := VerifySnapshot(snapshot.Path); err != nil {
+		return "", err
+	}
+
+	// Stage next to the server so the final moves are renames on the same filesystem
+	staging := filepath.Join(serverDir, ".hsm-restore")
+	os.RemoveAll(staging)
+	if err := os.MkdirAll(staging, 0750); err != nil {
+		return "", fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+	if err := extractArchive(snapshot.Path, "", staging); err != nil {
+		return "", fmt.Errorf("failed to extract snapshot: %w", err)
+	}
+	os.Remove(filepath.Join(staging, SnapshotManifestName))
+
+	wasRunning := tm.HasSession(server)
+	if wasRunning {
+		if _, err := tm.Stop(server); err != nil {
+			return "", fmt.Errorf("failed to stop server %d: %w", server, err)
+		}
+	}
+
+	paths := snapshotPaths
+	if opts.UniverseOnly {
+		paths = []string{"universe"}
+	}
+	suffix := ".pre-restore-" + time.Now().Format("20060102-150405")
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Restored %s (server %d, %s) to server %d\n", snapshot.Name, snapshot.Server, snapshot.Created.Format("2006-01-02 15:04"), server))
+	for _, rel := range paths {
+		staged := filepath.Join(staging, rel)
+		if _, err := os.Stat(staged); os.IsNotExist(err) {
+			continue
+		}
+		current := filepath.Join(serverDir, rel)
+		if _, err := os.Stat(current); err == nil {
+			if err := os.Rename(current, current+suffix); err != nil {
+				return b.String(), fmt.Errorf("failed to move %s aside: %w", rel, err)
+			}
+			b.WriteString(fmt.Sprintf("Previous %s kept at %s\n", rel, current+suffix))
+		}
+		if err := os.Rename(staged, current); err != nil {
+			return b.String(), fmt.Errorf("failed to restore %s: %w", rel, err)
+		}
+	}
+
+	manifest := LoadManifestOrDefault()
+	if os.Geteuid() == 0 && SystemUserExists(manifest.HytaleUser) {
+		if err := ApplyServerOwnership(manifest.HytaleUser, server); err != nil {
+			return b.String(), err
+		}
+	}
+
+	if wasRunning && !opts.NoRestart {
+		if err := tm.StartServer(server, LoadLaunchSettings(manifest)); err != nil {
+			return b.String(), fmt.Errorf("snapshot restored but server %d failed to start: %w", server, err)
+		}
+		b.WriteString(fmt.Sprintf("Server %d restarted\n", server))
+	}
+	return b.String(), nil
+}
+
+// DeleteSnapshot deletes a snapshot and its manifest copy
+func DeleteSnapshot(name string) error {
+	snapshot, err := FindSnapshot(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(snapshot.Path); err != nil {
+		return fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+	os.Remove(snapshotSidecarPath(snapshot.Path))
+	return nil
+}
@@ -0,0 +1,70 @@
+package hytale
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteSnapshotArchiveRefusesTakenName(t *testing.T) {
+	serverDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(serverDir, "config.json"), []byte(`{"Version": 3}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "server-1-20260101-120000.tar.gz")
+
+	if err := writeSnapshotArchive(path, serverDir, &SnapshotManifest{Server: 1}); err != nil {
+		t.Fatalf("writeSnapshotArchive: %v", err)
+	}
+	first, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A second snapshot within the same second must not replace the first
+	if err := writeSnapshotArchive(path, serverDir, &SnapshotManifest{Server: 1}); !errors.Is(err, os.ErrExist) {
+		t.Fatalf("second snapshot under the same name: got %v, want os.ErrExist", err)
+	}
+	// Nor may one that is still being written
+	if err := os.WriteFile(path+"-2.partial", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeSnapshotArchive(path+"-2", serverDir, &SnapshotManifest{Server: 1}); !errors.Is(err, os.ErrExist) {
+		t.Fatalf("snapshot over one in progress: got %v, want os.ErrExist", err)
+	}
+
+	second, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(first) != string(second) {
+		t.Error("first snapshot was modified")
+	}
+	if _, err := os.Stat(path + ".partial"); !os.IsNotExist(err) {
+		t.Error("refused snapshot left its temporary file behind")
+	}
+}
+
+func TestParseSnapshotName(t *testing.T) {
+	tests := []struct {
+		name   string
+		server int
+		ok     bool
+	}{
+		{"server-1-20260101-120000.tar.gz", 1, true},
+		{"server-12-20260101-120000-2.tar.gz", 12, true},
+		{"snapshots/server-3-20260101-120000-10.tar.gz.enc", 3, true},
+		{"server-1-20260101-120000.tar.gz.partial", 0, false},
+		{"server-1-20260101.tar.gz", 0, false},
+	}
+	for _, tt := range tests {
+		server, created, ok := parseSnapshotName(tt.name)
+		if ok != tt.ok || server != tt.server {
+			t.Errorf("parseSnapshotName(%q) = %d, %v; want %d, %v", tt.name, server, ok, tt.server, tt.ok)
+		}
+		if ok && created.Format("20060102-150405") != "20260101-120000" {
+			t.Errorf("parseSnapshotName(%q) time = %s", tt.name, created)
+		}
+	}
+}
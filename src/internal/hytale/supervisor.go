@@ -0,0 +1,435 @@
+package hytale
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Server states reported by Status in addition to "running" and "stopped"
+const (
+	StatusRunning      = "running"
+	StatusStopped      = "stopped"
+	StatusCrashed      = "crashed"       // Crashed, waiting for the supervisor to restart it
+	StatusCrashLooping = "crash-looping" // Crashed too often, automatic restarts given up
+)
+
+const (
+	// StopIntentFile is created in a server directory when HSM stops the server on purpose
+	StopIntentFile = ".hsm-stop-intent"
+
+	// ExitStatusFile receives the server process's exit status (written by the process backend)
+	ExitStatusFile = ".hsm-exit-status"
+
+	// SupervisorStateFile holds the supervisor's crash history for a server
+	SupervisorStateFile = ".hsm-supervisor.json"
+
+	// crashTailLines is how many console lines are kept for crash reports
+	crashTailLines = 100
+)
+
+// Supervisor defaults (overridable with hsm daemon flags)
+const (
+	DefaultSupervisorInterval    = 5 * time.Second
+	DefaultSupervisorMaxCrashes  = 5
+	DefaultSupervisorCrashWindow = 10 * time.Minute
+	DefaultSupervisorBackoff     = 10 * time.Second
+	DefaultSupervisorMaxBackoff  = 5 * time.Minute
+)
+
+// crashLinePattern matches console lines that indicate the JVM died abnormally
+var crashLinePattern = regexp.MustCompile(`(?i)(exception|error:|SEVERE|OutOfMemoryError|hs_err_pid|fatal)`)
+
+// SupervisorState is the crash history the supervisor keeps for each server
+type SupervisorState struct {
+	State       string      `json:"state"` // StatusCrashed, StatusCrashLooping or "" when healthy
+	Crashes     []time.Time `json:"crashes"`
+	LastReason  string      `json:"last_reason,omitempty"`
+	LastReport  string      `json:"last_report,omitempty"`
+	NextRestart time.Time   `json:"next_restart,omitempty"`
+}
+
+// GetSupervisorStatePath returns the path to a server's supervisor state
+func GetSupervisorStatePath(serverNum int) string {
+	return filepath.Join(GetServerDir(serverNum), SupervisorStateFile)
+}
+
+// GetServerLogsDir returns the path to a server's logs directory
+func GetServerLogsDir(serverNum int) string {
+	return filepath.Join(GetServerDir(serverNum), "logs")
+}
+
+// LoadSupervisorState reads a server's supervisor state (empty state if there is none)
+func LoadSupervisorState(serverNum int) *SupervisorState {
+	state := &SupervisorState{}
+	data, err := os.ReadFile(GetSupervisorStatePath(serverNum))
+	if err != nil {
+		return state
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return &SupervisorState{}
+	}
+	return state
+}
+
+// SaveSupervisorState writes a server's supervisor state
+func SaveSupervisorState(serverNum int, state *SupervisorState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal supervisor state: %w", err)
+	}
+	path := GetSupervisorStatePath(serverNum)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write supervisor state: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write supervisor state: %w", err)
+	}
+	return nil
+}
+
+// markStopIntent records that HSM is stopping a server on purpose
+func markStopIntent(server int) {
+	_ = os.WriteFile(filepath.Join(GetServerDir(server), StopIntentFile), []byte(time.Now().Format(time.RFC3339)+"\n"), 0644)
+}
+
+// clearExitMarkers removes the stop intent and exit status left by the previous run
+func clearExitMarkers(server int) {
+	os.Remove(filepath.Join(GetServerDir(server), StopIntentFile))
+	os.Remove(filepath.Join(GetServerDir(server), ExitStatusFile))
+}
+
+// hasStopIntent checks if HSM stopped a server on purpose
+func hasStopIntent(server int) bool {
+	_, err := os.Stat(filepath.Join(GetServerDir(server), StopIntentFile))
+	return err == nil
+}
+
+// readExitStatus returns the exit status the backend recorded for a server's last run
+// The status is a number, or a signal name when systemd reports one (e.g. "KILL")
+func readExitStatus(server int) (string, bool) {
+	data, err := os.ReadFile(filepath.Join(GetServerDir(server), ExitStatusFile))
+	if err != nil {
+		return "", false
+	}
+	status := strings.TrimSpace(string(data))
+	return status, status != ""
+}
+
+// ClassifyExit decides whether a server that is no longer running crashed
+// An HSM stop (stop intent marker) is never a crash; otherwise a zero exit status is a
+// clean stop (e.g. /stop typed in the console), a non-zero status is a crash, and
+// without an exit status the last console lines decide
+func ClassifyExit(server int, tail []string) (crashed bool, reason string) {
+	if hasStopIntent(server) {
+		return false, "stopped by HSM"
+	}
+
+	if status, ok := readExitStatus(server); ok {
+		if status == "0" {
+			return false, "exited cleanly (exit status 0)"
+		}
+		if code, err := strconv.Atoi(status); err == nil && code > 128 {
+			// Shells report death by signal as 128+N
+			return true, fmt.Sprintf("killed by %s (exit status %d)", syscall.Signal(code-128), code)
+		}
+		return true, fmt.Sprintf("exit status %s", status)
+	}
+
+	for i := len(tail) - 1; i >= 0; i-- {
+		if crashLinePattern.MatchString(tail[i]) {
+			return true, fmt.Sprintf("exit status unknown, last error: %s", strings.TrimSpace(tail[i]))
+		}
+	}
+	return true, "exit status unknown (process disappeared)"
+}
+
+// WriteCrashReport saves a crash report with the tail of the console to server-N/logs/
+// Returns the report path
+func WriteCrashReport(server int, reason string, tail []string) (string, error) {
+	logsDir := GetServerLogsDir(server)
+	if err := EnsureUserDir(logsDir, LoadManifestOrDefault().HytaleUser); err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	path := filepath.Join(logsDir, fmt.Sprintf("crash-%s.log", now.Format("20060102-150405")))
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Hytale server %d crash report\n", server))
+	b.WriteString(fmt.Sprintf("Time:   %s\n", now.Format(time.RFC3339)))
+	b.WriteString(fmt.Sprintf("Reason: %s\n", reason))
+	if status, ok := readExitStatus(server); ok {
+		b.WriteString(fmt.Sprintf("Exit:   %s\n", status))
+	}
+	b.WriteString(fmt.Sprintf("\nLast %d console lines:\n", len(tail)))
+	b.WriteString(strings.Repeat("-", 60) + "\n")
+	for _, line := range tail {
+		b.WriteString(line + "\n")
+	}
+
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return "", fmt.Errorf("failed to write crash report: %w", err)
+	}
+	return path, nil
+}
+
+// Supervisor watches servers, telling intentional stops from crashes and restarting
+// crashed servers with exponential backoff until they crash too often
+type Supervisor struct {
+	Interval    time.Duration // How often servers are checked
+	MaxCrashes  int           // Crashes within CrashWindow before a server is marked crash-looping
+	CrashWindow time.Duration
+	Backoff     time.Duration // Delay before the first restart, doubled for each further crash in the window
+	MaxBackoff  time.Duration
+
+	// Logf receives the supervisor's activity log
+	Logf func(format string, args ...interface{})
+
+	// Scheduler, if set, runs the scheduled jobs on every pass
+	Scheduler *Scheduler
+
+	servers map[int]*supervisedServer
+}
+
+// supervisedServer is what the supervisor remembers about a server between checks
+type supervisedServer struct {
+	running    bool
+	tail       []string  // Last console lines seen while running (tmux output is gone once the session ends)
+	lastBackup time.Time // Newest game backup retention has been applied after
+}
+
+// NewSupervisor creates a supervisor with the default settings
+func NewSupervisor() *Supervisor {
+	return &Supervisor{
+		Interval:    DefaultSupervisorInterval,
+		MaxCrashes:  DefaultSupervisorMaxCrashes,
+		CrashWindow: DefaultSupervisorCrashWindow,
+		Backoff:     DefaultSupervisorBackoff,
+		MaxBackoff:  DefaultSupervisorMaxBackoff,
+		Logf:        func(string, ...interface{}) {},
+		servers:     make(map[int]*supervisedServer),
+	}
+}
+
+// Run supervises servers until ctx is cancelled
+// Only one supervisor can run per installation
+func (s *Supervisor) Run(ctx context.Context) error {
+	lock, err := lockSupervisor()
+	if err != nil {
+		return err
+	}
+	defer lock.Close()
+
+	s.Logf("Supervisor started (check every %s, crash-looping after %d crashes in %s)", s.Interval, s.MaxCrashes, s.CrashWindow)
+
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		s.check()
+		if s.Scheduler != nil {
+			s.Scheduler.Check(ctx, time.Now())
+		}
+		select {
+		case <-ctx.Done():
+			if s.Scheduler != nil {
+				s.Scheduler.Wait()
+			}
+			s.Logf("Supervisor stopped")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// lockSupervisor takes an exclusive lock so two supervisors never restart the same server
+func lockSupervisor() (*os.File, error) {
+	if err := os.MkdirAll(ConfigDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create config directory: %w", err)
+	}
+	f, err := os.OpenFile(filepath.Join(ConfigDir, "daemon.lock"), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open daemon lock: %w", err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("another hsm daemon is already running")
+	}
+	return f, nil
+}
+
+// check runs one supervision pass over all servers
+func (s *Supervisor) check() {
+	// Re-read the manifest every pass so backend or port changes are picked up
+	manifest := LoadManifestOrDefault()
+	tm := NewTmuxManagerFromManifest(manifest)
+
+	for server := 1; server <= DetectNumServers(); server++ {
+		srv, ok := s.servers[server]
+		if !ok {
+			srv = &supervisedServer{}
+			s.servers[server] = srv
+		}
+
+		// The game writes its backups on its own schedule, so apply retention whenever a new one appears
+		if latest := latestGameBackup(server); latest.After(srv.lastBackup) {
+			srv.lastBackup = latest
+			s.pruneGameBackups(server)
+		}
+
+		if tm.HasSession(server) {
+			srv.running = true
+			if output, err := tm.Logs(server, crashTailLines); err == nil {
+				srv.tail = SplitLogLines(output)
+			}
+			continue
+		}
+
+		if srv.running {
+			// Running at the last check, gone now
+			srv.running = false
+			s.handleExit(tm, manifest, server, srv.tail)
+			srv.tail = nil
+		}
+
+		state := LoadSupervisorState(server)
+		if state.State == StatusCrashed && !state.NextRestart.IsZero() && !time.Now().Before(state.NextRestart) {
+			s.restart(tm, manifest, server, state)
+		}
+	}
+}
+
+// pruneGameBackups applies the retention policy to a server's game backups
+func (s *Supervisor) pruneGameBackups(server int) {
+	config, err := ReadBackupConfig()
+	if err != nil {
+		return
+	}
+	policy := config.RetentionFor(server)
+	if !policy.Enabled() {
+		return
+	}
+	result := PruneGameBackups(server, policy, false)
+	if len(result.Removed) > 0 || result.Err != "" {
+		s.Logf("Retention: %s", result)
+	}
+}
+
+// handleExit classifies a server that stopped since the last check and schedules a restart if it crashed
+func (s *Supervisor) handleExit(tm *TmuxManager, manifest *Manifest, server int, tail []string) {
+	// Console logs and the journal outlive the process, so prefer them over the cached tail
+	if output, err := tm.Logs(server, crashTailLines); err == nil && strings.TrimSpace(output) != "" {
+		tail = SplitLogLines(output)
+	}
+
+	crashed, reason := ClassifyExit(server, tail)
+	state := LoadSupervisorState(server)
+	if !crashed {
+		s.Logf("Server %d stopped: %s", server, reason)
+		// A crash-looping server stays marked until it is started by hand, even when
+		// its systemd unit is stopped below after a restart systemd already began
+		if state.State != StatusCrashLooping {
+			state.State = ""
+		}
+		state.NextRestart = time.Time{}
+		_ = SaveSupervisorState(server, state)
+		return
+	}
+
+	now := time.Now()
+	state.Crashes = append(recentCrashes(state.Crashes, now, s.CrashWindow), now)
+	state.LastReason = reason
+
+	report, err := WriteCrashReport(server, reason, tail)
+	if err != nil {
+		s.Logf("Server %d: %v", server, err)
+	}
+	state.LastReport = report
+
+	switch {
+	case len(state.Crashes) >= s.MaxCrashes:
+		state.State = StatusCrashLooping
+		state.NextRestart = time.Time{}
+		s.Logf("Server %d crashed (%s) - %d crashes in %s, marked crash-looping; start it manually once fixed", server, reason, len(state.Crashes), s.CrashWindow)
+		if manifest.ProcessBackend == BackendSystemd && manifest.SystemdRestart != "no" {
+			// systemd's Restart= policy would keep bringing the unit back; stopping and
+			// disabling it cancels the pending restart
+			markStopIntent(server)
+			if err := tm.backend.Stop(server); err != nil {
+				s.Logf("Server %d: failed to stop %s: %v", server, tm.SessionName(server), err)
+			}
+		}
+	case manifest.ProcessBackend == BackendSystemd && manifest.SystemdRestart != "no":
+		// systemd's Restart= policy already brings the unit back
+		state.State = ""
+		state.NextRestart = time.Time{}
+		s.Logf("Server %d crashed (%s) - systemd will restart it (report: %s)", server, reason, report)
+	default:
+		delay := s.backoff(len(state.Crashes))
+		state.State = StatusCrashed
+		state.NextRestart = now.Add(delay)
+		s.Logf("Server %d crashed (%s) - restarting in %s (report: %s)", server, reason, delay, report)
+	}
+
+	if err := SaveSupervisorState(server, state); err != nil {
+		s.Logf("Server %d: %v", server, err)
+	}
+}
+
+// restart starts a crashed server again; a failed start counts as another crash
+func (s *Supervisor) restart(tm *TmuxManager, manifest *Manifest, server int, state *SupervisorState) {
+	s.Logf("Restarting server %d", server)
+	err := tm.StartServer(server, LoadLaunchSettings(manifest))
+	if err == nil {
+		state.State = ""
+		state.NextRestart = time.Time{}
+		_ = SaveSupervisorState(server, state)
+		return
+	}
+
+	now := time.Now()
+	state.Crashes = append(recentCrashes(state.Crashes, now, s.CrashWindow), now)
+	state.LastReason = fmt.Sprintf("restart failed: %v", err)
+	if len(state.Crashes) >= s.MaxCrashes {
+		state.State = StatusCrashLooping
+		state.NextRestart = time.Time{}
+		s.Logf("Server %d failed to restart (%v) - marked crash-looping", server, err)
+	} else {
+		delay := s.backoff(len(state.Crashes))
+		state.NextRestart = now.Add(delay)
+		s.Logf("Server %d failed to restart (%v) - retrying in %s", server, err, delay)
+	}
+	_ = SaveSupervisorState(server, state)
+}
+
+// backoff returns the restart delay after the given number of recent crashes
+func (s *Supervisor) backoff(crashes int) time.Duration {
+	delay := s.Backoff
+	for i := 1; i < crashes && delay < s.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > s.MaxBackoff {
+		delay = s.MaxBackoff
+	}
+	return delay
+}
+
+// recentCrashes drops crashes older than window
+func recentCrashes(crashes []time.Time, now time.Time, window time.Duration) []time.Time {
+	var recent []time.Time
+	for _, t := range crashes {
+		if now.Sub(t) <= window {
+			recent = append(recent, t)
+		}
+	}
+	return recent
+}
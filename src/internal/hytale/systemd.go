@@ -0,0 +1,334 @@
+package hytale
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/template"
+)
+
+const (
+	// SystemdUnitDir is where HSM installs its unit templates
+	SystemdUnitDir = "/etc/systemd/system"
+
+	// SystemdUnitPrefix is the templated unit name prefix (hytale-server@N.service)
+	SystemdUnitPrefix = "hytale-server"
+
+	// SystemdRuntimeDir holds the per-server console input FIFOs
+	SystemdRuntimeDir = "/run/hytale"
+
+	// DefaultSystemdRestart is the default Restart= policy for server units
+	DefaultSystemdRestart = "on-failure"
+)
+
+// systemdServiceTemplate is the templated hytale-server@.service unit
+// Console input comes from the matching socket unit's FIFO so SendCommand works like tmux send-keys
+// Java runs under `hsm logpipe`, which writes the persistent console log and passes output on to the journal
+// The launch arguments are read by logpipe from a JSON file rather than expanded by systemd, which
+// would split arguments containing spaces
+var systemdServiceTemplate = template.Must(template.New("service").Parse(`# Managed by Hytale Server Manager (HSM) - changes will be overwritten
+[Unit]
+Description=Hytale server %i (HSM)
+Wants=network-online.target
+After=network-online.target {{.Prefix}}@%i.socket
+Requires={{.Prefix}}@%i.socket
+
+[Service]
+Type=simple
+User={{.User}}
+Group={{.User}}
+WorkingDirectory={{.DataDir}}/server-%i
+ExecStart={{.HSM}} logpipe %i --argv {{.EnvDir}}/server-%i.argv.json
+ExecStop=/bin/sh -c 'echo /stop > {{.RuntimeDir}}/server-%i.stdin; while kill -0 $MAINPID 2>/dev/null; do sleep 1; done'
+ExecStopPost=/bin/sh -c 'echo $EXIT_STATUS > {{.DataDir}}/server-%i/{{.ExitStatusFile}}'
+TimeoutStopSec=120
+Sockets={{.Prefix}}@%i.socket
+StandardInput=socket
+StandardOutput=journal
+StandardError=journal
+Restart={{.Restart}}
+RestartSec=10
+# A stop that escalates to SIGTERM (or SIGINT) makes the JVM exit with 128+signal, which is not a failure to restart after
+SuccessExitStatus=130 143
+{{- if .MemoryMax}}
+MemoryMax={{.MemoryMax}}
+{{- end}}
+{{- if .CPUQuota}}
+CPUQuota={{.CPUQuota}}
+{{- end}}
+
+[Install]
+WantedBy=multi-user.target
+`))
+
+// systemdSocketTemplate is the templated hytale-server@.socket unit providing console input
+var systemdSocketTemplate = template.Must(template.New("socket").Parse(`# Managed by Hytale Server Manager (HSM) - changes will be overwritten
+[Unit]
+Description=Hytale server %i console input (HSM)
+PartOf={{.Prefix}}@%i.service
+
+[Socket]
+ListenFIFO={{.RuntimeDir}}/server-%i.stdin
+SocketUser={{.User}}
+SocketGroup={{.User}}
+SocketMode=0660
+RemoveOnStop=true
+`))
+
+// systemdUnitParams are the values substituted into the unit templates
+type systemdUnitParams struct {
+	Prefix         string
+	User           string
+	DataDir        string
+	EnvDir         string
+	RuntimeDir     string
+	ExitStatusFile string
+	HSM            string
+	Restart        string
+	MemoryMax      string
+	CPUQuota       string
+}
+
+// systemdBackend runs each server as an instance of the hytale-server@.service template
+// Units are enabled on start so servers come back after a host reboot
+type systemdBackend struct {
+	user      string
+	restart   string
+	memoryMax string
+	cpuQuota  string
+}
+
+func newSystemdBackend(manifest *Manifest) *systemdBackend {
+	restart := manifest.SystemdRestart
+	if restart == "" {
+		restart = DefaultSystemdRestart
+	}
+	return &systemdBackend{
+		user:      manifest.HytaleUser,
+		restart:   restart,
+		memoryMax: manifest.SystemdMemoryMax,
+		cpuQuota:  manifest.SystemdCPUQuota,
+	}
+}
+
+// GetSystemdEnvDir returns the directory holding per-server launch arguments
+func GetSystemdEnvDir() string {
+	return filepath.Join(ConfigDir, "instances")
+}
+
+// Name returns the backend name
+func (sb *systemdBackend) Name() string {
+	return BackendSystemd
+}
+
+// SessionName returns the systemd unit name for a given server number
+func (sb *systemdBackend) SessionName(server int) string {
+	return fmt.Sprintf("%s@%d.service", SystemdUnitPrefix, server)
+}
+
+// fifoPath returns the console input FIFO for a server
+func (sb *systemdBackend) fifoPath(server int) string {
+	return filepath.Join(SystemdRuntimeDir, fmt.Sprintf("server-%d.stdin", server))
+}
+
+// systemctl runs a systemctl command and includes its output in any error
+func systemctl(args ...string) error {
+	output, err := exec.Command("systemctl", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemctl %s failed: %w\nOutput: %s", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// systemdArgvPath returns the file holding a server's launch command
+func systemdArgvPath(server int) string {
+	return filepath.Join(GetSystemdEnvDir(), fmt.Sprintf("server-%d.argv.json", server))
+}
+
+// ReadSystemdArgv reads a launch command written for the systemd backend (used by hsm logpipe)
+func ReadSystemdArgv(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read launch command: %w", err)
+	}
+	var argv []string
+	if err := json.Unmarshal(data, &argv); err != nil {
+		return nil, fmt.Errorf("failed to parse launch command %s: %w", path, err)
+	}
+	if len(argv) == 0 {
+		return nil, fmt.Errorf("launch command %s is empty", path)
+	}
+	return argv, nil
+}
+
+// installUnits writes the unit templates if they changed and reloads systemd
+func (sb *systemdBackend) installUnits() error {
+	hsm, err := hsmExecutable()
+	if err != nil {
+		return err
+	}
+	params := systemdUnitParams{
+		Prefix:         SystemdUnitPrefix,
+		User:           sb.user,
+		DataDir:        DataDirBase,
+		EnvDir:         GetSystemdEnvDir(),
+		RuntimeDir:     SystemdRuntimeDir,
+		ExitStatusFile: ExitStatusFile,
+		HSM:            hsm,
+		Restart:        sb.restart,
+		MemoryMax:      sb.memoryMax,
+		CPUQuota:       sb.cpuQuota,
+	}
+
+	units := map[string]*template.Template{
+		SystemdUnitPrefix + "@.service": systemdServiceTemplate,
+		SystemdUnitPrefix + "@.socket":  systemdSocketTemplate,
+	}
+
+	changed := false
+	for name, tmpl := range units {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, params); err != nil {
+			return fmt.Errorf("failed to render %s: %w", name, err)
+		}
+		path := filepath.Join(SystemdUnitDir, name)
+		if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, buf.Bytes()) {
+			continue
+		}
+		if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		changed = true
+	}
+
+	if changed {
+		return systemctl("daemon-reload")
+	}
+	return nil
+}
+
+// Start writes the server's launch arguments and enables and starts its unit
+func (sb *systemdBackend) Start(server int, dataDir string, argv []string) error {
+	if len(argv) == 0 {
+		return fmt.Errorf("no command to start")
+	}
+
+	// The unit's PATH may not be ours, so the program is resolved here
+	program, err := exec.LookPath(argv[0])
+	if err != nil {
+		return fmt.Errorf("%s not found in PATH: %w", argv[0], err)
+	}
+	if err := sb.installUnits(); err != nil {
+		return err
+	}
+
+	// The launch command goes in a JSON file that logpipe runs as it is, one argument per element
+	envDir := GetSystemdEnvDir()
+	if err := os.MkdirAll(envDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", envDir, err)
+	}
+	data, err := json.Marshal(append([]string{program}, argv[1:]...))
+	if err != nil {
+		return fmt.Errorf("failed to encode launch command: %w", err)
+	}
+	argvPath := systemdArgvPath(server)
+	// 0600 and owned by the server's user, who runs logpipe: the arguments can include session tokens
+	if err := os.WriteFile(argvPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", argvPath, err)
+	}
+	if os.Geteuid() == 0 && SystemUserExists(sb.user) {
+		uid, gid, err := lookupIDs(sb.user)
+		if err != nil {
+			return err
+		}
+		if err := os.Chown(argvPath, uid, gid); err != nil {
+			return fmt.Errorf("failed to chown %s: %w", argvPath, err)
+		}
+	}
+	// Launch arguments of older HSM versions, which systemd expanded from an EnvironmentFile
+	os.Remove(filepath.Join(envDir, fmt.Sprintf("server-%d.env", server)))
+
+	return systemctl("enable", "--now", sb.SessionName(server))
+}
+
+// Stop stops the unit (ExecStop sends /stop and waits if the JVM is still up) and disables
+// it so an intentionally stopped server stays stopped after a reboot
+func (sb *systemdBackend) Stop(server int) error {
+	return systemctl("disable", "--now", sb.SessionName(server))
+}
+
+// IsRunning checks if the server's unit is active
+func (sb *systemdBackend) IsRunning(server int) bool {
+	return exec.Command("systemctl", "is-active", "--quiet", sb.SessionName(server)).Run() == nil
+}
+
+// MainPID returns the PID of the JVM in the server's unit
+// systemd's main PID is the `hsm logpipe` wrapper, so the JVM is looked up among its children
+func (sb *systemdBackend) MainPID(server int) (int, error) {
+	output, err := exec.Command("systemctl", "show", "-p", "MainPID", "--value", sb.SessionName(server)).Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to query %s: %w", sb.SessionName(server), err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(output)))
+	if err != nil {
+		return 0, fmt.Errorf("invalid MainPID for %s: %w", sb.SessionName(server), err)
+	}
+	if pid == 0 {
+		return 0, fmt.Errorf("%s: %w", sb.SessionName(server), ErrNotRunning)
+	}
+	if javaPID := findJavaPID(pid); javaPID > 0 {
+		return javaPID, nil
+	}
+	return pid, nil
+}
+
+// Logs reads the last N lines of the server's journal
+func (sb *systemdBackend) Logs(server int, lines int) (string, error) {
+	cmd := exec.Command("journalctl", "-u", sb.SessionName(server), "-n", strconv.Itoa(lines), "--no-pager", "-o", "cat")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read journal for %s: %w", sb.SessionName(server), err)
+	}
+	return string(output), nil
+}
+
+// SendCommand writes a command line to the server's console FIFO
+func (sb *systemdBackend) SendCommand(server int, command string) error {
+	// O_NONBLOCK makes the open fail (ENXIO) instead of hanging when nothing reads the FIFO
+	f, err := os.OpenFile(sb.fifoPath(server), os.O_WRONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		if os.IsNotExist(err) || errors.Is(err, syscall.ENXIO) {
+			return fmt.Errorf("%s: %w", sb.SessionName(server), ErrNotRunning)
+		}
+		return fmt.Errorf("failed to open console for %s: %w", sb.SessionName(server), err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(command + "\n"); err != nil {
+		return fmt.Errorf("failed to send command to %s: %w", sb.SessionName(server), err)
+	}
+	return nil
+}
+
+// Remove stops and disables the unit and deletes its launch arguments
+func (sb *systemdBackend) Remove(server int) error {
+	err := systemctl("disable", "--now", sb.SessionName(server))
+	os.Remove(systemdArgvPath(server))
+	os.Remove(filepath.Join(GetSystemdEnvDir(), fmt.Sprintf("server-%d.env", server)))
+	return err
+}
+
+// RemoveSystemdUnits deletes HSM's unit templates (used when wiping an installation)
+func RemoveSystemdUnits() {
+	for _, name := range []string{SystemdUnitPrefix + "@.service", SystemdUnitPrefix + "@.socket"} {
+		os.Remove(filepath.Join(SystemdUnitDir, name))
+	}
+	_ = exec.Command("systemctl", "daemon-reload").Run()
+}
@@ -0,0 +1,76 @@
+package hytale
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSystemdArgvKeepsSpaces(t *testing.T) {
+	argv := []string{
+		"/usr/bin/java",
+		"-Xmx6G",
+		`-XX:OnOutOfMemoryError=kill -9 %p`,
+		"-jar", "/srv/hytale data/master-install/Server/HytaleServer.jar",
+		"--assets", "/srv/hytale data/server-1/Assets.zip",
+		"--session-token", `it's "quoted"`,
+	}
+	data, err := json.Marshal(argv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "server-1.argv.json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadSystemdArgv(path)
+	if err != nil {
+		t.Fatalf("ReadSystemdArgv: %v", err)
+	}
+	if !reflect.DeepEqual(got, argv) {
+		t.Errorf("ReadSystemdArgv = %q, want %q", got, argv)
+	}
+
+	if err := os.WriteFile(path, []byte("[]"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadSystemdArgv(path); err == nil {
+		t.Error("empty launch command accepted")
+	}
+}
+
+func TestSystemdServiceUnit(t *testing.T) {
+	var buf bytes.Buffer
+	err := systemdServiceTemplate.Execute(&buf, systemdUnitParams{
+		Prefix:         SystemdUnitPrefix,
+		User:           "hytale",
+		DataDir:        "/var/lib/hytale",
+		EnvDir:         "/etc/hytale/instances",
+		RuntimeDir:     SystemdRuntimeDir,
+		ExitStatusFile: ExitStatusFile,
+		HSM:            "/usr/local/bin/hsm",
+		Restart:        DefaultSystemdRestart,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unit := buf.String()
+
+	for _, line := range []string{
+		"ExecStart=/usr/local/bin/hsm logpipe %i --argv /etc/hytale/instances/server-%i.argv.json\n",
+		"SuccessExitStatus=130 143\n",
+	} {
+		if !strings.Contains(unit, line) {
+			t.Errorf("unit has no %q\n%s", strings.TrimSpace(line), unit)
+		}
+	}
+	// systemd splits an unquoted $VAR on whitespace, breaking arguments that contain spaces
+	if strings.Contains(unit, "$HYTALE_ARGS") || strings.Contains(unit, "EnvironmentFile=") {
+		t.Errorf("unit expands launch arguments from the environment\n%s", unit)
+	}
+}
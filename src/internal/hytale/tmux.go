@@ -3,37 +3,73 @@ package hytale
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"strconv"
 	"strings"
+	"time"
 )
 
-// TmuxManager manages Hytale server processes via tmux sessions
+// TmuxManager manages Hytale server processes
+// It builds the server command line and delegates process control to the
+// installation's ProcessBackend (tmux sessions by default, or systemd units)
 type TmuxManager struct {
-	basePort int
+	basePort    int
+	backend     ProcessBackend
+	stopTimeout time.Duration // Grace period after /stop before escalating to SIGTERM
+	savePattern string        // Console line that confirms the world was saved on stop
+	user        string        // System user servers run as
 }
 
-// NewTmuxManager creates a new TmuxManager instance
+// NewTmuxManager creates a new TmuxManager instance using the tmux backend
 func NewTmuxManager(basePort int) *TmuxManager {
 	return &TmuxManager{
-		basePort: basePort,
+		basePort:    basePort,
+		backend:     newTmuxBackend(""),
+		stopTimeout: DefaultStopTimeoutSeconds * time.Second,
+		savePattern: DefaultSaveCompletePattern,
 	}
 }
 
-// SessionName returns the tmux session name for a given server number
+// NewTmuxManagerFromManifest creates a TmuxManager using the installation's configured
+// base port, process backend and system user
+func NewTmuxManagerFromManifest(manifest *Manifest) *TmuxManager {
+	backend, err := NewProcessBackend(manifest)
+	if err != nil {
+		// Unknown backend in a hand-edited manifest - tmux is what older installs used
+		backend = newTmuxBackend(manifest.HytaleUser)
+	}
+	stopTimeout := time.Duration(manifest.StopTimeoutSeconds) * time.Second
+	if stopTimeout <= 0 {
+		stopTimeout = DefaultStopTimeoutSeconds * time.Second
+	}
+	savePattern := manifest.SaveCompletePattern
+	if savePattern == "" {
+		savePattern = DefaultSaveCompletePattern
+	}
+	return &TmuxManager{
+		basePort:    manifest.BasePort,
+		backend:     backend,
+		stopTimeout: stopTimeout,
+		savePattern: savePattern,
+		user:        manifest.HytaleUser,
+	}
+}
+
+// Backend returns the process backend servers are controlled through
+func (tm *TmuxManager) Backend() ProcessBackend {
+	return tm.backend
+}
+
+// SessionName returns the backend session name (tmux session or systemd unit) for a given server number
 func (tm *TmuxManager) SessionName(server int) string {
-	return fmt.Sprintf("%s-%d", TmuxSessionPrefix, server)
+	return tm.backend.SessionName(server)
 }
 
-// HasSession checks if a tmux session exists
+// HasSession checks if a server's process is running
 func (tm *TmuxManager) HasSession(server int) bool {
-	sessionName := tm.SessionName(server)
-	cmd := exec.Command("tmux", "has-session", "-t", sessionName)
-	return cmd.Run() == nil
+	return tm.backend.IsRunning(server)
 }
 
-// Start launches a Hytale server in a tmux session
+// Start launches a Hytale server through the process backend
 // If sessionTokens is provided, servers start authenticated (no need for /auth login device)
 // Per Server Provider Authentication Guide: https://support.hytale.com/hc/en-us/articles/45328341414043
 func (tm *TmuxManager) Start(server int, dataDir, jarPath string, jvmArgs string, backupEnabled bool, backupFrequency int, sessionTokens *SessionTokens) error {
@@ -41,12 +77,17 @@ func (tm *TmuxManager) Start(server int, dataDir, jarPath string, jvmArgs string
 
 	// Check if session already exists
 	if tm.HasSession(server) {
-		return fmt.Errorf("session %s already exists", sessionName)
+		return fmt.Errorf("session %s already exists: %w", sessionName, ErrAlreadyRunning)
 	}
 
 	// Calculate port from basePort (Hytale doesn't store port in config.json, it's passed via --bind)
 	port := tm.basePort + (server - 1)
 	
+	// Run the server's own copy of the game, which may be a different version than master-install
+	if serverJar := filepath.Join(dataDir, "Server", "HytaleServer.jar"); fileExists(serverJar) {
+		jarPath = serverJar
+	}
+
 	// Parse JVM args into array
 	args := strings.Fields(jvmArgs)
 	args = append(args, "-jar", jarPath)
@@ -73,6 +114,9 @@ func (tm *TmuxManager) Start(server int, dataDir, jarPath string, jvmArgs string
 		args = append(args, "--backup")
 		// --backup-frequency: Backup frequency in minutes
 		args = append(args, "--backup-frequency", fmt.Sprintf("%d", backupFrequency))
+		// --backup-dir: Keep backups in a known place so HSM can list and restore them
+		args = append(args, "--backup-dir", GetServerBackupDir(server))
+		_ = EnsureUserDir(GetServerBackupDir(server), tm.user)
 	}
 
 	// Add session and identity tokens if provided (per Server Provider Authentication Guide)
@@ -85,32 +129,25 @@ func (tm *TmuxManager) Start(server int, dataDir, jarPath string, jvmArgs string
 		}
 	}
 
-	// Create tmux session and run server
-	cmd := exec.Command("tmux", "new-session", "-d", "-s", sessionName,
-		"-c", dataDir,
-		"java", strings.Join(args, " "))
-	
-	return cmd.Run()
-}
+	// The console log is written by the server's user, so the logs directory must belong to it
+	_ = EnsureUserDir(GetServerLogsDir(server), tm.user)
 
-// Stop gracefully stops a server by sending /stop command, then kills the tmux session
-func (tm *TmuxManager) Stop(server int) error {
-	sessionName := tm.SessionName(server)
-
-	if !tm.HasSession(server) {
-		return fmt.Errorf("session %s does not exist", sessionName)
+	// Forget how the previous run ended, and clear a crash state the operator is overriding
+	clearExitMarkers(server)
+	if state := LoadSupervisorState(server); state.State != "" {
+		state.State = ""
+		state.NextRestart = time.Time{}
+		_ = SaveSupervisorState(server, state)
 	}
 
-	// Send /stop command to server
-	cmd := exec.Command("tmux", "send-keys", "-t", sessionName, "/stop", "C-m")
-	_ = cmd.Run()
-
-	// Wait a moment, then kill session
-	cmd = exec.Command("sleep", "2")
-	_ = cmd.Run()
+	// Launch java with the assembled arguments
+	return tm.backend.Start(server, dataDir, append([]string{"java"}, args...))
+}
 
-	cmd = exec.Command("tmux", "kill-session", "-t", sessionName)
-	return cmd.Run()
+// Remove forcibly tears down a server's session without asking the server to stop
+func (tm *TmuxManager) Remove(server int) error {
+	markStopIntent(server)
+	return tm.backend.Remove(server)
 }
 
 // StartAll starts all servers with optional session tokens
@@ -124,19 +161,10 @@ func (tm *TmuxManager) StartAll(numServers int, dataDirBase, jarPath string, jvm
 	return nil
 }
 
-// StopAll stops all running servers
-func (tm *TmuxManager) StopAll(numServers int) error {
-	for i := 1; i <= numServers; i++ {
-		if tm.HasSession(i) {
-			_ = tm.Stop(i) // Continue on error
-		}
-	}
-	return nil
-}
-
 // Status returns human-readable status for all servers
 func (tm *TmuxManager) Status(numServers int) []ServerStatus {
 	statuses := make([]ServerStatus, numServers)
+	manifest := LoadManifestOrDefault()
 	
 	for i := 1; i <= numServers; i++ {
 		sessionName := tm.SessionName(i)
@@ -145,9 +173,12 @@ func (tm *TmuxManager) Status(numServers int) []ServerStatus {
 		port := tm.basePort + (i - 1)
 		
 		running := tm.HasSession(i)
-		status := "stopped"
+		status := StatusStopped
 		if running {
-			status = "running"
+			status = StatusRunning
+		} else if state := LoadSupervisorState(i); state.State != "" {
+			// Crashed or crash-looping according to the supervisor (hsm daemon)
+			status = state.State
 		}
 
 		statuses[i-1] = ServerStatus{
@@ -155,34 +186,37 @@ func (tm *TmuxManager) Status(numServers int) []ServerStatus {
 			Status: status,
 			Port:   port,
 			Session: sessionName,
+			Patchline: ServerPatchline(manifest, i),
+			GameVersion: manifest.ServerGameVersions[i],
 		}
 	}
 
 	return statuses
 }
 
-// Logs reads the last N lines from a server's tmux session
+// Logs reads the last N lines of a server's console output
+// The persistent console logs are used when they exist (so logs survive restarts and
+// stopped servers), falling back to the backend's own output (tmux scrollback, journal)
 func (tm *TmuxManager) Logs(server int, lines int) (string, error) {
 	sessionName := tm.SessionName(server)
 
-	if !tm.HasSession(server) {
-		return "", fmt.Errorf("session %s does not exist", sessionName)
+	if output, err := TailConsoleLog(server, lines); err == nil {
+		return output, nil
 	}
 
-	// Use tmux capture-pane to get output
-	cmd := exec.Command("tmux", "capture-pane", "-t", sessionName, "-p", "-S", strconv.Itoa(-lines))
-	output, err := cmd.Output()
-	if err != nil {
-		return "", err
+	if !tm.HasSession(server) {
+		return "", fmt.Errorf("session %s does not exist: %w", sessionName, ErrNotRunning)
 	}
 
-	return string(output), nil
+	return tm.backend.Logs(server, lines)
 }
 
 // ServerStatus represents the status of a single server
 type ServerStatus struct {
-	Server  int
-	Status  string // "running", "stopped"
-	Port    int
-	Session string
+	Server  int    `json:"server"`
+	Status  string `json:"status"` // "running", "stopped", "crashed", "crash-looping"
+	Port    int    `json:"port"`
+	Session string `json:"session"`
+	Patchline   string `json:"patchline"`              // "production" or "pre-release"
+	GameVersion string `json:"game_version,omitempty"` // versions/ directory the server runs ("" = master-install)
 }
@@ -0,0 +1,119 @@
+package hytale
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// tmuxBackend runs each server in a detached tmux session
+// Sessions live on the tmux server of the configured system user, so every
+// tmux command is run as that user
+type tmuxBackend struct {
+	user string
+}
+
+func newTmuxBackend(user string) *tmuxBackend {
+	return &tmuxBackend{user: user}
+}
+
+// tmux builds a tmux command that runs as the backend's system user
+func (tb *tmuxBackend) tmux(args ...string) *exec.Cmd {
+	return userCommand(tb.user, "tmux", args...)
+}
+
+// Name returns the backend name
+func (tb *tmuxBackend) Name() string {
+	return BackendTmux
+}
+
+// SessionName returns the tmux session name for a given server number
+func (tb *tmuxBackend) SessionName(server int) string {
+	return fmt.Sprintf("%s-%d", TmuxSessionPrefix, server)
+}
+
+// Start creates a detached tmux session running argv
+// argv runs under a small shell wrapper that records its exit status in ExitStatusFile,
+// since the session (and the exit status with it) disappears when the process ends
+func (tb *tmuxBackend) Start(server int, dataDir string, argv []string) error {
+	sessionName := tb.SessionName(server)
+
+	// The program and its arguments are passed as "$@" so nothing is re-split or re-quoted
+	wrapper := fmt.Sprintf(`"$@"; echo $? > %s`, ExitStatusFile)
+	tmuxArgs := append([]string{"new-session", "-d", "-s", sessionName, "-c", dataDir, "sh", "-c", wrapper, sessionName}, argv...)
+	cmd := tb.tmux(tmuxArgs...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create tmux session %s: %w\nOutput: %s", sessionName, err, strings.TrimSpace(string(output)))
+	}
+
+	// Mirror everything the pane prints into the persistent console log
+	// Best effort: the server still runs (with scrollback-only logs) if this fails
+	if hsm, err := hsmExecutable(); err == nil {
+		pipe := fmt.Sprintf("exec %s logpipe %d", shellQuote(hsm), server)
+		_ = tb.tmux("pipe-pane", "-o", "-t", sessionName, pipe).Run()
+	}
+	return nil
+}
+
+// Stop kills the server's tmux session if it is still around
+// (the session normally ends by itself once the JVM exits)
+func (tb *tmuxBackend) Stop(server int) error {
+	if !tb.IsRunning(server) {
+		return nil
+	}
+	return tb.Remove(server)
+}
+
+// IsRunning checks if the server's tmux session exists
+func (tb *tmuxBackend) IsRunning(server int) bool {
+	cmd := tb.tmux("has-session", "-t", tb.SessionName(server))
+	return cmd.Run() == nil
+}
+
+// MainPID returns the PID of the JVM running in the server's tmux pane
+func (tb *tmuxBackend) MainPID(server int) (int, error) {
+	output, err := tb.tmux("list-panes", "-t", tb.SessionName(server), "-F", "#{pane_pid}").Output()
+	if err != nil {
+		return 0, fmt.Errorf("session %s does not exist: %w", tb.SessionName(server), ErrNotRunning)
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("no pane found in session %s", tb.SessionName(server))
+	}
+	panePID, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid pane PID %q: %w", fields[0], err)
+	}
+	// The pane runs the exit status wrapper; the JVM is its child
+	if pid := findJavaPID(panePID); pid > 0 {
+		return pid, nil
+	}
+	return panePID, nil
+}
+
+// Logs reads the last N lines from the server's tmux pane
+func (tb *tmuxBackend) Logs(server int, lines int) (string, error) {
+	// Use tmux capture-pane to get output
+	cmd := tb.tmux("capture-pane", "-t", tb.SessionName(server), "-p", "-S", strconv.Itoa(-lines))
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// SendCommand types a command into the server's tmux pane and presses Enter
+func (tb *tmuxBackend) SendCommand(server int, command string) error {
+	// -l sends the text literally so words like "Enter" aren't treated as key names
+	if err := tb.tmux("send-keys", "-t", tb.SessionName(server), "-l", command).Run(); err != nil {
+		return fmt.Errorf("failed to send command to %s: %w", tb.SessionName(server), err)
+	}
+	return tb.tmux("send-keys", "-t", tb.SessionName(server), "C-m").Run()
+}
+
+// Remove kills the server's tmux session without asking the server to stop
+func (tb *tmuxBackend) Remove(server int) error {
+	cmd := tb.tmux("kill-session", "-t", tb.SessionName(server))
+	return cmd.Run()
+}
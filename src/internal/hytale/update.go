@@ -5,46 +5,71 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
-// UpdateGame downloads and updates the Hytale server files
-func UpdateGame(ctx context.Context) (string, error) {
-	masterDir := filepath.Join(DataDirBase, "master-install")
+// GameUpdateCheck compares the installed game version with the latest one hytale-downloader offers
+type GameUpdateCheck struct {
+	Patchline string `json:"patchline"`
+	Installed string `json:"installed"`
+	Latest    string `json:"latest"`
+}
 
-	// 1. Download latest server files to master-install using hytale-downloader
-	// Create a minimal bootstrap config for downloader (with empty OAuth fields for now)
-	// In the future, we could read saved credentials from config
-	cfg := BootstrapConfig{} // Empty config - downloader will use existing credentials file or environment
-	
-	// Try to download using hytale-downloader
-	downloader, err := NewHytaleDownloader(cfg)
+// Available reports whether the latest game version differs from the installed one
+func (c GameUpdateCheck) Available() bool {
+	// Installed may be a versions/ directory name rather than the reported version
+	return c.Latest != "" && c.Latest != c.Installed && sanitizeVersion(c.Latest) != c.Installed
+}
+
+// CheckGameUpdate asks hytale-downloader for the latest game version of the installation's
+// patchline without downloading it
+func CheckGameUpdate(ctx context.Context) (GameUpdateCheck, error) {
+	patchline := LoadManifestOrDefault().Patchline
+	if patchline == "" {
+		patchline = PatchlineProduction
+	}
+	downloader, err := NewHytaleDownloader(BootstrapConfig{})
 	if err != nil {
-		// hytale-downloader not available - check if files already exist
-		jarPath := filepath.Join(masterDir, "Server", "HytaleServer.jar")
-		if _, statErr := os.Stat(jarPath); os.IsNotExist(statErr) {
-			return "", fmt.Errorf("hytale-downloader not found and server files missing. %v. Please install hytale-downloader or copy server files manually to %s", err, masterDir)
-		}
-		// Files exist, use existing files
-	} else {
-		// Download latest files
-		if err := downloader.Download(ctx, masterDir, nil); err != nil {
-			return "", fmt.Errorf("failed to download server files: %w", err)
-		}
+		return GameUpdateCheck{}, err
 	}
-
-	// Verify master-install has server files
-	jarPath := filepath.Join(masterDir, "Server", "HytaleServer.jar")
-	if _, err := os.Stat(jarPath); os.IsNotExist(err) {
-		return "", fmt.Errorf("server files not found in master-install after download. Please check hytale-downloader output")
+	downloader.SetPatchline(patchline)
+	latest, err := downloader.PrintVersion(ctx)
+	if err != nil {
+		return GameUpdateCheck{}, err
 	}
+	return GameUpdateCheck{Patchline: patchline, Installed: InstalledPatchlineVersion(patchline), Latest: latest}, nil
+}
+
+// UpdateGame downloads and updates the Hytale server files
+// Each patchline in use is downloaded as its own game version (see versions.go) so it can be rolled
+// back, and each server is updated from its patchline's build. Nothing is downloaded or copied when
+// the installed game versions are already the latest.
+// Running servers are left on their version, since replacing their files can crash them;
+// RollingUpdateGame restarts them onto the new version.
+func UpdateGame(ctx context.Context) (string, error) {
+	return UpdateGameWithProgress(ctx, nil, nil)
+}
 
-	// 2. Update all server instances from master-install
+// UpdateGameWithProgress is UpdateGame reporting the download percentage to progress and
+// hytale-downloader's sign-in prompt to deviceCode (either may be nil)
+func UpdateGameWithProgress(ctx context.Context, progress ProgressCallback, deviceCode DeviceCodeCallback) (string, error) {
 	numServers := DetectNumServers()
 	if numServers == 0 {
-		return "", fmt.Errorf("no servers installed")
+		return "", ErrNotInstalled
 	}
 
-	updated := 0
+	manifest := LoadManifestOrDefault()
+	updates, err := downloadPatchlineUpdates(ctx, manifest, numServers, progress, deviceCode)
+	if err != nil {
+		return "", err
+	}
+
+	// 2. Update server instances from their patchline's master-install
+	// Servers on an up-to-date patchline keep their version (it may be a rollback), unless
+	// they were moved to another patchline since their last update
+	tm := NewTmuxManagerFromManifest(manifest)
+	updatedTo := make(map[string][]int)
+	var running []int
 	for i := 1; i <= numServers; i++ {
 		select {
 		case <-ctx.Done():
@@ -52,6 +77,15 @@ func UpdateGame(ctx context.Context) (string, error) {
 		default:
 		}
 
+		update := updates[ServerPatchline(manifest, i)]
+		if manifest.ServerGameVersions[i] == update.version || update.upToDate && onServerPatchline(manifest, i) {
+			continue
+		}
+		if tm.HasSession(i) {
+			running = append(running, i)
+			continue
+		}
+
 		// Copy master-install to server (preserves universe/ and logs/)
 		if err := CopyMasterToServer(ctx, i); err != nil {
 			return "", fmt.Errorf("failed to update server %d: %w", i, err)
@@ -63,10 +97,128 @@ func UpdateGame(ctx context.Context) (string, error) {
 		}
 
 		// Note: config.json is NOT overwritten (server-specific settings preserved)
-		updated++
+		updatedTo[update.version] = append(updatedTo[update.version], i)
+	}
+
+	if len(updatedTo) == 0 && len(running) == 0 {
+		return fmt.Sprintf("Game is up to date (version %s)", updates.versions()), nil
+	}
+
+	updated := 0
+	for version, servers := range updatedTo {
+		if err := RecordServerGameVersions(servers, version); err != nil {
+			return "", fmt.Errorf("failed to record game versions: %w", err)
+		}
+		updated += len(servers)
+	}
+
+	// Files copied by HSM are owned by root - hand them back to the system user
+	if err := FixInstallOwnership(); err != nil {
+		return "", fmt.Errorf("failed to set ownership: %w", err)
+	}
+
+	result := fmt.Sprintf("Updated %d server(s) to game version %s", updated, updates.versions())
+	if len(running) > 0 {
+		result += fmt.Sprintf("\n%d running server(s) kept their version; use a rolling update (hsm update game --rolling) to restart them onto it", len(running))
 	}
+	return result, nil
+}
+
+// patchlineUpdate is the outcome of downloading one patchline's latest game version
+type patchlineUpdate struct {
+	version  string // versions/ directory of the patchline's latest build
+	upToDate bool   // Nothing was downloaded
+}
 
-	return fmt.Sprintf("Updated %d server(s) from master-install", updated), nil
+// patchlineUpdates maps patchlines to their downloaded updates
+type patchlineUpdates map[string]patchlineUpdate
+
+// versions lists the downloaded game versions, production first
+func (u patchlineUpdates) versions() string {
+	var versions []string
+	for _, patchline := range Patchlines {
+		if update, ok := u[patchline]; ok {
+			versions = append(versions, update.version)
+		}
+	}
+	return strings.Join(versions, ", ")
+}
+
+// downloadPatchlineUpdates downloads the latest game version of every patchline followed by a
+// server or by the installation
+func downloadPatchlineUpdates(ctx context.Context, manifest *Manifest, numServers int, progress ProgressCallback, deviceCode DeviceCodeCallback) (patchlineUpdates, error) {
+	inUse := map[string]bool{manifest.Patchline: true}
+	for server := 1; server <= numServers; server++ {
+		inUse[ServerPatchline(manifest, server)] = true
+	}
+
+	updates := make(patchlineUpdates)
+	for _, patchline := range Patchlines {
+		if !inUse[patchline] {
+			continue
+		}
+		version, upToDate, err := downloadGameUpdate(ctx, patchline, progress, deviceCode)
+		if err != nil {
+			if patchline != PatchlineProduction {
+				err = fmt.Errorf("%s: %w", patchline, err)
+			}
+			return nil, err
+		}
+		updates[patchline] = patchlineUpdate{version: version, upToDate: upToDate}
+	}
+	return updates, nil
+}
+
+// downloadGameUpdate downloads a patchline's latest game version into versions/ and points the
+// patchline's master-install at it
+// upToDate is true (and nothing is downloaded) when the installed version is already the latest
+func downloadGameUpdate(ctx context.Context, patchline string, progress ProgressCallback, deviceCode DeviceCodeCallback) (version string, upToDate bool, err error) {
+	masterDir := getPatchlineMasterLink(patchline)
+
+	// 1. Download latest server files to versions/<game-version> using hytale-downloader
+	// Create a minimal bootstrap config for downloader (with empty OAuth fields for now)
+	// In the future, we could read saved credentials from config
+	cfg := BootstrapConfig{} // Empty config - downloader will use existing credentials file or environment
+	
+	// Try to download using hytale-downloader
+	downloader, err := NewHytaleDownloader(cfg)
+	if err != nil {
+		// hytale-downloader not available - check if files already exist
+		jarPath := filepath.Join(masterDir, "Server", "HytaleServer.jar")
+		if _, statErr := os.Stat(jarPath); os.IsNotExist(statErr) {
+			return "", false, fmt.Errorf("hytale-downloader not found and server files missing. %v. Please install hytale-downloader or copy server files manually to %s", err, masterDir)
+		}
+		// Files exist, use existing files (moved into versions/ if they were copied in by hand)
+		if _, err = EnsureVersionedInstall(); err != nil {
+			return "", false, err
+		}
+		version = ActivePatchlineVersion(patchline)
+	} else {
+		// Skip the download when the installed game version is already the latest
+		// If the check fails, download anyway like before (the version is then read from the JAR)
+		downloader.SetPatchline(patchline)
+		downloader.SetDeviceCodeCallback(deviceCode)
+		latest, checkErr := downloader.PrintVersion(ctx)
+		check := GameUpdateCheck{Patchline: patchline, Installed: InstalledPatchlineVersion(patchline), Latest: latest}
+		if checkErr == nil && !check.Available() && fileExists(filepath.Join(masterDir, "Server", "HytaleServer.jar")) {
+			return ActivePatchlineVersion(patchline), true, nil
+		}
+
+		// Download latest files
+		version, err = InstallGameVersion(ctx, patchline, latest, func(dir string) error {
+			return downloader.Download(ctx, dir, progress)
+		})
+		if err != nil {
+			return "", false, fmt.Errorf("failed to download server files: %w", err)
+		}
+	}
+
+	// Verify master-install has server files
+	jarPath := filepath.Join(masterDir, "Server", "HytaleServer.jar")
+	if _, err := os.Stat(jarPath); os.IsNotExist(err) {
+		return "", false, fmt.Errorf("server files not found in %s after download. Please check hytale-downloader output", filepath.Base(masterDir))
+	}
+	return version, false, nil
 }
 
 // UpdatePlugins updates server plugins and addons
@@ -111,5 +263,10 @@ func UpdatePlugins(ctx context.Context) (string, error) {
 		updatedCount++
 	}
 
+	// Files copied by HSM are owned by root - hand them back to the system user
+	if err := FixInstallOwnership(); err != nil {
+		return "", fmt.Errorf("failed to set ownership: %w", err)
+	}
+
 	return fmt.Sprintf("Plugins updated: %d/%d servers updated", updatedCount, numServers), nil
 }
@@ -0,0 +1,181 @@
+package hytale
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"strconv"
+)
+
+// validUsername matches names accepted by useradd on common distributions
+var validUsername = regexp.MustCompile(`^[a-z_][a-z0-9_-]{0,31}$`)
+
+// SystemUserExists checks if a system user exists
+func SystemUserExists(username string) bool {
+	_, err := user.Lookup(username)
+	return err == nil
+}
+
+// EnsureSystemUser creates the unprivileged system user servers run as (if it doesn't exist)
+// The user gets no login shell and DataDirBase as its home directory
+func EnsureSystemUser(username string) error {
+	if !validUsername.MatchString(username) {
+		return fmt.Errorf("invalid system user name %q (use lowercase letters, digits, '-' and '_')", username)
+	}
+	if SystemUserExists(username) {
+		return nil
+	}
+	if os.Geteuid() != 0 {
+		return fmt.Errorf("creating system user %s requires root (run with sudo)", username)
+	}
+
+	shell := "/usr/sbin/nologin"
+	if _, err := os.Stat(shell); os.IsNotExist(err) {
+		shell = "/bin/false"
+	}
+
+	cmd := exec.Command("useradd",
+		"--system",
+		"--user-group",
+		"--no-create-home",
+		"--home-dir", DataDirBase,
+		"--shell", shell,
+		username,
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create system user %s: %w\nOutput: %s", username, err, string(output))
+	}
+
+	return nil
+}
+
+// lookupIDs returns the numeric uid and gid of a system user
+func lookupIDs(username string) (int, int, error) {
+	u, err := user.Lookup(username)
+	if err != nil {
+		return 0, 0, fmt.Errorf("system user %s not found: %w", username, err)
+	}
+	uid, err := strconv.Atoi(u.Uid)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid uid for %s: %w", username, err)
+	}
+	gid, err := strconv.Atoi(u.Gid)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid gid for %s: %w", username, err)
+	}
+	return uid, gid, nil
+}
+
+// chownTree recursively hands a directory to uid:gid and makes directories 0750
+// Symlinks are re-owned without following them
+func chownTree(root string, uid, gid int) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := os.Lchown(path, uid, gid); err != nil {
+			return fmt.Errorf("failed to chown %s: %w", path, err)
+		}
+		if info.IsDir() {
+			if err := os.Chmod(path, 0750); err != nil {
+				return fmt.Errorf("failed to chmod %s: %w", path, err)
+			}
+		}
+		return nil
+	})
+}
+
+// ApplyServerOwnership hands a single server directory to the system user
+func ApplyServerOwnership(username string, serverNum int) error {
+	uid, gid, err := lookupIDs(username)
+	if err != nil {
+		return err
+	}
+	return chownTree(GetServerDir(serverNum), uid, gid)
+}
+
+// ApplyInstallOwnership hands the game versions, shared and every server-N directory to the system user
+// DataDirBase itself stays root-owned (0755) so the user can't replace the directory layout
+func ApplyInstallOwnership(username string) error {
+	uid, gid, err := lookupIDs(username)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Chmod(DataDirBase, 0755); err != nil {
+		return fmt.Errorf("failed to chmod %s: %w", DataDirBase, err)
+	}
+
+	dirs := []string{
+		GetMasterInstallDir(),
+		GetVersionsDir(),
+		GetSharedConfigDir(),
+	}
+	for i := 1; i <= DetectNumServers(); i++ {
+		dirs = append(dirs, GetServerDir(i))
+	}
+
+	for _, dir := range dirs {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			continue
+		}
+		if err := chownTree(dir, uid, gid); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// EnsureUserDir creates a directory inside a server or shared directory, owned by the system user
+// so the server can write to it even when HSM (as root) created it
+func EnsureUserDir(path string, username string) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(path, 0750); err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if os.Geteuid() != 0 || !SystemUserExists(username) {
+		return nil
+	}
+	uid, gid, err := lookupIDs(username)
+	if err != nil {
+		return err
+	}
+	if err := os.Lchown(path, uid, gid); err != nil {
+		return fmt.Errorf("failed to chown %s: %w", path, err)
+	}
+	return nil
+}
+
+// FixInstallOwnership re-applies ownership after HSM (running as root) wrote new files
+// Installations whose system user was never created are left untouched
+func FixInstallOwnership() error {
+	manifest := LoadManifestOrDefault()
+	if os.Geteuid() != 0 || !SystemUserExists(manifest.HytaleUser) {
+		return nil
+	}
+	return ApplyInstallOwnership(manifest.HytaleUser)
+}
+
+// userCommand builds a command that runs as username
+// Falls back to the current user if username is empty, is the current user, or doesn't exist
+// (installations made before HSM created the system user)
+func userCommand(username string, name string, args ...string) *exec.Cmd {
+	if username == "" || !SystemUserExists(username) {
+		return exec.Command(name, args...)
+	}
+	if current, err := user.Current(); err == nil && current.Username == username {
+		return exec.Command(name, args...)
+	}
+
+	if os.Geteuid() == 0 {
+		return exec.Command("runuser", append([]string{"-u", username, "--", name}, args...)...)
+	}
+	// Not root - sudo may still be allowed to switch user without a password
+	return exec.Command("sudo", append([]string{"-n", "-u", username, "--", name}, args...)...)
+}
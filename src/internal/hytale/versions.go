@@ -0,0 +1,569 @@
+package hytale
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Game builds are kept side by side in versions/<game-version>/ and master-install is a
+// symlink to the active one, so a bad update can be rolled back without downloading again.
+// Each server runs its own copy of a build; the manifest records which one.
+//
+// Builds from patchlines other than production are named <patchline>-<game-version> and have
+// their own master symlink (master-install-<patchline>), so canary servers can run pre-release
+// builds next to production ones.
+
+const (
+	// PatchlineProduction is the hytale-downloader patchline of stable releases
+	PatchlineProduction = "production"
+	// PatchlinePreRelease is the hytale-downloader patchline of upcoming releases
+	PatchlinePreRelease = "pre-release"
+)
+
+// Patchlines lists the patchlines servers can follow
+var Patchlines = []string{PatchlineProduction, PatchlinePreRelease}
+
+// ValidPatchline reports whether a patchline is one of Patchlines
+func ValidPatchline(patchline string) bool {
+	for _, p := range Patchlines {
+		if p == patchline {
+			return true
+		}
+	}
+	return false
+}
+
+// ServerPatchline returns the patchline a server follows: its own, or the installation's
+func ServerPatchline(manifest *Manifest, server int) string {
+	if patchline := manifest.ServerPatchlines[server]; patchline != "" {
+		return patchline
+	}
+	if manifest.Patchline != "" {
+		return manifest.Patchline
+	}
+	return PatchlineProduction
+}
+
+// versionDirName returns the versions/ directory name of a patchline's game version
+func versionDirName(patchline, version string) string {
+	version = sanitizeVersion(version)
+	if patchline == PatchlineProduction || version == "" {
+		return version
+	}
+	return patchline + "-" + version
+}
+
+// VersionPatchline returns the patchline a versions/ directory was downloaded from
+func VersionPatchline(dirName string) string {
+	for _, patchline := range Patchlines {
+		if patchline != PatchlineProduction && strings.HasPrefix(dirName, patchline+"-") {
+			return patchline
+		}
+	}
+	return PatchlineProduction
+}
+
+// GetVersionsDir returns the directory holding one directory per downloaded game build
+func GetVersionsDir() string {
+	return filepath.Join(DataDirBase, "versions")
+}
+
+// GetVersionDir returns the directory of a downloaded game build
+func GetVersionDir(version string) string {
+	return filepath.Join(GetVersionsDir(), version)
+}
+
+// getMasterInstallLink returns the path of master-install itself (a symlink on versioned installs)
+func getMasterInstallLink() string {
+	return getPatchlineMasterLink(PatchlineProduction)
+}
+
+// getPatchlineMasterLink returns the symlink to a patchline's active build
+func getPatchlineMasterLink(patchline string) string {
+	if patchline == PatchlineProduction {
+		return filepath.Join(DataDirBase, "master-install")
+	}
+	return filepath.Join(DataDirBase, "master-install-"+patchline)
+}
+
+// GetMasterInstallDir returns the directory master-install points to
+// Directory walks don't descend into a symlink, so callers copying or hashing master-install use this
+func GetMasterInstallDir() string {
+	return GetPatchlineMasterDir(PatchlineProduction)
+}
+
+// GetPatchlineMasterDir returns the directory of a patchline's active build
+func GetPatchlineMasterDir(patchline string) string {
+	link := getPatchlineMasterLink(patchline)
+	if resolved, err := filepath.EvalSymlinks(link); err == nil {
+		return resolved
+	}
+	return link
+}
+
+// GameVersion is a downloaded game build
+type GameVersion struct {
+	Version     string    `json:"version"`
+	Dir         string    `json:"dir"`
+	Patchline   string    `json:"patchline"`
+	InstalledAt time.Time `json:"installed_at"`
+	Active      bool      `json:"active"`  // The patchline's master-install points to it
+	Servers     []int     `json:"servers"` // Servers running this build
+}
+
+// gameJarPath returns the server JAR inside a game directory
+func gameJarPath(dir string) string {
+	jarPath := filepath.Join(dir, "Server", "HytaleServer.jar")
+	if _, err := os.Stat(jarPath); os.IsNotExist(err) {
+		// hytale-downloader may extract the JAR to the root
+		if rootJar := filepath.Join(dir, "HytaleServer.jar"); fileExists(rootJar) {
+			return rootJar
+		}
+	}
+	return jarPath
+}
+
+// DetectGameVersion reads the game version of the build in dir
+// It comes from Implementation-Version in the JAR's manifest; builds without one are named after their hash
+func DetectGameVersion(dir string) (string, error) {
+	jarPath := gameJarPath(dir)
+	if version := jarImplementationVersion(jarPath); version != "" {
+		return version, nil
+	}
+	hash, err := hashFile(jarPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read server JAR: %w", err)
+	}
+	return "build-" + shortHash(hash), nil
+}
+
+// jarImplementationVersion returns the sanitized Implementation-Version of a JAR, or "" if it has none
+func jarImplementationVersion(jarPath string) string {
+	reader, err := zip.OpenReader(jarPath)
+	if err != nil {
+		return ""
+	}
+	defer reader.Close()
+
+	for _, file := range reader.File {
+		if file.Name != "META-INF/MANIFEST.MF" {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return ""
+		}
+		defer rc.Close()
+		scanner := bufio.NewScanner(rc)
+		for scanner.Scan() {
+			if value := strings.TrimPrefix(scanner.Text(), "Implementation-Version:"); value != scanner.Text() {
+				return sanitizeVersion(value)
+			}
+		}
+	}
+	return ""
+}
+
+// sanitizeVersion makes a version safe to use as a directory name
+func sanitizeVersion(version string) string {
+	version = strings.TrimSpace(version)
+	var b strings.Builder
+	for _, r := range version {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_', r == '+':
+			b.WriteRune(r)
+		case !strings.HasSuffix(b.String(), "-"):
+			b.WriteRune('-')
+		}
+	}
+	return strings.Trim(b.String(), ".-")
+}
+
+// ActiveGameVersion returns the build master-install points to, or "" before the first versioned install
+func ActiveGameVersion() string {
+	return ActivePatchlineVersion(PatchlineProduction)
+}
+
+// ActivePatchlineVersion returns the build a patchline's master symlink points to, or "" if it has none
+func ActivePatchlineVersion(patchline string) string {
+	target, err := os.Readlink(getPatchlineMasterLink(patchline))
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(target)
+}
+
+// ListGameVersions lists the downloaded game builds, newest first
+func ListGameVersions() ([]GameVersion, error) {
+	entries, err := os.ReadDir(GetVersionsDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read versions directory: %w", err)
+	}
+
+	manifest := LoadManifestOrDefault()
+	var versions []GameVersion
+	for _, entry := range entries {
+		// Dot directories are downloads in progress
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		patchline := VersionPatchline(entry.Name())
+		version := GameVersion{
+			Version:     entry.Name(),
+			Dir:         GetVersionDir(entry.Name()),
+			Patchline:   patchline,
+			InstalledAt: info.ModTime(),
+			Active:      entry.Name() == ActivePatchlineVersion(patchline),
+			Servers:     []int{},
+		}
+		for server := 1; server <= DetectNumServers(); server++ {
+			if manifest.ServerGameVersions[server] == version.Version {
+				version.Servers = append(version.Servers, server)
+			}
+		}
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].InstalledAt.After(versions[j].InstalledAt)
+	})
+	return versions, nil
+}
+
+// PreviousGameVersion returns the newest build of a patchline older than its active one
+func PreviousGameVersion(patchline string) (string, error) {
+	versions, err := ListGameVersions()
+	if err != nil {
+		return "", err
+	}
+	seenActive := false
+	for _, version := range versions {
+		if version.Patchline != patchline {
+			continue
+		}
+		if seenActive {
+			return version.Version, nil
+		}
+		seenActive = version.Active
+	}
+	return "", fmt.Errorf("no previous %s game version to roll back to", patchline)
+}
+
+// EnsureVersionedInstall moves a plain master-install directory (from older HSM versions or
+// copied in by hand) into versions/ and replaces it with a symlink
+// Returns the active version, or "" when there is no game build yet
+func EnsureVersionedInstall() (string, error) {
+	link := getMasterInstallLink()
+	info, err := os.Lstat(link)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read master-install: %w", err)
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		return ActiveGameVersion(), nil
+	}
+
+	if !fileExists(gameJarPath(link)) {
+		// An empty master-install left by the setup wizard; a directory with other files is left alone
+		if err := os.Remove(link); err != nil {
+			return "", fmt.Errorf("master-install has no server JAR and isn't empty: %w", err)
+		}
+		return "", nil
+	}
+
+	version, err := DetectGameVersion(link)
+	if err != nil {
+		return "", err
+	}
+	if fileExists(GetVersionDir(version)) {
+		return "", fmt.Errorf("master-install holds game version %s, but %s already exists", version, GetVersionDir(version))
+	}
+	if err := os.MkdirAll(GetVersionsDir(), 0755); err != nil {
+		return "", fmt.Errorf("failed to create versions directory: %w", err)
+	}
+	if err := os.Rename(link, GetVersionDir(version)); err != nil {
+		return "", fmt.Errorf("failed to move master-install to %s: %w", GetVersionDir(version), err)
+	}
+	if err := pointMasterInstall(version); err != nil {
+		return "", err
+	}
+	return version, nil
+}
+
+// InstallGameVersion downloads a patchline's game build into its own versions/ directory, activates
+// it and records it as the patchline's installed game version
+// version is what hytale-downloader reported ("" detects it from the JAR); download fills the
+// directory it's given, and a build that is already kept is reused
+func InstallGameVersion(ctx context.Context, patchline, version string, download func(dir string) error) (string, error) {
+	if _, err := EnsureVersionedInstall(); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(GetVersionsDir(), 0755); err != nil {
+		return "", fmt.Errorf("failed to create versions directory: %w", err)
+	}
+
+	stagingDir := filepath.Join(GetVersionsDir(), fmt.Sprintf(".download-%d", time.Now().UnixNano()))
+	defer os.RemoveAll(stagingDir)
+	if err := download(stagingDir); err != nil {
+		return "", err
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	if sanitizeVersion(version) == "" {
+		var err error
+		if version, err = DetectGameVersion(stagingDir); err != nil {
+			return "", err
+		}
+	}
+	dirName := versionDirName(patchline, version)
+	if !fileExists(GetVersionDir(dirName)) {
+		if err := os.Rename(stagingDir, GetVersionDir(dirName)); err != nil {
+			return "", fmt.Errorf("failed to keep game version %s: %w", dirName, err)
+		}
+	}
+	if err := ActivateGameVersion(dirName); err != nil {
+		return "", err
+	}
+
+	manifest, err := LoadManifest()
+	if err != nil {
+		return "", err
+	}
+	if patchline == PatchlineProduction {
+		manifest.GameVersion = version
+	} else {
+		if manifest.PatchlineGameVersions == nil {
+			manifest.PatchlineGameVersions = make(map[string]string)
+		}
+		manifest.PatchlineGameVersions[patchline] = version
+	}
+	if err := SaveManifest(manifest); err != nil {
+		return "", fmt.Errorf("failed to record game version: %w", err)
+	}
+	return dirName, nil
+}
+
+// InstalledGameVersion returns the production game version last downloaded
+func InstalledGameVersion() string {
+	return InstalledPatchlineVersion(PatchlineProduction)
+}
+
+// InstalledPatchlineVersion returns the game version last downloaded from a patchline
+// Installations that haven't downloaded since the manifest recorded it fall back to the active build
+func InstalledPatchlineVersion(patchline string) string {
+	manifest := LoadManifestOrDefault()
+	version := manifest.PatchlineGameVersions[patchline]
+	if patchline == PatchlineProduction {
+		version = manifest.GameVersion
+	}
+	if version != "" {
+		return version
+	}
+	return strings.TrimPrefix(ActivePatchlineVersion(patchline), patchline+"-")
+}
+
+// ActivateGameVersion points the master symlink of a build's patchline at it
+// Servers keep running their own copy until they are updated or rolled back
+func ActivateGameVersion(version string) error {
+	if !fileExists(gameJarPath(GetVersionDir(version))) {
+		return fmt.Errorf("game version %q is not installed", version)
+	}
+	if _, err := EnsureVersionedInstall(); err != nil {
+		return err
+	}
+	return pointMasterInstall(version)
+}
+
+// pointMasterInstall swaps the master symlink of a build's patchline atomically
+func pointMasterInstall(version string) error {
+	link := getPatchlineMasterLink(VersionPatchline(version))
+	tmpLink := link + ".tmp"
+	os.Remove(tmpLink)
+	if err := os.Symlink(filepath.Join("versions", version), tmpLink); err != nil {
+		return fmt.Errorf("failed to link %s: %w", filepath.Base(link), err)
+	}
+	if err := os.Rename(tmpLink, link); err != nil {
+		os.Remove(tmpLink)
+		return fmt.Errorf("failed to link %s: %w", filepath.Base(link), err)
+	}
+	return nil
+}
+
+// ServerGameDir returns the game build a server was installed from
+// Servers installed before builds were versioned follow their patchline's master-install
+func ServerGameDir(manifest *Manifest, server int) string {
+	if version := manifest.ServerGameVersions[server]; version != "" && fileExists(GetVersionDir(version)) {
+		return GetVersionDir(version)
+	}
+	return GetPatchlineMasterDir(ServerPatchline(manifest, server))
+}
+
+// onServerPatchline reports whether a server runs a build of the patchline it follows
+// Unrecorded servers run master-install, which is production
+func onServerPatchline(manifest *Manifest, server int) bool {
+	version := manifest.ServerGameVersions[server]
+	if version == "" {
+		return ServerPatchline(manifest, server) == PatchlineProduction
+	}
+	return VersionPatchline(version) == ServerPatchline(manifest, server)
+}
+
+// SetServerPatchlines makes servers follow a patchline; "" makes them follow the installation's
+// The servers keep their game files until the next game update or rollback
+func SetServerPatchlines(servers []int, patchline string) error {
+	if patchline != "" && !ValidPatchline(patchline) {
+		return fmt.Errorf("unknown patchline %q (expected %s)", patchline, strings.Join(Patchlines, " or "))
+	}
+	manifest, err := LoadManifest()
+	if err != nil {
+		return err
+	}
+	if manifest.ServerPatchlines == nil {
+		manifest.ServerPatchlines = make(map[int]string)
+	}
+	for _, server := range servers {
+		if patchline == "" {
+			delete(manifest.ServerPatchlines, server)
+		} else {
+			manifest.ServerPatchlines[server] = patchline
+		}
+	}
+	return SaveManifest(manifest)
+}
+
+// SetInstallationPatchline sets the patchline followed by servers without one of their own
+func SetInstallationPatchline(patchline string) error {
+	if !ValidPatchline(patchline) {
+		return fmt.Errorf("unknown patchline %q (expected %s)", patchline, strings.Join(Patchlines, " or "))
+	}
+	manifest, err := LoadManifest()
+	if err != nil {
+		return err
+	}
+	manifest.Patchline = patchline
+	return SaveManifest(manifest)
+}
+
+// CopyVersionToServer copies a game build to a server instance, like CopyMasterToServer
+func CopyVersionToServer(ctx context.Context, serverNum int, version string) error {
+	return CopyDir(ctx, GetVersionDir(version), GetServerDir(serverNum), masterCopyExcludes)
+}
+
+// RecordServerGameVersions records in the manifest which game build the servers run
+func RecordServerGameVersions(servers []int, version string) error {
+	if version == "" || len(servers) == 0 {
+		return nil
+	}
+	manifest, err := LoadManifest()
+	if err != nil {
+		return err
+	}
+	if manifest.ServerGameVersions == nil {
+		manifest.ServerGameVersions = make(map[int]string)
+	}
+	for _, server := range servers {
+		manifest.ServerGameVersions[server] = version
+	}
+	return SaveManifest(manifest)
+}
+
+// RollbackGame switches servers to a downloaded game build, restarting the ones that were running
+// An empty version picks the build before the active one of the servers' patchline. Rolling back
+// every server following the build's patchline also points its master symlink at the build, so
+// servers added later use it too.
+func (tm *TmuxManager) RollbackGame(ctx context.Context, servers []int, version string) ([]ServerActionResult, error) {
+	manifest := LoadManifestOrDefault()
+	if version == "" {
+		patchline := ""
+		for _, server := range servers {
+			if patchline != "" && ServerPatchline(manifest, server) != patchline {
+				return nil, fmt.Errorf("the servers follow different patchlines - name the game version to roll back to")
+			}
+			patchline = ServerPatchline(manifest, server)
+		}
+		previous, err := PreviousGameVersion(patchline)
+		if err != nil {
+			return nil, err
+		}
+		version = previous
+	}
+	if !fileExists(gameJarPath(GetVersionDir(version))) {
+		return nil, fmt.Errorf("game version %q is not installed", version)
+	}
+	if coversPatchline(manifest, servers, VersionPatchline(version)) {
+		if err := ActivateGameVersion(version); err != nil {
+			return nil, err
+		}
+	}
+
+	settings := LoadLaunchSettings(manifest)
+	var mu sync.Mutex
+	var switched []int
+	results := RunOnServers(servers, func(server int) (string, error) {
+		wasRunning := tm.HasSession(server)
+		if wasRunning {
+			if _, err := tm.StopWithContext(ctx, server); err != nil {
+				return "", fmt.Errorf("failed to stop: %w", err)
+			}
+		}
+		if err := CopyVersionToServer(ctx, server, version); err != nil {
+			return "", fmt.Errorf("failed to copy game files: %w", err)
+		}
+		if err := CopySharedToServer(ctx, server); err != nil {
+			return "", fmt.Errorf("failed to copy shared configs: %w", err)
+		}
+		// The server runs the build from here on, even if it fails to start again
+		mu.Lock()
+		switched = append(switched, server)
+		mu.Unlock()
+		if os.Geteuid() == 0 && SystemUserExists(manifest.HytaleUser) {
+			if err := ApplyServerOwnership(manifest.HytaleUser, server); err != nil {
+				return "", err
+			}
+		}
+		if !wasRunning {
+			return "switched to " + version, nil
+		}
+		if err := tm.StartServer(server, settings); err != nil {
+			return "", fmt.Errorf("switched to %s but failed to start: %w", version, err)
+		}
+		return "switched to " + version + " and restarted", nil
+	})
+
+	if err := RecordServerGameVersions(switched, version); err != nil {
+		return results, fmt.Errorf("failed to record server game versions: %w", err)
+	}
+	return results, nil
+}
+
+// coversPatchline reports whether servers include every server following a patchline
+func coversPatchline(manifest *Manifest, servers []int, patchline string) bool {
+	targeted := make(map[int]bool, len(servers))
+	for _, server := range servers {
+		targeted[server] = true
+	}
+	for server := 1; server <= DetectNumServers(); server++ {
+		if ServerPatchline(manifest, server) == patchline && !targeted[server] {
+			return false
+		}
+	}
+	return len(servers) > 0
+}
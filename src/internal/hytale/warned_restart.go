@@ -0,0 +1,175 @@
+package hytale
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WarnedRestartOptions configures a restart with an in-game countdown
+type WarnedRestartOptions struct {
+	Warnings      []time.Duration // Time left at each broadcast, longest first
+	Command       string          // Console command broadcast at each step; {time} is replaced with the time left
+	CancelCommand string          // Console command broadcast if the restart is cancelled
+	Rolling       bool            // Restart servers one after another instead of together
+
+	// Progress, if set, receives a line for each step of the restart
+	Progress func(server int, message string)
+}
+
+// DefaultWarnedRestartOptions returns the default countdown and broadcast commands
+func DefaultWarnedRestartOptions() WarnedRestartOptions {
+	warnings, _ := ParseRestartWarnings(DefaultRestartWarnings)
+	return WarnedRestartOptions{
+		Warnings:      warnings,
+		Command:       DefaultRestartWarningCommand,
+		CancelCommand: DefaultRestartCancelCommand,
+	}
+}
+
+// ParseRestartWarnings parses a countdown such as "15m,5m,1m,30s,10s" (sorted longest first)
+func ParseRestartWarnings(spec string) ([]time.Duration, error) {
+	seen := make(map[time.Duration]bool)
+	var warnings []time.Duration
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		d, err := time.ParseDuration(part)
+		if err != nil || d < time.Second {
+			return nil, fmt.Errorf("invalid restart warning %q (expected a duration such as 5m or 30s)", part)
+		}
+		if !seen[d] {
+			seen[d] = true
+			warnings = append(warnings, d)
+		}
+	}
+	if len(warnings) == 0 {
+		return nil, fmt.Errorf("no restart warnings given")
+	}
+	sort.Slice(warnings, func(i, j int) bool { return warnings[i] > warnings[j] })
+	return warnings, nil
+}
+
+// FormatCountdown renders the time left for a broadcast, e.g. "15 minutes" or "30 seconds"
+func FormatCountdown(d time.Duration) string {
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s", unit)
+		}
+		return fmt.Sprintf("%d %ss", n, unit)
+	}
+	switch {
+	case d >= time.Hour && d%time.Hour == 0:
+		return plural(int(d/time.Hour), "hour")
+	case d >= time.Minute && d%time.Minute == 0:
+		return plural(int(d/time.Minute), "minute")
+	default:
+		return plural(int(d.Round(time.Second)/time.Second), "second")
+	}
+}
+
+func (o WarnedRestartOptions) progress(server int, format string, args ...interface{}) {
+	if o.Progress != nil {
+		o.Progress(server, fmt.Sprintf(format, args...))
+	}
+}
+
+// WarnedRestart counts down on a server's console, then saves, stops and starts it
+// Cancelling ctx during the countdown broadcasts the cancellation and leaves the server running.
+// A server that isn't running has nobody to warn and is started straight away.
+func (tm *TmuxManager) WarnedRestart(ctx context.Context, server int, opts WarnedRestartOptions) error {
+	settings := LoadLaunchSettings(LoadManifestOrDefault())
+	if !tm.HasSession(server) {
+		opts.progress(server, "Not running, starting")
+		return tm.StartServer(server, settings)
+	}
+
+	if err := tm.restartCountdown(ctx, server, opts); err != nil {
+		if opts.CancelCommand != "" && tm.HasSession(server) {
+			_ = tm.backend.SendCommand(server, opts.CancelCommand)
+		}
+		opts.progress(server, "Restart cancelled")
+		return err
+	}
+
+	if tm.HasSession(server) {
+		config, err := ReadBackupConfig()
+		if err != nil {
+			config = DefaultBackupConfig()
+		}
+		opts.progress(server, "Saving the world")
+		if !tm.saveWorld(server, config) {
+			opts.progress(server, "Save not confirmed on the console, stopping anyway")
+		}
+	}
+	opts.progress(server, "Restarting")
+	if err := tm.RestartServer(server, settings); err != nil {
+		return err
+	}
+	opts.progress(server, "Restarted")
+	return nil
+}
+
+// restartCountdown broadcasts each warning when that much time is left before the restart
+func (tm *TmuxManager) restartCountdown(ctx context.Context, server int, opts WarnedRestartOptions) error {
+	if len(opts.Warnings) == 0 {
+		return nil
+	}
+	restartAt := time.Now().Add(opts.Warnings[0])
+	wait := func(until time.Time) error {
+		timer := time.NewTimer(time.Until(until))
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			return nil
+		}
+	}
+
+	for _, left := range opts.Warnings {
+		if err := wait(restartAt.Add(-left)); err != nil {
+			return err
+		}
+		if !tm.HasSession(server) {
+			// Stopped during the countdown (e.g. from the console); the restart starts it again
+			return nil
+		}
+		if opts.Command != "" {
+			_ = tm.backend.SendCommand(server, strings.ReplaceAll(opts.Command, "{time}", FormatCountdown(left)))
+		}
+		opts.progress(server, "Restarting in %s", FormatCountdown(left))
+	}
+	return wait(restartAt)
+}
+
+// WarnedRestartServers runs a warned restart on several servers, together or rolling one after another
+// A rolling restart stops at the first failure so a broken update doesn't take down every server
+func (tm *TmuxManager) WarnedRestartServers(ctx context.Context, servers []int, opts WarnedRestartOptions) []ServerActionResult {
+	if !opts.Rolling {
+		return RunOnServers(servers, func(server int) (string, error) {
+			return "restarted", tm.WarnedRestart(ctx, server, opts)
+		})
+	}
+
+	results := make([]ServerActionResult, len(servers))
+	failed := 0
+	for i, server := range servers {
+		results[i].Server = server
+		if failed != 0 {
+			results[i].Err = fmt.Errorf("not restarted: server %d failed", failed)
+			continue
+		}
+		if err := tm.WarnedRestart(ctx, server, opts); err != nil {
+			results[i].Err = err
+			failed = server
+			continue
+		}
+		results[i].Detail = "restarted"
+	}
+	return results
+}
@@ -10,20 +10,24 @@ import (
 // WipeEverything permanently deletes all Hytale server data, configs, and system user
 // WARNING: This is irreversible!
 func WipeEverything(ctx context.Context) (string, error) {
+	// Read the manifest before anything is deleted (it lives in ConfigDir)
+	manifest := LoadManifestOrDefault()
+
 	// 1. Stop all running servers and kill all tmux sessions
 	numServers := DetectNumServers()
 	if numServers > 0 {
-		tm := NewTmuxManager(DefaultBasePort)
-		// Stop all servers (sends /stop, then kills sessions)
-		_ = tm.StopAll(numServers)
+		tm := NewTmuxManagerFromManifest(manifest)
+		// Stop all servers (sends /stop, waits for the JVMs to exit, then kills sessions)
+		_, _ = tm.StopAllWithContext(ctx, numServers)
 		
 		// Kill any remaining tmux sessions matching our pattern
 		for i := 1; i <= numServers; i++ {
-			sessionName := tm.SessionName(i)
-			cmd := exec.Command("tmux", "kill-session", "-t", sessionName)
-			_ = cmd.Run() // Ignore errors (session might not exist)
+			_ = tm.Remove(i) // Ignore errors (session might not exist)
 		}
 	}
+	if manifest.ProcessBackend == BackendSystemd {
+		RemoveSystemdUnits()
+	}
 
 	// 2. Delete all server directories
 	if err := os.RemoveAll(DataDirBase); err != nil {
@@ -36,9 +40,7 @@ func WipeEverything(ctx context.Context) (string, error) {
 	}
 
 	// 4. Delete system user (if it exists)
-	// Read default user from config if possible, or use default
-	// For now, try to remove the default user
-	userToRemove := DefaultHytaleUser
+	userToRemove := manifest.HytaleUser
 	cmd := exec.Command("id", "-u", userToRemove)
 	if err := cmd.Run(); err == nil {
 		// User exists, remove it
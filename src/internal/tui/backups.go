@@ -0,0 +1,335 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sivert-io/hytale-server-manager/src/internal/hytale"
+)
+
+// Backup kinds shown in the backup browser
+const (
+	backupKindGame     = "game"
+	backupKindSnapshot = "snapshot"
+)
+
+// backupEntry is a row in the backup browser: a game backup or an HSM snapshot
+type backupEntry struct {
+	kind   string
+	name   string
+	path   string
+	source int // Server the backup was taken of
+	time   time.Time
+	size   int64
+	worlds []string
+}
+
+// Backups message (game backups of a server and all HSM snapshots)
+type backupsMsg struct {
+	server  int
+	backups []backupEntry
+	err     error
+	notice  string
+}
+
+// loadBackupsGo lists a server's game backups and the HSM snapshots that can be restored to it
+// Snapshots of other servers are included so a world can be cloned between servers
+func loadBackupsGo(server int, notice string) tea.Cmd {
+	return func() tea.Msg {
+		games, err := hytale.ListGameBackups(server)
+		if err != nil {
+			return backupsMsg{server: server, err: err}
+		}
+		snapshots, err := hytale.ListSnapshots(0)
+		if err != nil {
+			return backupsMsg{server: server, err: err}
+		}
+
+		var entries []backupEntry
+		for _, s := range snapshots {
+			entries = append(entries, backupEntry{kind: backupKindSnapshot, name: s.Name, path: s.Path, source: s.Server, time: s.Created, size: s.Size, worlds: s.Worlds})
+		}
+		for _, b := range games {
+			entries = append(entries, backupEntry{kind: backupKindGame, name: b.Name, path: b.Path, source: b.Server, time: b.Time, size: b.Size, worlds: b.Worlds})
+		}
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].time.After(entries[j].time)
+		})
+		return backupsMsg{server: server, backups: entries, notice: notice}
+	}
+}
+
+// runCreateSnapshotGo takes an HSM snapshot and reloads the list
+func runCreateSnapshotGo(server int) tea.Cmd {
+	return func() tea.Msg {
+		tm := hytale.NewTmuxManagerFromManifest(hytale.LoadManifestOrDefault())
+		snapshot, err := tm.CreateSnapshot(server)
+		if err != nil {
+			return commandFinishedMsg{output: "", err: err}
+		}
+		notice := fmt.Sprintf("Snapshot %s created", snapshot.Name)
+		if tm.HasSession(server) && !snapshot.SaveConfirmed {
+			notice += " (save not confirmed on the console)"
+		}
+		lines, err := hytale.ReplicateToTargets(context.Background(), snapshot.Path, hytale.SnapshotRemoteName(snapshot))
+		if err != nil {
+			return commandFinishedMsg{output: notice + "\n" + strings.Join(lines, "\n"), err: err}
+		}
+		if len(lines) > 0 {
+			notice += "; " + strings.Join(lines, "; ")
+		}
+		results, _ := hytale.PruneBackups(context.Background(), []int{server}, false)
+		if removed := prunedCount(results); removed > 0 {
+			notice += fmt.Sprintf("; retention removed %d old backup(s)", removed)
+		}
+		return loadBackupsGo(server, notice)()
+	}
+}
+
+// runInspectBackupGo shows the contents of a backup in the viewer
+// Snapshots are verified against their manifest checksums while inspecting
+func runInspectBackupGo(backup backupEntry) tea.Cmd {
+	return func() tea.Msg {
+		var b strings.Builder
+		b.WriteString(fmt.Sprintf("Path:       %s\n", backup.path))
+		b.WriteString(fmt.Sprintf("Created:    %s\n", backup.time.Format("2006-01-02 15:04:05")))
+
+		if backup.kind == backupKindSnapshot {
+			manifest, err := hytale.VerifySnapshot(backup.path)
+			if err != nil {
+				return commandFinishedMsg{output: "", err: err}
+			}
+			var total int64
+			for _, f := range manifest.Files {
+				total += f.Size
+			}
+			b.WriteString(fmt.Sprintf("Size:       %s (%s uncompressed)\n", formatLogSize(backup.size), formatLogSize(total)))
+			b.WriteString(fmt.Sprintf("Server:     %d\n", manifest.Server))
+			b.WriteString(fmt.Sprintf("Files:      %d (all checksums verified)\n", len(manifest.Files)))
+			b.WriteString(fmt.Sprintf("Worlds:     %s\n", strings.Join(manifest.Worlds(), ", ")))
+			b.WriteString(fmt.Sprintf("Saved:      running=%t, save confirmed=%t\n", manifest.WasRunning, manifest.SaveConfirmed))
+		} else {
+			inspection, err := hytale.InspectGameBackup(backup.path)
+			if err != nil {
+				return commandFinishedMsg{output: "", err: err}
+			}
+			b.WriteString(fmt.Sprintf("Size:       %s (%s uncompressed)\n", formatLogSize(backup.size), formatLogSize(inspection.UncompressedSize)))
+			b.WriteString(fmt.Sprintf("Entries:    %d\n", inspection.Entries))
+			b.WriteString(fmt.Sprintf("Worlds:     %s\n", strings.Join(inspection.Worlds, ", ")))
+			b.WriteString(fmt.Sprintf("Top level:  %s\n", strings.Join(inspection.TopLevel, ", ")))
+		}
+		return viewportContentMsg{
+			content: b.String(),
+			title:   fmt.Sprintf("Server %d - %s", backup.source, backup.name),
+		}
+	}
+}
+
+// runRestoreBackupGo restores a game backup or snapshot to a server
+func runRestoreBackupGo(server int, backup backupEntry) tea.Cmd {
+	return func() tea.Msg {
+		tm := hytale.NewTmuxManagerFromManifest(hytale.LoadManifestOrDefault())
+		var output string
+		var err error
+		if backup.kind == backupKindSnapshot {
+			output, err = tm.RestoreSnapshot(backup.name, server, hytale.SnapshotRestoreOptions{})
+		} else {
+			output, err = tm.RestoreGameBackup(server, backup.name)
+		}
+		return commandFinishedMsg{output: output, err: err}
+	}
+}
+
+// runDeleteBackupGo deletes a game backup or snapshot and reloads the list
+func runDeleteBackupGo(server int, backup backupEntry) tea.Cmd {
+	return func() tea.Msg {
+		var err error
+		if backup.kind == backupKindSnapshot {
+			err = hytale.DeleteSnapshot(backup.name)
+		} else {
+			err = hytale.DeleteGameBackup(server, backup.name)
+		}
+		if err != nil {
+			return commandFinishedMsg{output: "", err: err}
+		}
+		return loadBackupsGo(server, fmt.Sprintf("Deleted %s", backup.name))()
+	}
+}
+
+// Prune preview message (what retention would delete)
+type backupPruneMsg struct {
+	server  int
+	results []hytale.PruneResult
+	err     error
+}
+
+// runPrunePreviewGo runs retention as a dry run so the browser can show what would be deleted
+func runPrunePreviewGo(server int) tea.Cmd {
+	return func() tea.Msg {
+		results, err := hytale.PruneBackups(context.Background(), []int{server}, true)
+		return backupPruneMsg{server: server, results: results, err: err}
+	}
+}
+
+// runPruneBackupsGo applies retention to a server's backups and reloads the list
+func runPruneBackupsGo(server int) tea.Cmd {
+	return func() tea.Msg {
+		results, err := hytale.PruneBackups(context.Background(), []int{server}, false)
+		if err != nil {
+			var lines []string
+			for _, r := range results {
+				lines = append(lines, r.String())
+			}
+			return commandFinishedMsg{output: strings.Join(lines, "\n"), err: err}
+		}
+		return loadBackupsGo(server, fmt.Sprintf("Retention removed %d backup(s)", prunedCount(results)))()
+	}
+}
+
+// prunedCount counts the backups removed by a prune run
+func prunedCount(results []hytale.PruneResult) int {
+	count := 0
+	for _, r := range results {
+		count += len(r.Removed)
+	}
+	return count
+}
+
+// updateBackups handles keys in the backup browser
+// Returns handled=false for keys the main handler deals with (navigation, esc)
+func (m model) updateBackups(msg tea.KeyMsg) (model, tea.Cmd, bool) {
+	if m.backupConfirm == "prune" {
+		m.backupConfirm = ""
+		m.backupPrunePreview = nil
+		switch msg.String() {
+		case "y", "Y":
+			return m, runPruneBackupsGo(m.backupServer), true
+		case "ctrl+c":
+			return m, nil, false
+		}
+		return m, nil, true
+	}
+	if m.backupConfirm != "" {
+		switch msg.String() {
+		case "y", "Y":
+			backup := m.backups[m.backupCursor]
+			action := m.backupConfirm
+			m.backupConfirm = ""
+			if action == "delete" {
+				return m, runDeleteBackupGo(m.backupServer, backup), true
+			}
+			m.running = true
+			m.actionTitle = fmt.Sprintf("💾 Restore Server %d", m.backupServer)
+			return m, tea.Batch(
+				sendActivityLog(fmt.Sprintf("Restoring %s to server %d...", backup.name, m.backupServer)),
+				runRestoreBackupGo(m.backupServer, backup),
+			), true
+		case "ctrl+c":
+			return m, nil, false
+		default:
+			// Any other key cancels
+			m.backupConfirm = ""
+			return m, nil, true
+		}
+	}
+
+	if msg.String() == "p" {
+		return m, runPrunePreviewGo(m.backupServer), true
+	}
+	if msg.String() == "s" {
+		m.running = true
+		m.actionTitle = fmt.Sprintf("📸 Snapshot Server %d", m.backupServer)
+		return m, tea.Batch(
+			sendActivityLog(fmt.Sprintf("Taking snapshot of server %d...", m.backupServer)),
+			runCreateSnapshotGo(m.backupServer),
+		), true
+	}
+	if len(m.backups) == 0 {
+		return m, nil, false
+	}
+	switch msg.String() {
+	case "enter", "i":
+		return m, runInspectBackupGo(m.backups[m.backupCursor]), true
+	case "r":
+		m.backupConfirm = "restore"
+		return m, nil, true
+	case "d":
+		m.backupConfirm = "delete"
+		return m, nil, true
+	}
+	return m, nil, false
+}
+
+// renderBackups renders the backup browser (newest first)
+// Snapshots taken of another server are marked with their source server
+func (m model) renderBackups() string {
+	s := titleStyle.Render(fmt.Sprintf(" 💾 Server %d Backups", m.backupServer)) + "\n\n"
+	if m.backupNotice != "" {
+		s += dimmedStyle.Render(m.backupNotice) + "\n\n"
+	}
+
+	if len(m.backups) == 0 {
+		s += dimmedStyle.Render("No backups found in "+hytale.GetServerBackupDir(m.backupServer)) + "\n"
+		s += dimmedStyle.Render("Enable backups in shared/backup.json so the server writes them, or press s for an HSM snapshot.") + "\n"
+		s += m.renderPrunePreview()
+		s += "\n" + dimmedStyle.Render("s: Snapshot  |  p: Prune  |  Esc: Back")
+		return s
+	}
+
+	s += selectedStyle.Render(fmt.Sprintf("  %-10s %-36s %-18s %-10s %s", "Kind", "Backup", "Time", "Size", "Worlds")) + "\n"
+	for i, backup := range m.backups {
+		worlds := strings.Join(backup.worlds, ", ")
+		if worlds == "" {
+			worlds = "-"
+		}
+		kind := backup.kind
+		if backup.kind == backupKindSnapshot && backup.source != m.backupServer {
+			kind = fmt.Sprintf("snap (s%d)", backup.source)
+		}
+		row := fmt.Sprintf("%-10s %-36s %-18s %-10s %s", kind, backup.name, backup.time.Format("2006-01-02 15:04"), formatLogSize(backup.size), worlds)
+		cursor := "  "
+		if i == m.backupCursor {
+			cursor = selectedStyle.Render("▶ ")
+			row = selectedStyle.Render(row)
+		}
+		s += cursor + row + "\n"
+	}
+
+	switch m.backupConfirm {
+	case "restore":
+		backup := m.backups[m.backupCursor]
+		prompt := fmt.Sprintf("Restore %s to server %d? The server is stopped and its current universe moved aside. (y/N)", backup.name, m.backupServer)
+		if backup.kind == backupKindSnapshot {
+			prompt = fmt.Sprintf("Restore %s to server %d? The server is stopped and its universe, config.json and mods moved aside. (y/N)", backup.name, m.backupServer)
+		}
+		s += "\n" + consoleErrorStyle.Render(prompt) + "\n"
+	case "delete":
+		s += "\n" + consoleErrorStyle.Render(fmt.Sprintf("Delete %s permanently? (y/N)", m.backups[m.backupCursor].name)) + "\n"
+	}
+	s += m.renderPrunePreview()
+	s += "\n" + dimmedStyle.Render("Enter/i: Inspect  |  r: Restore  |  d: Delete  |  s: Snapshot  |  p: Prune  |  Esc: Back")
+	return s
+}
+
+// renderPrunePreview renders the retention dry run and its confirmation prompt
+func (m model) renderPrunePreview() string {
+	if m.backupConfirm != "prune" {
+		return ""
+	}
+	s := "\n"
+	removed := 0
+	for _, line := range m.backupPrunePreview {
+		s += dimmedStyle.Render(line) + "\n"
+		if strings.HasPrefix(line, "  ") {
+			removed++
+		}
+	}
+	if removed == 0 {
+		return s + dimmedStyle.Render("Retention has nothing to remove. Press any key.") + "\n"
+	}
+	return s + consoleErrorStyle.Render(fmt.Sprintf("Delete these %d backup(s)? (y/N)", removed)) + "\n"
+}
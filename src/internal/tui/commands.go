@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"strings"
-	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/sivert-io/hytale-server-manager/src/internal/hytale"
@@ -33,20 +32,21 @@ func CancelInstall() {
 // Wrapper commands for backend operations
 
 func runBootstrapGo(cfg hytale.BootstrapConfig) tea.Cmd {
-	return func() tea.Msg {
-		ctx, cancel := context.WithCancel(context.Background())
-		SetInstallCancel(cancel)
-		defer CancelInstall()
-
+	return startGameDownload(func(ctx context.Context, progress hytale.ProgressCallback, deviceCode hytale.DeviceCodeCallback) commandFinishedMsg {
 		// Collect progress messages
 		var progressSteps []string
 
-		// Progress callback that collects steps
+		// Progress callback that collects steps (once each, the download reports many times)
+		// and shows them on the progress bar
 		progressCallback := func(percent float64, label string) {
-			progressSteps = append(progressSteps, label)
+			if len(progressSteps) == 0 || progressSteps[len(progressSteps)-1] != label {
+				progressSteps = append(progressSteps, label)
+			}
+			progress(percent, label)
 		}
 
 		// Run bootstrap with progress callback
+		cfg.DeviceCodeCallback = deviceCode
 		out, err := hytale.BootstrapWithContextAndProgress(ctx, cfg, progressCallback)
 
 		// Combine all progress messages into output
@@ -71,7 +71,7 @@ func runBootstrapGo(cfg hytale.BootstrapConfig) tea.Cmd {
 			output: output.String(),
 			err:    err,
 		}
-	}
+	}, nil)
 }
 
 func runStartAllGo() tea.Cmd {
@@ -80,34 +80,17 @@ func runStartAllGo() tea.Cmd {
 		if numServers == 0 {
 			return commandFinishedMsg{
 				output: "",
-				err:    fmt.Errorf("no servers installed - run installation wizard first"),
+				err:    hytale.ErrNotInstalled,
 			}
 		}
 
-		tm := hytale.NewTmuxManager(hytale.DefaultBasePort)
-		
-		// Get server JAR path (servers share the same JAR)
-		jarPath := hytale.GetServerJarPath(1)
-		dataDirBase := hytale.DataDirBase
+		manifest := hytale.LoadManifestOrDefault()
+		tm := hytale.NewTmuxManagerFromManifest(manifest)
 		
-		// Get backup settings from shared config file
-		backupConfig, backupErr := hytale.ReadBackupConfig()
-		if backupErr != nil {
-			// Fall back to defaults if config can't be read
-			backupConfig = &hytale.BackupConfig{
-				Enabled:  hytale.DefaultBackupEnabled,
-				Frequency: hytale.DefaultBackupFrequency,
-			}
-		}
-		backupEnabled := backupConfig.Enabled
-		backupFrequency := backupConfig.Frequency
+		// Launch settings come from the manifest, shared backup config and session tokens
+		settings := hytale.LoadLaunchSettings(manifest)
 		
-		// Try to load session tokens (for automatic authentication)
-		// Per Server Provider Authentication Guide: https://support.hytale.com/hc/en-us/articles/45328341414043
-		// If tokens exist and are valid, servers start authenticated without manual /auth
-		sessionTokens, _ := hytale.LoadSessionTokens()
-		
-		err := tm.StartAll(numServers, dataDirBase, jarPath, hytale.DefaultJVMArgs, backupEnabled, backupFrequency, sessionTokens)
+		err := tm.StartAll(numServers, hytale.DataDirBase, settings.JarPath, settings.JVMArgs, settings.BackupEnabled, settings.BackupFrequency, settings.SessionTokens)
 		if err != nil {
 			return commandFinishedMsg{
 				output: "",
@@ -128,22 +111,30 @@ func runStopAllGo() tea.Cmd {
 		if numServers == 0 {
 			return commandFinishedMsg{
 				output: "",
-				err:    fmt.Errorf("no servers installed"),
+				err:    hytale.ErrNotInstalled,
 			}
 		}
 
-		tm := hytale.NewTmuxManager(hytale.DefaultBasePort)
+		tm := hytale.NewTmuxManagerFromManifest(hytale.LoadManifestOrDefault())
 		
-		err := tm.StopAll(numServers)
+		results, err := tm.StopAll(numServers)
+
+		var output strings.Builder
+		for _, result := range results {
+			output.WriteString(fmt.Sprintf("  Server %d: %s\n", result.Server, result))
+		}
 		if err != nil {
 			return commandFinishedMsg{
-				output: "",
+				output: output.String(),
 				err:    err,
 			}
 		}
+		if len(results) == 0 {
+			return commandFinishedMsg{output: "No servers were running"}
+		}
 
 		return commandFinishedMsg{
-			output: fmt.Sprintf("All %d servers stopped successfully", numServers),
+			output: fmt.Sprintf("%d server(s) stopped successfully\n\n%s", len(results), output.String()),
 			err:    nil,
 		}
 	}
@@ -155,42 +146,44 @@ func runRestartAllGo() tea.Cmd {
 		if numServers == 0 {
 			return commandFinishedMsg{
 				output: "",
-				err:    fmt.Errorf("no servers installed"),
+				err:    hytale.ErrNotInstalled,
 			}
 		}
 
-		tm := hytale.NewTmuxManager(hytale.DefaultBasePort)
-		
-		// Stop all
-		_ = tm.StopAll(numServers)
+		manifest := hytale.LoadManifestOrDefault()
+		tm := hytale.NewTmuxManagerFromManifest(manifest)
 		
-		// Small delay before starting
-		time.Sleep(1 * time.Second)
-		
-		// Get server JAR path
-		jarPath := hytale.GetServerJarPath(1)
-		dataDirBase := hytale.DataDirBase
-		
-		// Get backup settings from shared config file
-		backupConfig, backupErr := hytale.ReadBackupConfig()
-		if backupErr != nil {
-			// Fall back to defaults if config can't be read
-			backupConfig = &hytale.BackupConfig{
-				Enabled:  hytale.DefaultBackupEnabled,
-				Frequency: hytale.DefaultBackupFrequency,
+		// Stop all (waits for every JVM to exit, so ports are free again)
+		results, stopErr := tm.StopAll(numServers)
+		stuck := make(map[int]string)
+		for _, result := range results {
+			if result.Error != "" {
+				stuck[result.Server] = result.Error
 			}
 		}
-		backupEnabled := backupConfig.Enabled
-		backupFrequency := backupConfig.Frequency
-		
-		// Try to load session tokens (for automatic authentication)
-		sessionTokens, _ := hytale.LoadSessionTokens()
-		
-		// Start all
-		err := tm.StartAll(numServers, dataDirBase, jarPath, hytale.DefaultJVMArgs, backupEnabled, backupFrequency, sessionTokens)
-		if err != nil {
+
+		// Start all, except servers that didn't stop: their JVM may still hold the port
+		settings := hytale.LoadLaunchSettings(manifest)
+		var output strings.Builder
+		failed := 0
+		for i := 1; i <= numServers; i++ {
+			if reason, ok := stuck[i]; ok {
+				output.WriteString(fmt.Sprintf("  Server %d: not restarted, it failed to stop: %s\n", i, reason))
+				failed++
+				continue
+			}
+			if err := tm.StartServer(i, settings); err != nil {
+				output.WriteString(fmt.Sprintf("  Server %d: stopped but failed to start: %v\n", i, err))
+				failed++
+			}
+		}
+		if failed > 0 {
+			err := fmt.Errorf("%d of %d server(s) failed to restart", failed, numServers)
+			if stopErr != nil {
+				err = fmt.Errorf("%w (%v)", err, stopErr)
+			}
 			return commandFinishedMsg{
-				output: "",
+				output: output.String(),
 				err:    err,
 			}
 		}
@@ -202,19 +195,73 @@ func runRestartAllGo() tea.Cmd {
 	}
 }
 
-func runUpdateGameGo() tea.Cmd {
+// runServerActionGo starts, stops or restarts the given servers concurrently
+// and reports the outcome for each server in the receipt
+func runServerActionGo(kind itemKind, servers []int) tea.Cmd {
 	return func() tea.Msg {
-		ctx, cancel := context.WithCancel(context.Background())
-		defer cancel()
+		manifest := hytale.LoadManifestOrDefault()
+		tm := hytale.NewTmuxManagerFromManifest(manifest)
+		settings := hytale.LoadLaunchSettings(manifest)
+
+		var verb string
+		var action func(server int) (string, error)
+		switch kind {
+		case itemStartServers:
+			verb = "started"
+			action = func(server int) (string, error) { return "", tm.StartServer(server, settings) }
+		case itemStopServers:
+			verb = "stopped"
+			action = func(server int) (string, error) {
+				result, err := tm.Stop(server)
+				return result.String(), err
+			}
+		case itemRestartServers:
+			verb = "restarted"
+			action = func(server int) (string, error) { return "", tm.RestartServer(server, settings) }
+		default:
+			return commandFinishedMsg{err: fmt.Errorf("unknown server action")}
+		}
+
+		results := hytale.RunOnServers(servers, action)
+
+		var output strings.Builder
+		failed := 0
+		for _, result := range results {
+			if result.Err != nil {
+				failed++
+				output.WriteString(fmt.Sprintf("  ❌ Server %d: %v\n", result.Server, result.Err))
+			} else {
+				line := fmt.Sprintf("  ✅ Server %d: %s", result.Server, verb)
+				if result.Detail != "" {
+					line += fmt.Sprintf(" (%s)", result.Detail)
+				}
+				output.WriteString(line + "\n")
+			}
+		}
 
-		out, err := hytale.UpdateGame(ctx)
+		var err error
+		if failed > 0 {
+			err = fmt.Errorf("%d of %d server(s) failed:\n\n%s", failed, len(results), output.String())
+		}
 		return commandFinishedMsg{
-			output: out,
+			output: output.String(),
 			err:    err,
 		}
 	}
 }
 
+// runUpdateGameGo updates the game in the background, then checks for game updates again so
+// the "Game update available" item goes away
+func runUpdateGameGo() tea.Cmd {
+	return startGameDownload(func(ctx context.Context, progress hytale.ProgressCallback, deviceCode hytale.DeviceCodeCallback) commandFinishedMsg {
+		out, err := hytale.UpdateGameWithProgress(ctx, progress, deviceCode)
+		return commandFinishedMsg{
+			output: out,
+			err:    err,
+		}
+	}, checkForGameUpdate())
+}
+
 func runUpdatePluginsGo() tea.Cmd {
 	return func() tea.Msg {
 		ctx, cancel := context.WithCancel(context.Background())
@@ -234,34 +281,14 @@ func runUpdatePluginsGo() tea.Cmd {
 	}
 }
 
-func runViewLogsGo(serverNum int) tea.Cmd {
-	return func() tea.Msg {
-		tm := hytale.NewTmuxManager(hytale.DefaultBasePort)
-		logs, err := tm.Logs(serverNum, 100) // Get last 100 lines
-		if err != nil {
-			return commandFinishedMsg{
-				output: "",
-				err:    fmt.Errorf("failed to get logs for server %d: %w", serverNum, err),
-			}
-		}
-		
-		// Return logs as viewport content message
-		return viewportContentMsg{
-			content: logs,
-			title:   fmt.Sprintf("Server %d Logs", serverNum),
-		}
-	}
-}
-
 func runScaleUpGo(numToAdd int) tea.Cmd {
 	return func() tea.Msg {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 
 		numServers := hytale.DetectNumServers()
-		basePort := hytale.DefaultBasePort
 		
-		err := hytale.AddServerInstanceWithContext(ctx, numServers, basePort)
+		err := hytale.AddServerInstanceWithContext(ctx, numServers)
 		if err != nil {
 			return commandFinishedMsg{
 				output: "",
@@ -307,12 +334,11 @@ func runAddServersGo(numToAdd int) tea.Cmd {
 		defer cancel()
 
 		numServers := hytale.DetectNumServers()
-		basePort := hytale.DefaultBasePort
 		
 		var added int
 		var lastErr error
 		for i := 0; i < numToAdd; i++ {
-			err := hytale.AddServerInstanceWithContext(ctx, numServers+i, basePort)
+			err := hytale.AddServerInstanceWithContext(ctx, numServers+i)
 			if err != nil {
 				lastErr = err
 				break
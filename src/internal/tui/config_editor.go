@@ -0,0 +1,269 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sivert-io/hytale-server-manager/src/internal/hytale"
+)
+
+// configEditor is a form over the editable config.json fields of one or more servers
+// A field whose value differs between the servers starts empty and is only changed if filled in
+type configEditor struct {
+	servers    []int
+	inputs     []textinput.Model // One per hytale.ConfigFields
+	initial    []string
+	varies     []bool
+	focus      int
+	previewing bool
+	diffs      []hytale.ServerConfigDiff
+	errMsg     string
+}
+
+// Config editor loaded message
+type configEditorMsg struct {
+	editor configEditor
+	err    error
+}
+
+// loadConfigEditorGo reads the selected servers' configs into a new editor
+func loadConfigEditorGo(servers []int) tea.Cmd {
+	return func() tea.Msg {
+		configs := make([]*hytale.HytaleConfig, len(servers))
+		for i, server := range servers {
+			config, err := hytale.ReadConfig(hytale.GetServerConfigPath(server))
+			if err != nil {
+				return configEditorMsg{err: fmt.Errorf("server %d: %w", server, err)}
+			}
+			configs[i] = config
+		}
+
+		e := configEditor{servers: servers}
+		for _, f := range hytale.ConfigFields {
+			value, varies := f.Get(configs[0]), false
+			for _, config := range configs[1:] {
+				if f.Get(config) != value {
+					value, varies = "", true
+					break
+				}
+			}
+
+			input := textinput.New()
+			input.Prompt = ""
+			input.Width = 40
+			input.SetValue(value)
+			if varies {
+				input.Placeholder = "(differs between servers)"
+			}
+			if f.Kind == hytale.ConfigFieldSecret {
+				input.EchoMode = textinput.EchoPassword
+			}
+			e.inputs = append(e.inputs, input)
+			e.initial = append(e.initial, value)
+			e.varies = append(e.varies, varies)
+		}
+		e.inputs[0].Focus()
+		return configEditorMsg{editor: e}
+	}
+}
+
+// edits returns the fields the user changed, keyed by config field
+func (e configEditor) edits() map[string]string {
+	edits := make(map[string]string)
+	for i, f := range hytale.ConfigFields {
+		value := e.inputs[i].Value()
+		if f.Kind == hytale.ConfigFieldInt {
+			value = strings.TrimSpace(value)
+		}
+		if value == e.initial[i] && !(e.varies[i] && value != "") {
+			continue
+		}
+		edits[f.Key] = value
+	}
+	return edits
+}
+
+// setFocus moves the cursor to another field
+func (e *configEditor) setFocus(i int) {
+	e.inputs[e.focus].Blur()
+	e.focus = (i + len(e.inputs)) % len(e.inputs)
+	e.inputs[e.focus].Focus()
+}
+
+// cycleChoice steps a choice field to the next or previous value
+func (e *configEditor) cycleChoice(step int) {
+	f := hytale.ConfigFields[e.focus]
+	current := 0
+	for i, choice := range f.Choices {
+		if choice == e.inputs[e.focus].Value() {
+			current = i
+			break
+		}
+	}
+	next := (current + step + len(f.Choices)) % len(f.Choices)
+	e.inputs[e.focus].SetValue(f.Choices[next])
+}
+
+// runApplyConfigGo writes the edits to every selected server and reports what changed
+func runApplyConfigGo(servers []int, edits map[string]string) tea.Cmd {
+	return func() tea.Msg {
+		diffs, err := hytale.ApplyConfigEdits(servers, edits)
+		var output strings.Builder
+		tm := hytale.NewTmuxManagerFromManifest(hytale.LoadManifestOrDefault())
+		restart := false
+		for _, diff := range diffs {
+			switch {
+			case diff.Err != nil:
+				output.WriteString(fmt.Sprintf("❌ Server %d: %v\n", diff.Server, diff.Err))
+			case len(diff.Changes) == 0:
+				output.WriteString(fmt.Sprintf("Server %d: already up to date\n", diff.Server))
+			default:
+				output.WriteString(fmt.Sprintf("✅ Server %d: %d change(s), previous config saved to %s\n", diff.Server, len(diff.Changes), diff.Backup))
+				if tm.HasSession(diff.Server) {
+					restart = true
+				}
+			}
+		}
+		if restart {
+			output.WriteString("\nRestart the running servers for the changes to take effect.\n")
+		}
+		return commandFinishedMsg{output: output.String(), err: err}
+	}
+}
+
+// updateConfigEditor handles keys in the config editor
+// Returns handled=false for keys the main handler deals with (esc from the form, ctrl+c)
+func (m model) updateConfigEditor(msg tea.KeyMsg) (model, tea.Cmd, bool) {
+	e := &m.configEditor
+	if len(e.inputs) == 0 {
+		return m, nil, false
+	}
+
+	if e.previewing {
+		switch msg.String() {
+		case "y", "Y", "enter":
+			m.running = true
+			m.actionTitle = serverActionTitle(itemEditConfigs, e.servers)
+			return m, tea.Batch(
+				sendActivityLog("Writing config.json..."),
+				runApplyConfigGo(e.servers, e.edits()),
+			), true
+		case "ctrl+c":
+			return m, nil, false
+		default:
+			e.previewing = false
+			return m, nil, true
+		}
+	}
+
+	f := hytale.ConfigFields[e.focus]
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		return m, nil, false
+	case "up", "shift+tab":
+		e.setFocus(e.focus - 1)
+		return m, nil, true
+	case "down", "tab":
+		e.setFocus(e.focus + 1)
+		return m, nil, true
+	case "enter":
+		edits := e.edits()
+		if len(edits) == 0 {
+			e.errMsg = "Nothing changed"
+			return m, nil, true
+		}
+		if err := hytale.ValidateConfigEdits(edits); err != nil {
+			e.errMsg = err.Error()
+			return m, nil, true
+		}
+		e.errMsg = ""
+		e.diffs = hytale.DiffConfigEdits(e.servers, edits)
+		e.previewing = true
+		return m, nil, true
+	}
+
+	if f.Kind == hytale.ConfigFieldChoice {
+		// Choice fields are picked, not typed
+		switch msg.String() {
+		case "left", " ":
+			e.cycleChoice(-1)
+		case "right":
+			e.cycleChoice(1)
+		}
+		return m, nil, true
+	}
+
+	var cmd tea.Cmd
+	e.inputs[e.focus], cmd = e.inputs[e.focus].Update(msg)
+	e.errMsg = ""
+	return m, cmd, true
+}
+
+// renderConfigEditor renders the form or the diff preview
+func (m model) renderConfigEditor() string {
+	e := m.configEditor
+	s := titleStyle.Render(" "+serverActionTitle(itemEditConfigs, e.servers)) + "\n\n"
+
+	if e.previewing {
+		s += dimmedStyle.Render("Changes to config.json (the previous file is backed up first):") + "\n\n"
+		for _, diff := range e.diffs {
+			switch {
+			case diff.Err != nil:
+				s += consoleErrorStyle.Render(fmt.Sprintf("Server %d: %v", diff.Server, diff.Err)) + "\n"
+			case len(diff.Changes) == 0:
+				s += dimmedStyle.Render(fmt.Sprintf("Server %d: no changes", diff.Server)) + "\n"
+			default:
+				s += selectedStyle.Render(fmt.Sprintf("Server %d", diff.Server)) + "\n"
+				for _, change := range diff.Changes {
+					old, new := change.Old, change.New
+					if f, ok := hytale.FindConfigField(change.Key); ok && f.Kind == hytale.ConfigFieldSecret {
+						old, new = maskSecret(old), maskSecret(new)
+					}
+					s += consoleErrorStyle.Render(fmt.Sprintf("  - %s: %q", change.Key, old)) + "\n"
+					s += fmt.Sprintf("  + %s: %q\n", change.Key, new)
+				}
+			}
+		}
+		s += "\n" + dimmedStyle.Render("y/Enter: Save  |  Any other key: Back to the form")
+		return s
+	}
+
+	for i, f := range hytale.ConfigFields {
+		cursor := "  "
+		label := fmt.Sprintf("%-24s", f.Label)
+		if i == e.focus {
+			cursor = selectedStyle.Render("▶ ")
+			label = selectedStyle.Render(label)
+		}
+		value := e.inputs[i].View()
+		if f.Kind == hytale.ConfigFieldChoice {
+			value = "◀ " + e.inputs[i].Value() + " ▶"
+			if e.inputs[i].Value() == "" && e.varies[i] {
+				value = "◀ (differs between servers) ▶"
+			}
+		}
+		s += cursor + label + " " + value + "\n"
+	}
+
+	f := hytale.ConfigFields[e.focus]
+	help := f.Help
+	if f.Kind == hytale.ConfigFieldInt {
+		help += fmt.Sprintf(" (%d-%d)", f.Min, f.Max)
+	}
+	s += "\n" + dimmedStyle.Render(f.Key+": "+help) + "\n"
+	if e.errMsg != "" {
+		s += consoleErrorStyle.Render(e.errMsg) + "\n"
+	}
+	s += "\n" + dimmedStyle.Render("↑/↓/Tab: Field  |  ←/→: Change choice  |  Enter: Preview  |  Esc: Cancel")
+	return s
+}
+
+// maskSecret hides a secret in the diff preview while still showing whether it is set
+func maskSecret(value string) string {
+	if value == "" {
+		return ""
+	}
+	return strings.Repeat("•", len(value))
+}
@@ -0,0 +1,188 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sivert-io/hytale-server-manager/src/internal/hytale"
+)
+
+// consoleTranscriptLines is how many transcript lines the console prompt keeps
+const consoleTranscriptLines = 2000
+
+var (
+	consoleCommandStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true)
+	consoleErrorStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+)
+
+// serverConsole is an interactive console prompt that sends commands to one or more servers
+type serverConsole struct {
+	servers    []int
+	input      textinput.Model
+	transcript []string
+	history    []string
+	historyPos int // len(history) when not browsing history
+	busy       bool
+	viewport   viewport.Model
+}
+
+// Console output message
+type consoleOutputMsg struct {
+	command string
+	results []hytale.ServerActionResult
+}
+
+func newServerConsole(servers []int, width, height int) serverConsole {
+	input := textinput.New()
+	input.Prompt = "> "
+	input.Placeholder = "/say Hello"
+	input.Focus()
+
+	c := serverConsole{
+		servers:  servers,
+		input:    input,
+		viewport: viewport.New(80, 10),
+	}
+	c.resize(width, height)
+	return c
+}
+
+// resize fits the transcript to the window
+func (c *serverConsole) resize(width, height int) {
+	if width <= 0 {
+		width = 80
+	}
+	if height <= 0 {
+		height = 24
+	}
+	c.viewport.Width = width - 4
+	c.viewport.Height = height - 12
+	if c.viewport.Height < 3 {
+		c.viewport.Height = 3
+	}
+	c.input.Width = width - 8
+	c.viewport.SetContent(strings.Join(c.transcript, "\n"))
+	c.viewport.GotoBottom()
+}
+
+// sendConsoleCommandGo runs a command on every selected server and collects the output
+func sendConsoleCommandGo(servers []int, command string) tea.Cmd {
+	return func() tea.Msg {
+		tm := hytale.NewTmuxManagerFromManifest(hytale.LoadManifestOrDefault())
+		results := hytale.RunOnServers(servers, func(server int) (string, error) {
+			lines, err := tm.SendCommand(server, command)
+			return strings.Join(lines, "\n"), err
+		})
+		return consoleOutputMsg{command: command, results: results}
+	}
+}
+
+func (c serverConsole) Update(msg tea.Msg) (serverConsole, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		c.resize(msg.Width, msg.Height)
+		return c, nil
+
+	case consoleOutputMsg:
+		c.busy = false
+		for _, result := range msg.results {
+			prefix := ""
+			if len(c.servers) > 1 {
+				prefix = fmt.Sprintf("[%d] ", result.Server)
+			}
+			if result.Err != nil {
+				c.appendTranscript(consoleErrorStyle.Render(fmt.Sprintf("%s%v", prefix, result.Err)))
+				continue
+			}
+			if result.Detail == "" {
+				c.appendTranscript(dimmedStyle.Render(prefix + "(no output)"))
+				continue
+			}
+			for _, line := range strings.Split(result.Detail, "\n") {
+				c.appendTranscript(prefix + line)
+			}
+		}
+		return c, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "enter":
+			command := strings.TrimSpace(c.input.Value())
+			if command == "" || c.busy {
+				return c, nil
+			}
+			c.busy = true
+			c.history = append(c.history, command)
+			c.historyPos = len(c.history)
+			c.input.SetValue("")
+			c.appendTranscript(consoleCommandStyle.Render("> " + command))
+			return c, sendConsoleCommandGo(c.servers, command)
+		case "up":
+			if c.historyPos > 0 {
+				c.historyPos--
+				c.input.SetValue(c.history[c.historyPos])
+				c.input.CursorEnd()
+			}
+			return c, nil
+		case "down":
+			if c.historyPos < len(c.history)-1 {
+				c.historyPos++
+				c.input.SetValue(c.history[c.historyPos])
+				c.input.CursorEnd()
+			} else {
+				c.historyPos = len(c.history)
+				c.input.SetValue("")
+			}
+			return c, nil
+		case "pgup":
+			c.viewport.ViewUp()
+			return c, nil
+		case "pgdown":
+			c.viewport.ViewDown()
+			return c, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	c.input, cmd = c.input.Update(msg)
+	return c, cmd
+}
+
+// appendTranscript adds a line to the transcript and scrolls to it
+func (c *serverConsole) appendTranscript(line string) {
+	c.transcript = append(c.transcript, line)
+	if len(c.transcript) > consoleTranscriptLines {
+		c.transcript = c.transcript[len(c.transcript)-consoleTranscriptLines:]
+	}
+	c.viewport.SetContent(strings.Join(c.transcript, "\n"))
+	c.viewport.GotoBottom()
+}
+
+func (c serverConsole) View() string {
+	names := make([]string, len(c.servers))
+	for i, server := range c.servers {
+		names[i] = strconv.Itoa(server)
+	}
+	target := "Server " + names[0]
+	if len(c.servers) > 1 {
+		target = "Servers " + strings.Join(names, ", ")
+	}
+
+	s := titleStyle.Render(" ⌨️  Console - "+target) + "\n"
+	if len(c.transcript) == 0 {
+		s += dimmedStyle.Render("Commands are typed into the server console; the output printed after them is shown here.") + "\n"
+	} else {
+		s += c.viewport.View() + "\n"
+	}
+	s += "\n" + c.input.View() + "\n"
+	if c.busy {
+		s += dimmedStyle.Render("Waiting for output...") + "\n"
+	}
+	s += "\n" + dimmedStyle.Render("Enter: Send  |  ↑/↓: History  |  PgUp/PgDn: Scroll  |  Esc: Back")
+	return s
+}
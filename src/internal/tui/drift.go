@@ -0,0 +1,174 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sivert-io/hytale-server-manager/src/internal/hytale"
+)
+
+// Drift report message (every server compared with its config layers, shared/mods and master-install)
+type driftMsg struct {
+	reports []hytale.ServerDrift
+	err     error
+}
+
+// loadDriftGo builds the drift report for all servers
+func loadDriftGo() tea.Cmd {
+	return func() tea.Msg {
+		numServers := hytale.DetectNumServers()
+		if numServers == 0 {
+			return driftMsg{err: hytale.ErrNotInstalled}
+		}
+		servers := make([]int, numServers)
+		for i := range servers {
+			servers[i] = i + 1
+		}
+		reports, err := hytale.CheckDrift(context.Background(), servers, hytale.DriftOptions{})
+		return driftMsg{reports: reports, err: err}
+	}
+}
+
+// runReconcileGo puts the servers back in line and reports what was fixed
+func runReconcileGo(servers []int) tea.Cmd {
+	return func() tea.Msg {
+		tm := hytale.NewTmuxManagerFromManifest(hytale.LoadManifestOrDefault())
+		var output strings.Builder
+		failed := 0
+		for _, result := range tm.ReconcileDrift(context.Background(), servers) {
+			if result.Err != nil {
+				failed++
+				output.WriteString(fmt.Sprintf("❌ Server %d: %v\n", result.Server, result.Err))
+				continue
+			}
+			output.WriteString(fmt.Sprintf("✅ Server %d: %s\n", result.Server, result.Detail))
+		}
+		var err error
+		if failed > 0 {
+			err = fmt.Errorf("%d of %d server(s) could not be reconciled", failed, len(servers))
+		}
+		return commandFinishedMsg{output: output.String(), err: err}
+	}
+}
+
+// driftSelection returns the ticked servers, or the one under the cursor
+func (m model) driftSelection() []int {
+	var servers []int
+	for _, report := range m.drift {
+		if m.driftChecked[report.Server] {
+			servers = append(servers, report.Server)
+		}
+	}
+	if len(servers) == 0 && len(m.drift) > 0 {
+		servers = []int{m.drift[m.driftCursor].Server}
+	}
+	return servers
+}
+
+// updateDrift handles keys in the drift report
+// Returns handled=false for keys the main handler deals with (navigation, esc)
+func (m model) updateDrift(msg tea.KeyMsg) (model, tea.Cmd, bool) {
+	if m.driftConfirm {
+		m.driftConfirm = false
+		switch msg.String() {
+		case "y", "Y":
+			servers := m.driftSelection()
+			m.running = true
+			m.actionTitle = serverActionTitle(itemDrift, servers)
+			return m, tea.Batch(
+				sendActivityLog("Reconciling drifted servers..."),
+				runReconcileGo(servers),
+			), true
+		case "ctrl+c":
+			return m, nil, false
+		}
+		return m, nil, true
+	}
+
+	switch msg.String() {
+	case "r":
+		m.running = true
+		return m, tea.Batch(sendActivityLog("Comparing servers... (hashing game files may take a moment)"), loadDriftGo()), true
+	case " ":
+		if len(m.drift) > 0 {
+			server := m.drift[m.driftCursor].Server
+			m.driftChecked[server] = !m.driftChecked[server]
+		}
+		return m, nil, true
+	case "a":
+		all := false
+		for _, report := range m.drift {
+			if !m.driftChecked[report.Server] {
+				all = true
+			}
+		}
+		for _, report := range m.drift {
+			m.driftChecked[report.Server] = all
+		}
+		return m, nil, true
+	case "c":
+		if len(m.drift) > 0 {
+			m.driftConfirm = true
+		}
+		return m, nil, true
+	}
+	return m, nil, false
+}
+
+// renderDrift renders the per-server drift summary and the differences of the server under the cursor
+func (m model) renderDrift() string {
+	s := titleStyle.Render(" 🧭 Drift Report") + "\n\n"
+	s += dimmedStyle.Render("config.json vs its config layers, mods/ vs shared/mods, game files vs master-install") + "\n\n"
+
+	for i, report := range m.drift {
+		cursor := "  "
+		if i == m.driftCursor {
+			cursor = selectedStyle.Render("▶ ")
+		}
+		check := "[ ]"
+		if m.driftChecked[report.Server] {
+			check = "[x]"
+		}
+		row := fmt.Sprintf("%s Server %-4d %s", check, report.Server, report.Summary())
+		switch {
+		case i == m.driftCursor:
+			row = selectedStyle.Render(row)
+		case report.Error != "" || report.Count("") > 0:
+			row = consoleErrorStyle.Render(row)
+		}
+		s += cursor + row + "\n"
+	}
+
+	if len(m.drift) > 0 {
+		report := m.drift[m.driftCursor]
+		s += "\n" + titleStyle.Render(fmt.Sprintf(" Server %d", report.Server)) + "\n\n"
+		items := report.Items
+		if max := m.height - len(m.drift) - 16; max > 0 && len(items) > max {
+			items = items[:max]
+		}
+		for _, item := range items {
+			expected, actual := item.Expected, item.Actual
+			if expected == "" {
+				expected = "(none)"
+			}
+			if actual == "" {
+				actual = "(none)"
+			}
+			s += fmt.Sprintf("  %-9s %-40s %s → %s\n", item.Kind, item.Path, expected, actual)
+		}
+		if len(items) < len(report.Items) {
+			s += dimmedStyle.Render(fmt.Sprintf("  ... and %d more (hsm drift check %d)", len(report.Items)-len(items), report.Server)) + "\n"
+		}
+		if len(report.Items) == 0 && report.Error == "" {
+			s += dimmedStyle.Render("  No differences") + "\n"
+		}
+	}
+
+	if m.driftConfirm {
+		s += "\n" + consoleErrorStyle.Render(fmt.Sprintf("%s? config.json is re-rendered, mods are copied from shared/mods (extra mods moved aside) and game files from master-install. (y/N)", serverActionTitle(itemDrift, m.driftSelection()))) + "\n"
+	}
+	s += "\n" + dimmedStyle.Render("Space: Toggle  |  a: Toggle All  |  c: Reconcile  |  r: Refresh  |  Esc: Back")
+	return s
+}
@@ -0,0 +1,87 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sivert-io/hytale-server-manager/src/internal/hytale"
+)
+
+// Sign-in prompt printed by hytale-downloader during a download
+type deviceCodeMsg struct {
+	code hytale.DeviceCode
+}
+
+// Download started message (hands the running download to the model)
+type gameDownloadStartedMsg struct {
+	download *gameDownload
+}
+
+// gameDownload is an installation or game update running in the background
+// Download progress arrives as progressMsg, sign-in prompts as deviceCodeMsg and the end as
+// commandFinishedMsg, all through updates
+type gameDownload struct {
+	updates chan tea.Msg
+	after   tea.Cmd // Run once the download has finished
+}
+
+// startGameDownload runs a download in the background and reports on it through the model
+// run gets the callbacks to pass to hytale; after (may be nil) runs once it has finished
+func startGameDownload(run func(ctx context.Context, progress hytale.ProgressCallback, deviceCode hytale.DeviceCodeCallback) commandFinishedMsg, after tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithCancel(context.Background())
+		SetInstallCancel(cancel)
+		d := &gameDownload{
+			updates: make(chan tea.Msg, 64),
+			after:   after,
+		}
+
+		go func() {
+			defer close(d.updates)
+			defer CancelInstall()
+
+			// Only whole percents reach the progress bar, so a fast download doesn't flood the model
+			lastPercent, lastLabel := -1, ""
+			progress := func(percent float64, label string) {
+				if int(percent*100) == lastPercent && label == lastLabel {
+					return
+				}
+				lastPercent, lastLabel = int(percent*100), label
+				d.updates <- progressMsg{percent: percent, label: label}
+			}
+			deviceCode := func(code hytale.DeviceCode) {
+				d.updates <- deviceCodeMsg{code: code}
+			}
+			d.updates <- run(ctx, progress, deviceCode)
+		}()
+
+		return gameDownloadStartedMsg{download: d}
+	}
+}
+
+// wait returns the next progress, sign-in or completion message
+func (d *gameDownload) wait() tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-d.updates
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+// renderDeviceCode renders the sign-in prompt hytale-downloader is waiting on
+func (m model) renderDeviceCode() string {
+	content := selectedStyle.Render("🔑 Sign in to download the game") + "\n\n"
+	content += "hytale-downloader needs you to sign in to your Hytale account:\n\n"
+	content += fmt.Sprintf("  1. Open  %s\n", selectedStyle.Render(m.deviceCode.URL))
+	content += fmt.Sprintf("  2. Enter %s\n\n", selectedStyle.Render(m.deviceCode.Code))
+	content += dimmedStyle.Render("The download continues once you have signed in.")
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("214")).
+		Padding(1, 2).
+		Render(content)
+}
@@ -0,0 +1,129 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sivert-io/hytale-server-manager/src/internal/hytale"
+)
+
+// Downloaded game versions message (for the rollback picker)
+type gameVersionsMsg struct {
+	versions []hytale.GameVersion
+	err      error
+}
+
+// loadGameVersionsGo lists the downloaded game versions
+func loadGameVersionsGo() tea.Cmd {
+	return func() tea.Msg {
+		if hytale.DetectNumServers() == 0 {
+			return gameVersionsMsg{err: hytale.ErrNotInstalled}
+		}
+		versions, err := hytale.ListGameVersions()
+		if err == nil && len(versions) == 0 {
+			err = fmt.Errorf("no game versions downloaded yet - run Update Game first")
+		}
+		return gameVersionsMsg{versions: versions, err: err}
+	}
+}
+
+// runRollbackGo switches the servers following a game version's patchline to it and restarts the running ones
+func runRollbackGo(version hytale.GameVersion) tea.Cmd {
+	return func() tea.Msg {
+		manifest := hytale.LoadManifestOrDefault()
+		var servers []int
+		for server := 1; server <= hytale.DetectNumServers(); server++ {
+			if hytale.ServerPatchline(manifest, server) == version.Patchline {
+				servers = append(servers, server)
+			}
+		}
+		if len(servers) == 0 {
+			return commandFinishedMsg{err: fmt.Errorf("no servers follow the %s patchline", version.Patchline)}
+		}
+		tm := hytale.NewTmuxManagerFromManifest(manifest)
+		results, err := tm.RollbackGame(context.Background(), servers, version.Version)
+		var output strings.Builder
+		failed := 0
+		for _, result := range results {
+			if result.Err != nil {
+				failed++
+				output.WriteString(fmt.Sprintf("❌ Server %d: %v\n", result.Server, result.Err))
+				continue
+			}
+			output.WriteString(fmt.Sprintf("✅ Server %d: %s\n", result.Server, result.Detail))
+		}
+		if err == nil && failed > 0 {
+			err = fmt.Errorf("%d of %d server(s) could not be rolled back", failed, len(servers))
+		}
+		return commandFinishedMsg{output: output.String(), err: err}
+	}
+}
+
+// updateGameVersions handles keys in the game version picker
+// Returns handled=false for keys the main handler deals with (navigation, esc)
+func (m model) updateGameVersions(msg tea.KeyMsg) (model, tea.Cmd, bool) {
+	if m.gameVersionConfirm {
+		m.gameVersionConfirm = false
+		switch msg.String() {
+		case "y", "Y":
+			version := m.gameVersions[m.gameVersionCursor]
+			m.running = true
+			m.actionTitle = "⏪ Roll Back Game to " + version.Version
+			return m, tea.Batch(
+				sendActivityLog(fmt.Sprintf("Switching %s servers to game version %s...", version.Patchline, version.Version)),
+				runRollbackGo(version),
+			), true
+		case "ctrl+c":
+			return m, nil, false
+		}
+		return m, nil, true
+	}
+
+	if msg.String() == "enter" {
+		if len(m.gameVersions) > 0 {
+			m.gameVersionConfirm = true
+		}
+		return m, nil, true
+	}
+	return m, nil, false
+}
+
+// renderGameVersions renders the downloaded game versions and which servers run them
+func (m model) renderGameVersions() string {
+	s := titleStyle.Render(" ⏪ Roll Back Game") + "\n\n"
+	s += dimmedStyle.Render("Downloaded game versions, newest first (★ = the patchline's master-install)") + "\n\n"
+
+	for i, version := range m.gameVersions {
+		cursor := "  "
+		if i == m.gameVersionCursor {
+			cursor = selectedStyle.Render("▶ ")
+		}
+		marker := " "
+		if version.Active {
+			marker = "★"
+		}
+		servers := make([]string, len(version.Servers))
+		for j, server := range version.Servers {
+			servers[j] = strconv.Itoa(server)
+		}
+		running := "no servers"
+		if len(servers) > 0 {
+			running = "servers " + strings.Join(servers, ", ")
+		}
+		row := fmt.Sprintf("%s %-30s %-12s %s  %s", marker, version.Version, version.Patchline, version.InstalledAt.Format("2006-01-02 15:04"), running)
+		if i == m.gameVersionCursor {
+			row = selectedStyle.Render(row)
+		}
+		s += cursor + row + "\n"
+	}
+
+	if m.gameVersionConfirm {
+		version := m.gameVersions[m.gameVersionCursor]
+		s += "\n" + consoleErrorStyle.Render(fmt.Sprintf("Switch all %s servers to %s? Running servers are stopped and started again. (y/N)", version.Patchline, version.Version)) + "\n"
+	}
+	s += "\n" + dimmedStyle.Render("Enter: Switch the Patchline's Servers  |  hsm game rollback VERSION N: One Server  |  Esc: Back")
+	return s
+}
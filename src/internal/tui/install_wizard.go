@@ -100,6 +100,13 @@ func newInstallWizard() installWizard {
 				required:    false,
 				description: "Java VM arguments (memory, GC, AOT cache). Recommended: -Xms6G -Xmx6G -XX:+UseG1GC -XX:AOTCache=HytaleServer.aot",
 			},
+			{
+				label:       "Process Backend",
+				value:       hytale.BackendTmux,
+				kind:        fieldToggle,
+				required:    false,
+				description: "tmux: servers run in tmux sessions. systemd: servers run as hytale-server@N units that restart on failure and survive reboots (Press Enter to toggle)",
+			},
 			{
 				label:       "Enable Backups",
 				value:       "Yes",
@@ -211,6 +218,18 @@ func (w installWizard) Update(msg tea.Msg) (installWizard, tea.Cmd) {
 					nextIndex := (currentIndex + 1) % len(gameModes)
 					w.fields[w.cursor].value = gameModes[nextIndex]
 					w.fields[w.cursor].input.SetValue(gameModes[nextIndex])
+				} else if w.fields[w.cursor].label == "Process Backend" {
+					backends := hytale.ValidProcessBackends()
+					currentIndex := 0
+					for i, backend := range backends {
+						if backend == currentValue {
+							currentIndex = i
+							break
+						}
+					}
+					nextBackend := backends[(currentIndex+1)%len(backends)]
+					w.fields[w.cursor].value = nextBackend
+					w.fields[w.cursor].input.SetValue(nextBackend)
 				} else if w.fields[w.cursor].label == "Enable Backups" {
 					// Toggle Yes/No
 					if currentValue == "Yes" {
@@ -365,11 +384,12 @@ func (w installWizard) submit() tea.Cmd {
 	gameMode := w.fields[6].value
 	serverPassword := w.fields[7].value
 	jvmArgs := w.fields[8].value
-	backupEnabled := w.fields[9].value == "Yes"
-	backupFrequency, _ := strconv.Atoi(w.fields[10].value)
-	oauthClientID := w.fields[11].value
-	oauthClientSecret := w.fields[12].value
-	oauthAccessToken := w.fields[13].value
+	processBackend := w.fields[9].value
+	backupEnabled := w.fields[10].value == "Yes"
+	backupFrequency, _ := strconv.Atoi(w.fields[11].value)
+	oauthClientID := w.fields[12].value
+	oauthClientSecret := w.fields[13].value
+	oauthAccessToken := w.fields[14].value
 	
 	// Validate numServers
 	if numServers < 1 {
@@ -453,6 +473,7 @@ func (w installWizard) submit() tea.Cmd {
 		MaxViewRadius:     maxViewRadius,
 		GameMode:          gameMode,
 		JVMArgs:           jvmArgs,
+		ProcessBackend:    processBackend,
 		BackupEnabled:     backupEnabled,
 		BackupFrequency:   backupFrequency,
 		OAuthClientID:     oauthClientID,
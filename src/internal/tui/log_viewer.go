@@ -0,0 +1,505 @@
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sivert-io/hytale-server-manager/src/internal/hytale"
+)
+
+const (
+	// logFollowInterval is how often the log viewer polls for new console output
+	logFollowInterval = 1 * time.Second
+
+	// logFetchLines is the console window fetched on every poll (new lines are found by diffing)
+	logFetchLines = 300
+
+	// logBufferLines is how many lines each pane keeps
+	logBufferLines = 5000
+)
+
+// logLevel is the minimum level the log viewer shows
+type logLevel int
+
+const (
+	levelAll logLevel = iota
+	levelInfo
+	levelWarn
+	levelSevere
+)
+
+func (l logLevel) String() string {
+	switch l {
+	case levelInfo:
+		return "INFO+"
+	case levelWarn:
+		return "WARN+"
+	case levelSevere:
+		return "SEVERE"
+	default:
+		return "ALL"
+	}
+}
+
+var (
+	severeLinePattern = regexp.MustCompile(`\b(SEVERE|ERROR|FATAL)\b`)
+	warnLinePattern   = regexp.MustCompile(`\b(WARN|WARNING)\b`)
+	infoLinePattern   = regexp.MustCompile(`\bINFO\b`)
+	// Stack trace lines belong to the message above them
+	stackTracePattern = regexp.MustCompile(`^(\[[^\]]*\] )?(\s+at |\s*Caused by: |\s+\.\.\. \d+ more|\s*[\w.$]+(Exception|Error)(: |$))`)
+
+	severeLineStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	warnLineStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	stackLineStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("167"))
+	matchStyle      = lipgloss.NewStyle().Reverse(true)
+	paneTitleStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+)
+
+// logLine is a console line with the level it was classified as
+type logLine struct {
+	text  string
+	level logLevel // levelAll for lines without a level
+	stack bool     // Part of a stack trace
+}
+
+// logPane tails one server's console
+type logPane struct {
+	server   int
+	lines    []logLine
+	previous []string // Last fetched window, diffed against the next one
+	err      string
+	viewport viewport.Model
+	follow   bool  // Keep scrolled to the bottom as lines arrive
+	visible  []int // Indexes into lines that pass the level filter
+}
+
+// logViewer is the live-follow log view for one or more servers (split view)
+type logViewer struct {
+	panes  []logPane
+	focus  int
+	paused bool
+	level  logLevel
+
+	searching bool
+	search    textinput.Model
+	query     string
+	pattern   *regexp.Regexp // query as a case-insensitive literal
+	matches   []int          // Visible line numbers in the focused pane that match query
+	match     int
+
+	width  int
+	height int
+	gen    int // Identifies this viewer's poll loop so a closed viewer's ticks are dropped
+}
+
+// Log viewer messages
+type logTickMsg struct {
+	gen int
+}
+
+type logLinesMsg struct {
+	gen    int
+	server int
+	output string
+	err    error
+}
+
+// logViewerGen is incremented for every viewer that is opened
+var logViewerGen int
+
+func newLogViewer(servers []int, width, height int) logViewer {
+	logViewerGen++
+	search := textinput.New()
+	search.Prompt = "/"
+	search.Placeholder = "search"
+
+	v := logViewer{
+		search: search,
+		gen:    logViewerGen,
+	}
+	for _, server := range servers {
+		v.panes = append(v.panes, logPane{
+			server:   server,
+			viewport: viewport.New(80, 10),
+			follow:   true,
+		})
+	}
+	v.resize(width, height)
+	return v
+}
+
+// Init fetches the first window of every pane
+func (v logViewer) Init() tea.Cmd {
+	return v.fetchAll()
+}
+
+// fetchAll reads the console of every pane
+func (v logViewer) fetchAll() tea.Cmd {
+	cmds := make([]tea.Cmd, 0, len(v.panes))
+	for _, pane := range v.panes {
+		cmds = append(cmds, fetchLogLinesGo(v.gen, pane.server))
+	}
+	return tea.Batch(cmds...)
+}
+
+// fetchLogLinesGo reads the latest console window of a server
+func fetchLogLinesGo(gen, server int) tea.Cmd {
+	return func() tea.Msg {
+		tm := hytale.NewTmuxManagerFromManifest(hytale.LoadManifestOrDefault())
+		output, err := tm.Logs(server, logFetchLines)
+		return logLinesMsg{gen: gen, server: server, output: output, err: err}
+	}
+}
+
+// scheduleLogTick schedules the next poll
+func scheduleLogTick(gen int) tea.Cmd {
+	return tea.Tick(logFollowInterval, func(time.Time) tea.Msg {
+		return logTickMsg{gen: gen}
+	})
+}
+
+// resize lays the panes out on top of each other
+func (v *logViewer) resize(width, height int) {
+	if width <= 0 {
+		width = 80
+	}
+	if height <= 0 {
+		height = 24
+	}
+	v.width = width
+	v.height = height
+	if len(v.panes) == 0 {
+		return
+	}
+
+	// Title, help and search lines take ~8 rows; each pane has its own title row
+	available := height - 8 - len(v.panes)
+	paneHeight := available / len(v.panes)
+	if paneHeight < 3 {
+		paneHeight = 3
+	}
+	for i := range v.panes {
+		v.panes[i].viewport.Width = width - 4
+		v.panes[i].viewport.Height = paneHeight
+		v.render(i)
+	}
+}
+
+func (v logViewer) Update(msg tea.Msg) (logViewer, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.resize(msg.Width, msg.Height)
+		return v, nil
+
+	case logTickMsg:
+		if msg.gen != v.gen {
+			return v, nil
+		}
+		return v, v.fetchAll()
+
+	case logLinesMsg:
+		if msg.gen != v.gen {
+			return v, nil
+		}
+		for i := range v.panes {
+			if v.panes[i].server != msg.server {
+				continue
+			}
+			v.appendOutput(i, msg.output, msg.err)
+			if !v.paused {
+				v.render(i)
+			}
+		}
+		// One tick per round of fetches: schedule it when the last pane has reported
+		if msg.server == v.panes[len(v.panes)-1].server {
+			return v, scheduleLogTick(v.gen)
+		}
+		return v, nil
+
+	case tea.KeyMsg:
+		if v.searching {
+			return v.updateSearch(msg)
+		}
+		return v.updateKeys(msg)
+	}
+	return v, nil
+}
+
+// updateSearch handles keys while the search prompt is open (incremental search)
+func (v logViewer) updateSearch(msg tea.KeyMsg) (logViewer, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		v.searching = false
+		v.search.Blur()
+		return v, nil
+	case "esc":
+		v.searching = false
+		v.search.Blur()
+		v.setQuery("")
+		return v, nil
+	}
+
+	var cmd tea.Cmd
+	v.search, cmd = v.search.Update(msg)
+	if v.search.Value() != v.query {
+		v.setQuery(v.search.Value())
+	}
+	return v, cmd
+}
+
+// updateKeys handles keys while browsing
+func (v logViewer) updateKeys(msg tea.KeyMsg) (logViewer, tea.Cmd) {
+	pane := &v.panes[v.focus]
+	switch msg.String() {
+	case " ", "p":
+		v.paused = !v.paused
+		if !v.paused {
+			for i := range v.panes {
+				v.render(i)
+			}
+		}
+	case "/":
+		v.searching = true
+		v.search.SetValue(v.query)
+		v.search.CursorEnd()
+		v.search.Focus()
+		return v, textinput.Blink
+	case "n":
+		v.jumpMatch(1)
+	case "N":
+		v.jumpMatch(-1)
+	case "L":
+		v.level = (v.level + 1) % (levelSevere + 1)
+		for i := range v.panes {
+			v.render(i)
+		}
+		v.findMatches()
+	case "tab":
+		v.focus = (v.focus + 1) % len(v.panes)
+		v.findMatches()
+	case "shift+tab":
+		v.focus = (v.focus + len(v.panes) - 1) % len(v.panes)
+		v.findMatches()
+	case "up", "k":
+		pane.viewport.LineUp(1)
+		pane.follow = false
+	case "down", "j":
+		pane.viewport.LineDown(1)
+		pane.follow = pane.viewport.AtBottom()
+	case "pgup", "b":
+		pane.viewport.ViewUp()
+		pane.follow = false
+	case "pgdown", "f":
+		pane.viewport.ViewDown()
+		pane.follow = pane.viewport.AtBottom()
+	case "g", "home":
+		pane.viewport.GotoTop()
+		pane.follow = false
+	case "G", "end":
+		pane.viewport.GotoBottom()
+		pane.follow = true
+	}
+	return v, nil
+}
+
+// appendOutput adds the lines in output that weren't in the previous window
+func (v *logViewer) appendOutput(i int, output string, err error) {
+	pane := &v.panes[i]
+	if err != nil {
+		pane.err = err.Error()
+		return
+	}
+	pane.err = ""
+
+	current := hytale.SplitLogLines(output)
+	newLines := hytale.NewLogLines(pane.previous, current)
+	pane.previous = current
+
+	for _, text := range newLines {
+		pane.lines = append(pane.lines, classifyLogLine(text, pane.lines))
+	}
+	if len(pane.lines) > logBufferLines {
+		pane.lines = pane.lines[len(pane.lines)-logBufferLines:]
+	}
+}
+
+// classifyLogLine works out a line's level; stack trace lines take the level of the line they belong to
+func classifyLogLine(text string, previous []logLine) logLine {
+	line := logLine{text: text}
+	switch {
+	case stackTracePattern.MatchString(text):
+		line.stack = true
+		line.level = levelSevere
+		if len(previous) > 0 && previous[len(previous)-1].level > levelAll {
+			line.level = previous[len(previous)-1].level
+		}
+	case severeLinePattern.MatchString(text):
+		line.level = levelSevere
+	case warnLinePattern.MatchString(text):
+		line.level = levelWarn
+	case infoLinePattern.MatchString(text):
+		line.level = levelInfo
+	}
+	return line
+}
+
+// render rebuilds a pane's viewport from its lines, applying the level filter and search highlighting
+func (v *logViewer) render(i int) {
+	pane := &v.panes[i]
+	pane.visible = pane.visible[:0]
+
+	var b strings.Builder
+	for idx, line := range pane.lines {
+		if v.level != levelAll && line.level < v.level {
+			continue
+		}
+		pane.visible = append(pane.visible, idx)
+		b.WriteString(v.styleLine(line))
+		b.WriteString("\n")
+	}
+
+	pane.viewport.SetContent(b.String())
+	if pane.follow {
+		pane.viewport.GotoBottom()
+	}
+	if i == v.focus {
+		v.findMatches()
+	}
+}
+
+// styleLine colourises a line by level and highlights search matches
+func (v *logViewer) styleLine(line logLine) string {
+	style := lipgloss.NewStyle()
+	switch {
+	case line.stack:
+		style = stackLineStyle
+	case line.level == levelSevere:
+		style = severeLineStyle
+	case line.level == levelWarn:
+		style = warnLineStyle
+	}
+
+	if v.query == "" {
+		return style.Render(line.text)
+	}
+
+	// Highlight every case-insensitive occurrence of the query
+	// Offsets come from the original text: lowercasing can change a line's length in bytes
+	var b strings.Builder
+	pos := 0
+	for _, loc := range v.pattern.FindAllStringIndex(line.text, -1) {
+		b.WriteString(style.Render(line.text[pos:loc[0]]))
+		b.WriteString(matchStyle.Render(line.text[loc[0]:loc[1]]))
+		pos = loc[1]
+	}
+	b.WriteString(style.Render(line.text[pos:]))
+	return b.String()
+}
+
+// setQuery changes the search query and jumps to the nearest match
+func (v *logViewer) setQuery(query string) {
+	v.query = query
+	v.pattern = regexp.MustCompile("(?i)" + regexp.QuoteMeta(query))
+	for i := range v.panes {
+		v.render(i)
+	}
+	v.findMatches()
+	if len(v.matches) > 0 {
+		// Incremental search: jump to the first match at or below the top of the view
+		pane := &v.panes[v.focus]
+		v.match = 0
+		for j, line := range v.matches {
+			if line >= pane.viewport.YOffset {
+				v.match = j
+				break
+			}
+		}
+		v.showMatch()
+	}
+}
+
+// findMatches finds the visible lines of the focused pane that contain the query
+func (v *logViewer) findMatches() {
+	v.matches = v.matches[:0]
+	if v.query == "" || len(v.panes) == 0 {
+		return
+	}
+	pane := &v.panes[v.focus]
+	for n, idx := range pane.visible {
+		if v.pattern.MatchString(pane.lines[idx].text) {
+			v.matches = append(v.matches, n)
+		}
+	}
+	if v.match >= len(v.matches) {
+		v.match = 0
+	}
+}
+
+// jumpMatch moves to the next (1) or previous (-1) match
+func (v *logViewer) jumpMatch(dir int) {
+	if len(v.matches) == 0 {
+		return
+	}
+	v.match = (v.match + dir + len(v.matches)) % len(v.matches)
+	v.showMatch()
+}
+
+// showMatch scrolls the focused pane so the current match is visible
+func (v *logViewer) showMatch() {
+	pane := &v.panes[v.focus]
+	line := v.matches[v.match]
+	pane.viewport.SetYOffset(line - pane.viewport.Height/2)
+	pane.follow = false
+}
+
+func (v logViewer) View() string {
+	title := " 📜 Live Logs"
+	if len(v.panes) == 1 {
+		title = fmt.Sprintf(" 📜 Server %d Live Logs", v.panes[0].server)
+	}
+	s := titleStyle.Render(title) + "\n"
+
+	state := "following"
+	if v.paused {
+		state = "⏸ paused"
+	}
+	s += dimmedStyle.Render(fmt.Sprintf("%s  |  level: %s", state, v.level)) + "\n"
+
+	for i, pane := range v.panes {
+		header := fmt.Sprintf("Server %d", pane.server)
+		if pane.err != "" {
+			header += " - " + pane.err
+		} else if !pane.follow {
+			header += " (scrolled)"
+		}
+		if len(v.panes) > 1 && i == v.focus {
+			header = paneTitleStyle.Render("▶ " + header)
+		} else {
+			header = dimmedStyle.Render("  " + header)
+		}
+		s += header + "\n" + pane.viewport.View() + "\n"
+	}
+
+	if v.searching {
+		s += v.search.View() + "\n"
+	} else if v.query != "" {
+		if len(v.matches) == 0 {
+			s += dimmedStyle.Render(fmt.Sprintf("/%s - no matches", v.query)) + "\n"
+		} else {
+			s += dimmedStyle.Render(fmt.Sprintf("/%s - match %d of %d", v.query, v.match+1, len(v.matches))) + "\n"
+		}
+	}
+
+	help := "Space: Pause  |  /: Search  |  n/N: Next/Prev  |  L: Level  |  ↑/↓ g/G: Scroll"
+	if len(v.panes) > 1 {
+		help += "  |  Tab: Next Pane"
+	}
+	s += dimmedStyle.Render(help + "  |  Esc: Back")
+	return s
+}
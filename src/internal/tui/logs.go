@@ -0,0 +1,79 @@
+package tui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sivert-io/hytale-server-manager/src/internal/hytale"
+)
+
+// logFileViewLines is how many lines of a historic log file are loaded into the viewer
+const logFileViewLines = 2000
+
+// Log files message (console logs and crash reports of a server)
+type logFilesMsg struct {
+	server int
+	files  []hytale.ConsoleLogFile
+	err    error
+}
+
+// loadLogFilesGo lists a server's console logs and crash reports
+func loadLogFilesGo(server int) tea.Cmd {
+	return func() tea.Msg {
+		files, err := hytale.ListConsoleLogs(server)
+		return logFilesMsg{server: server, files: files, err: err}
+	}
+}
+
+// runViewLogFileGo loads a historic console log or crash report into the viewer
+func runViewLogFileGo(server int, file hytale.ConsoleLogFile) tea.Cmd {
+	return func() tea.Msg {
+		content, err := hytale.ReadLogFile(file.Path, logFileViewLines)
+		if err != nil {
+			return commandFinishedMsg{
+				output: "",
+				err:    fmt.Errorf("failed to read %s: %w", file.Name, err),
+			}
+		}
+		return viewportContentMsg{
+			content: content,
+			title:   fmt.Sprintf("Server %d - %s", server, file.Name),
+		}
+	}
+}
+
+// renderLogFiles renders the log file picker (latest output first, then files newest first)
+func (m model) renderLogFiles() string {
+	s := titleStyle.Render(fmt.Sprintf(" 📋 Server %d Logs", m.logServer)) + "\n\n"
+
+	entries := []string{"Latest output"}
+	details := []string{"current console (live)"}
+	for _, file := range m.logFiles {
+		entries = append(entries, file.Name)
+		details = append(details, fmt.Sprintf("%s, %s", file.ModTime.Format("2006-01-02 15:04"), formatLogSize(file.Size)))
+	}
+
+	for i, entry := range entries {
+		cursor := "  "
+		text := entry
+		if i == m.logFileCursor {
+			cursor = selectedStyle.Render("▶ ")
+			text = selectedStyle.Render(text)
+		}
+		s += fmt.Sprintf("%s%s %s\n", cursor, text, dimmedStyle.Render("- "+details[i]))
+	}
+	s += "\n" + dimmedStyle.Render("Enter: Open  |  Esc: Back")
+	return s
+}
+
+// formatLogSize formats a file size for the log file picker
+func formatLogSize(size int64) string {
+	switch {
+	case size >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1024*1024))
+	case size >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(size)/1024)
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
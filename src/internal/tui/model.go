@@ -2,9 +2,12 @@ package tui
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	"github.com/sivert-io/hytale-server-manager/src/internal/hytale"
 )
@@ -21,6 +24,14 @@ const (
 	viewServerStatus
 	viewServerSelection
 	viewConfirmWipe
+	viewLogFiles
+	viewLogViewer
+	viewConsole
+	viewBackups
+	viewSchedules
+	viewWarnedRestart
+	viewDrift
+	viewGameVersions
 )
 
 // Tabs
@@ -54,6 +65,17 @@ const (
 	itemInstallDependencies
 	itemCheckUpdates
 	itemWipeEverything
+	itemStartServers
+	itemStopServers
+	itemRestartServers
+	itemFollowLogs
+	itemConsole
+	itemBackups
+	itemSchedules
+	itemWarnedRestart
+	itemDrift
+	itemRollbackGame
+	itemRollingUpdate
 )
 
 // Wizard cancel message
@@ -130,6 +152,52 @@ type model struct {
 	// Viewport for logs/status
 	viewport viewport.Model
 	viewportContent string
+	viewportTitle   string
+
+	// Log file picker (console logs and crash reports of one server)
+	logServer     int
+	logFiles      []hytale.ConsoleLogFile
+	logFileCursor int
+
+	// Backup browser (game backups of one server and HSM snapshots)
+	backupServer       int
+	backups            []backupEntry
+	backupCursor       int
+	backupConfirm      string // "restore", "delete" or "prune" while waiting for y/N
+	backupNotice       string
+	backupPrunePreview []string // Retention dry run shown while backupConfirm is "prune"
+
+	// Scheduled jobs view
+	schedules     *hytale.ScheduleConfig
+	scheduleState *hytale.SchedulerState
+
+	// Warned restart (countdown running in the background)
+	restart        *warnedRestart
+	restartRolling bool // Restart the selected servers one after another
+
+	// Installation or game update running in the background
+	download   *gameDownload
+	deviceCode *hytale.DeviceCode // Sign-in prompt hytale-downloader is waiting on
+
+	// Config editor (form over config.json of the selected servers)
+	configEditor configEditor
+
+	// Drift report
+	drift        []hytale.ServerDrift
+	driftCursor  int
+	driftChecked map[int]bool
+	driftConfirm bool // Waiting for y/N before reconciling
+
+	// Game version picker (rollback)
+	gameVersions       []hytale.GameVersion
+	gameVersionCursor  int
+	gameVersionConfirm bool // Waiting for y/N before switching servers
+
+	// Live-follow log viewer
+	logViewer logViewer
+
+	// Console prompt
+	console serverConsole
 	
 	// Progress tracking
 	progress progressModel
@@ -140,11 +208,14 @@ type model struct {
 	serverList     []int
 	// Track which action we're doing in server selection
 	serverSelectionAction itemKind
+	// Servers ticked for multi-select actions (start/stop/restart individual servers)
+	selectedServers map[int]bool
 	
 	// Update status
 	updateAvailable bool
 	latestVersion    string
 	currentVersion   string
+	gameUpdate      hytale.GameUpdateCheck // Installed vs latest game version (hytale-downloader -print-version)
 	
 	// Action result (receipt)
 	actionResult string
@@ -157,9 +228,12 @@ type model struct {
 }
 
 type serverStatus struct {
-	ID     int
-	Status string // "running", "stopped"
-	Port   int
+	ID          int
+	Status      string // "running", "stopped", "crashed", "crash-looping"
+	Port        int
+	Session     string
+	Patchline   string
+	GameVersion string
 }
 
 func initialModel() model {
@@ -187,10 +261,10 @@ func initialModel() model {
 }
 
 func getTabItems(t tab) []menuItem {
-	return getTabItemsWithUpdate(t, false, "")
+	return getTabItemsWithUpdate(t, false, "", hytale.GameUpdateCheck{})
 }
 
-func getTabItemsWithUpdate(t tab, updateAvailable bool, latestVersion string) []menuItem {
+func getTabItemsWithUpdate(t tab, updateAvailable bool, latestVersion string, gameUpdate hytale.GameUpdateCheck) []menuItem {
 	switch t {
 	case tabInstall:
 		items := []menuItem{
@@ -208,18 +282,39 @@ func getTabItemsWithUpdate(t tab, updateAvailable bool, latestVersion string) []
 		return items
 
 	case tabUpdates:
-		return []menuItem{
+		items := []menuItem{
 			{title: "Check for Updates", description: "Check for HSM updates and install if available", kind: itemCheckUpdates},
-			{title: "Update Game", description: "Download and install latest Hytale server", kind: itemUpdateGame},
+			{title: "Update Game", description: "Download and install latest Hytale server (running servers keep their version)", kind: itemUpdateGame},
+			{title: "Rolling Game Update", description: "Update servers one at a time with a countdown, health checking each and rolling back on failure", kind: itemRollingUpdate},
+			{title: "Roll Back Game...", description: "Switch servers back to a previously downloaded game version", kind: itemRollbackGame},
 			{title: "Update Plugins", description: "Update server plugins and addons", kind: itemUpdatePlugins},
 		}
+		// Add game update option at the end if available
+		if gameUpdate.Available() {
+			installed := gameUpdate.Installed
+			if installed == "" {
+				installed = "unknown"
+			}
+			items = append(items, menuItem{
+				title: fmt.Sprintf("Game update available: %s → %s", installed, gameUpdate.Latest),
+				description: "Download the new game version and update all servers",
+				kind: itemUpdateGame,
+			})
+		}
+		return items
 
 	case tabServers:
 		items := []menuItem{
 			{title: "Start All Servers", description: "Start all Hytale server instances", kind: itemStartAllGo},
 			{title: "Stop All Servers", description: "Stop all running server instances", kind: itemStopAllGo},
 			{title: "Restart All Servers", description: "Restart all server instances", kind: itemRestartAllGo},
-			{title: "View Server Logs", description: "View logs for a specific server", kind: itemViewLogs},
+			{title: "Start Servers...", description: "Start selected server instances", kind: itemStartServers},
+			{title: "Stop Servers...", description: "Stop selected server instances", kind: itemStopServers},
+			{title: "Restart Servers...", description: "Restart selected server instances", kind: itemRestartServers},
+			{title: "Warned Restart...", description: "Count down in-game, then restart selected servers (together or rolling)", kind: itemWarnedRestart},
+			{title: "View Server Logs", description: "Follow a server's console or open older log files", kind: itemViewLogs},
+			{title: "Follow Server Logs...", description: "Tail the consoles of selected servers in a split view", kind: itemFollowLogs},
+			{title: "Server Console...", description: "Send console commands to selected servers", kind: itemConsole},
 			{title: "Add Servers", description: "Add multiple server instances", kind: itemAddServers},
 			{title: "Remove Servers", description: "Remove multiple server instances", kind: itemRemoveServers},
 			{title: "Scale Up Servers", description: "Add one server instance", kind: itemScaleUp},
@@ -237,8 +332,11 @@ func getTabItemsWithUpdate(t tab, updateAvailable bool, latestVersion string) []
 
 	case tabTools:
 		items := []menuItem{
-			{title: "Edit Server Configs", description: "Edit shared server configuration", kind: itemEditConfigs},
+			{title: "Edit Server Configs", description: "Edit config.json of one, several or all servers", kind: itemEditConfigs},
 			{title: "View Server Status", description: "View detailed server status", kind: itemViewServerStatus},
+			{title: "Backups", description: "Browse, inspect, restore and delete world backups", kind: itemBackups},
+			{title: "Scheduled Jobs", description: "View scheduled restarts, snapshots and updates and their history", kind: itemSchedules},
+			{title: "Drift Report", description: "Find servers whose config, mods or game files differ and reconcile them", kind: itemDrift},
 		}
 		// Add update option at the end if available
 		if updateAvailable {
@@ -266,6 +364,7 @@ func (m model) Init() tea.Cmd {
 	return tea.Batch(
 		getServerStatus(),
 		checkForUpdates(),
+		checkForGameUpdate(),
 	)
 }
 
@@ -279,13 +378,13 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				// Return to main menu from wizard
 				CancelInstall()
 				m.view = viewMain
-				m.items = getTabItemsWithUpdate(m.tab, m.updateAvailable, m.latestVersion)
+				m.items = getTabItemsWithUpdate(m.tab, m.updateAvailable, m.latestVersion, m.gameUpdate)
 				return m, nil
 			}
 		case wizardCancelMsg:
 			// Return to main menu from wizard
 			m.view = viewMain
-			m.items = getTabItemsWithUpdate(m.tab, m.updateAvailable, m.latestVersion)
+			m.items = getTabItemsWithUpdate(m.tab, m.updateAvailable, m.latestVersion, m.gameUpdate)
 			return m, nil
 		case commandFinishedMsg:
 			// Handle command completion from wizard (e.g., bootstrap)
@@ -301,12 +400,93 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	// The live log viewer handles its own keys (search input, scrolling, panes)
+	switch msg := msg.(type) {
+	case logTickMsg, logLinesMsg:
+		if m.view != viewLogViewer {
+			// Viewer was closed - let its poll loop end
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.logViewer, cmd = m.logViewer.Update(msg)
+		return m, cmd
+	case consoleOutputMsg:
+		var cmd tea.Cmd
+		m.console, cmd = m.console.Update(msg)
+		return m, cmd
+	case tea.KeyMsg:
+		if m.view == viewBackups {
+			if updated, cmd, handled := m.updateBackups(msg); handled {
+				return updated, cmd
+			}
+		}
+		if m.view == viewSchedules {
+			if updated, cmd, handled := m.updateSchedules(msg); handled {
+				return updated, cmd
+			}
+		}
+		if m.view == viewWarnedRestart {
+			if updated, cmd, handled := m.updateWarnedRestart(msg); handled {
+				return updated, cmd
+			}
+		}
+		if m.view == viewDrift {
+			if updated, cmd, handled := m.updateDrift(msg); handled {
+				return updated, cmd
+			}
+		}
+		if m.view == viewGameVersions {
+			if updated, cmd, handled := m.updateGameVersions(msg); handled {
+				return updated, cmd
+			}
+		}
+		if m.view == viewEditServerConfigs {
+			if updated, cmd, handled := m.updateConfigEditor(msg); handled {
+				return updated, cmd
+			}
+		}
+		if m.view == viewConsole {
+			switch msg.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "esc":
+				m.view = viewMain
+				m.items = getTabItemsWithUpdate(m.tab, m.updateAvailable, m.latestVersion, m.gameUpdate)
+				return m, getServerStatus()
+			}
+			var cmd tea.Cmd
+			m.console, cmd = m.console.Update(msg)
+			return m, cmd
+		}
+		if m.view == viewLogViewer {
+			switch msg.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "esc", "q":
+				if !m.logViewer.searching {
+					m.view = viewMain
+					m.items = getTabItemsWithUpdate(m.tab, m.updateAvailable, m.latestVersion, m.gameUpdate)
+					return m, getServerStatus()
+				}
+			}
+			var cmd tea.Cmd
+			m.logViewer, cmd = m.logViewer.Update(msg)
+			return m, cmd
+		}
+	}
+
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
 		m.viewport.Width = msg.Width - 4
 		m.viewport.Height = msg.Height - 10
+		if m.view == viewLogViewer {
+			m.logViewer, _ = m.logViewer.Update(msg)
+		}
+		if m.view == viewConsole {
+			m.console, _ = m.console.Update(msg)
+		}
 		return m, nil
 
 	case wizardCancelMsg:
@@ -332,7 +512,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Switch to previous tab
 			if m.tab > tabInstall {
 				m.tab--
-				m.items = getTabItemsWithUpdate(m.tab, m.updateAvailable, m.latestVersion)
+				m.items = getTabItemsWithUpdate(m.tab, m.updateAvailable, m.latestVersion, m.gameUpdate)
 				m.cursor = 0
 			}
 			return m, nil
@@ -341,7 +521,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Switch to next tab
 			if m.tab < tabAdvanced {
 				m.tab++
-				m.items = getTabItemsWithUpdate(m.tab, m.updateAvailable, m.latestVersion)
+				m.items = getTabItemsWithUpdate(m.tab, m.updateAvailable, m.latestVersion, m.gameUpdate)
 				m.cursor = 0
 			}
 			return m, nil
@@ -351,6 +531,30 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.viewport.LineUp(1)
 				return m, nil
 			}
+			if m.view == viewLogFiles {
+				if m.logFileCursor > 0 {
+					m.logFileCursor--
+				}
+				return m, nil
+			}
+			if m.view == viewDrift {
+				if m.driftCursor > 0 {
+					m.driftCursor--
+				}
+				return m, nil
+			}
+			if m.view == viewGameVersions {
+				if m.gameVersionCursor > 0 {
+					m.gameVersionCursor--
+				}
+				return m, nil
+			}
+			if m.view == viewBackups {
+				if m.backupCursor > 0 {
+					m.backupCursor--
+				}
+				return m, nil
+			}
 			if m.view == viewServerSelection {
 				if m.selectedServer > 0 {
 					m.selectedServer--
@@ -367,6 +571,30 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.viewport.LineDown(1)
 				return m, nil
 			}
+			if m.view == viewLogFiles {
+				if m.logFileCursor < len(m.logFiles) {
+					m.logFileCursor++
+				}
+				return m, nil
+			}
+			if m.view == viewDrift {
+				if m.driftCursor < len(m.drift)-1 {
+					m.driftCursor++
+				}
+				return m, nil
+			}
+			if m.view == viewGameVersions {
+				if m.gameVersionCursor < len(m.gameVersions)-1 {
+					m.gameVersionCursor++
+				}
+				return m, nil
+			}
+			if m.view == viewBackups {
+				if m.backupCursor < len(m.backups)-1 {
+					m.backupCursor++
+				}
+				return m, nil
+			}
 			if m.view == viewServerSelection {
 				if m.selectedServer < len(m.serverList)-1 {
 					m.selectedServer++
@@ -378,7 +606,16 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 
-		case "enter", " ":
+		case " ":
+			// Space ticks servers in multi-select views, otherwise it behaves like Enter
+			if m.view == viewServerSelection && isMultiSelectAction(m.serverSelectionAction) {
+				serverNum := m.serverList[m.selectedServer]
+				m.selectedServers[serverNum] = !m.selectedServers[serverNum]
+				return m, nil
+			}
+			fallthrough
+
+		case "enter":
 			// Handle action result view - return to main menu
 			if m.view == viewActionResult {
 				m.view = viewMain
@@ -396,13 +633,67 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				)
 			}
 			
+			// Handle log file picker - entry 0 is the latest output, the rest are files
+			if m.view == viewLogFiles {
+				if m.logFileCursor == 0 {
+					return m.openLogViewer([]int{m.logServer})
+				}
+				return m, runViewLogFileGo(m.logServer, m.logFiles[m.logFileCursor-1])
+			}
+
 			// Handle server selection view
 			if m.view == viewServerSelection {
 				// Use serverSelectionAction to determine what to do
 				switch m.serverSelectionAction {
+				case itemStartServers, itemStopServers, itemRestartServers:
+					servers := m.checkedServers()
+					if len(servers) == 0 {
+						// Nothing ticked - act on the server under the cursor
+						servers = []int{m.serverList[m.selectedServer]}
+					}
+					m.running = true
+					m.actionTitle = serverActionTitle(m.serverSelectionAction, servers)
+					return m, tea.Batch(
+						sendActivityLog(fmt.Sprintf("%s...", m.actionTitle)),
+						runServerActionGo(m.serverSelectionAction, servers),
+					)
+				case itemWarnedRestart:
+					servers := m.checkedServers()
+					if len(servers) == 0 {
+						servers = []int{m.serverList[m.selectedServer]}
+					}
+					m.running = true
+					m.actionTitle = serverActionTitle(m.serverSelectionAction, servers)
+					var cmd tea.Cmd
+					m.restart, cmd = startWarnedRestart(servers, m.restartRolling)
+					m.view = viewWarnedRestart
+					return m, cmd
+				case itemEditConfigs:
+					servers := m.checkedServers()
+					if len(servers) == 0 {
+						servers = []int{m.serverList[m.selectedServer]}
+					}
+					return m, loadConfigEditorGo(servers)
 				case itemViewLogs:
 					serverNum := m.serverList[m.selectedServer]
-					return m, runViewLogsGo(serverNum)
+					return m, loadLogFilesGo(serverNum)
+				case itemBackups:
+					serverNum := m.serverList[m.selectedServer]
+					return m, loadBackupsGo(serverNum, "")
+				case itemFollowLogs:
+					servers := m.checkedServers()
+					if len(servers) == 0 {
+						servers = []int{m.serverList[m.selectedServer]}
+					}
+					return m.openLogViewer(servers)
+				case itemConsole:
+					servers := m.checkedServers()
+					if len(servers) == 0 {
+						servers = []int{m.serverList[m.selectedServer]}
+					}
+					m.console = newServerConsole(servers, m.width, m.height)
+					m.view = viewConsole
+					return m, textinput.Blink
 				case itemScaleUp:
 					numToAdd := m.serverList[m.selectedServer]
 					m.running = true
@@ -443,7 +734,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						return m, runScaleDownGo()
 					} else if len(m.serverList) > 0 && m.serverList[0] > 0 {
 						serverNum := m.serverList[m.selectedServer]
-						return m, runViewLogsGo(serverNum)
+						return m, loadLogFilesGo(serverNum)
 					}
 				}
 			}
@@ -456,7 +747,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Execute selected action
 			if len(m.items) == 0 {
 				// No items available - refresh items list
-				m.items = getTabItemsWithUpdate(m.tab, m.updateAvailable, m.latestVersion)
+				m.items = getTabItemsWithUpdate(m.tab, m.updateAvailable, m.latestVersion, m.gameUpdate)
 				return m, nil
 			}
 			
@@ -473,7 +764,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.view = viewInstallWizard
 				m.wizard = newInstallWizard()
 				return m, nil
-			case itemViewLogs:
+			case itemViewLogs, itemBackups:
 				// Show server selection
 				numServers := hytale.DetectNumServers()
 				if numServers == 0 {
@@ -485,15 +776,46 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.serverList[i] = i + 1
 				}
 				m.selectedServer = 0
-				m.serverSelectionAction = itemViewLogs
+				m.serverSelectionAction = kind
 				m.view = viewServerSelection
 				return m, nil
-			case itemEditConfigs:
-				m.view = viewEditServerConfigs
-				return m, nil
+			case itemStartServers, itemStopServers, itemRestartServers, itemWarnedRestart, itemFollowLogs, itemConsole, itemEditConfigs:
+				// Show multi-select server list
+				numServers := hytale.DetectNumServers()
+				if numServers == 0 {
+					m.status = "No servers installed"
+					return m, nil
+				}
+				m.serverList = make([]int, numServers)
+				for i := 0; i < numServers; i++ {
+					m.serverList[i] = i + 1
+				}
+				m.selectedServer = 0
+				m.selectedServers = make(map[int]bool)
+				m.serverSelectionAction = kind
+				m.view = viewServerSelection
+				return m, getServerStatus()
 			case itemViewServerStatus:
 				m.view = viewServerStatus
 				return m, getServerStatus()
+			case itemSchedules:
+				return m, loadSchedulesGo()
+			case itemDrift:
+				m.running = true
+				m.driftChecked = make(map[int]bool)
+				m.driftCursor = 0
+				return m, tea.Batch(sendActivityLog("Comparing servers... (hashing game files may take a moment)"), loadDriftGo())
+			case itemRollingUpdate:
+				m.running = true
+				m.actionTitle = "🎮 Rolling Game Update"
+				var cmd tea.Cmd
+				m.restart, cmd = startRollingUpdate()
+				m.view = viewWarnedRestart
+				return m, cmd
+			case itemRollbackGame:
+				m.gameVersionCursor = 0
+				m.gameVersionConfirm = false
+				return m, loadGameVersionsGo()
 			case itemScaleUp:
 				// Show scale up selection (1-5 servers)
 				m.serverList = []int{1, 2, 3, 4, 5}
@@ -568,11 +890,28 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, cmd
 			}
 
+		case "a":
+			// Toggle all servers in multi-select views
+			if m.view == viewServerSelection && isMultiSelectAction(m.serverSelectionAction) {
+				selectAll := len(m.checkedServers()) < len(m.serverList)
+				for _, serverNum := range m.serverList {
+					m.selectedServers[serverNum] = selectAll
+				}
+			}
+			return m, nil
+
+		case "o":
+			// Toggle rolling (one server at a time) for warned restarts
+			if m.view == viewServerSelection && m.serverSelectionAction == itemWarnedRestart {
+				m.restartRolling = !m.restartRolling
+			}
+			return m, nil
+
 		case "esc":
 			// Back to main menu
 			if m.view != viewMain {
 				m.view = viewMain
-				m.items = getTabItemsWithUpdate(m.tab, m.updateAvailable, m.latestVersion)
+				m.items = getTabItemsWithUpdate(m.tab, m.updateAvailable, m.latestVersion, m.gameUpdate)
 				m.cursor = 0
 			}
 			return m, nil
@@ -583,8 +922,23 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.showProgress = true
 		var cmd tea.Cmd
 		m.progress, cmd = m.progress.Update(msg)
+		if m.download != nil {
+			return m, tea.Batch(cmd, m.download.wait())
+		}
 		return m, cmd
 
+	case gameDownloadStartedMsg:
+		m.download = msg.download
+		return m, m.download.wait()
+
+	case deviceCodeMsg:
+		// Shown until the download finishes
+		m.deviceCode = &msg.code
+		if m.download != nil {
+			return m, m.download.wait()
+		}
+		return m, nil
+
 	case progressCompleteMsg:
 		// Hide progress bar
 		m.showProgress = false
@@ -616,17 +970,26 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		// Switch to receipt view
 		m.view = viewActionResult
+		m.deviceCode = nil
+		var after tea.Cmd
+		if m.download != nil {
+			after = m.download.after
+			m.download = nil
+		}
 		// Refresh server status after command completion (but don't overwrite receipt)
-		return m, getServerStatus()
+		return m, tea.Batch(getServerStatus(), after)
 
 	case serverStatusMsg:
 		// Update server statuses
 		m.serverStatuses = make([]serverStatus, len(msg.statuses))
 		for i, st := range msg.statuses {
 			m.serverStatuses[i] = serverStatus{
-				ID:     st.Server,
-				Status: st.Status,
-				Port:   st.Port,
+				ID:          st.Server,
+				Status:      st.Status,
+				Port:        st.Port,
+				Session:     st.Session,
+				Patchline:   st.Patchline,
+				GameVersion: st.GameVersion,
 			}
 		}
 		
@@ -637,15 +1000,127 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.status = "Ready"
 		}
 		
-		// Continue polling only if in main view or server status view
-		if m.view == viewMain || m.view == viewServerStatus {
+		// Continue polling only if in main view, server status view or server selection
+		if m.view == viewMain || m.view == viewServerStatus || m.view == viewServerSelection {
 			return m, pollServerStatus()
 		}
 		return m, nil
 
+	case logFilesMsg:
+		if msg.err != nil || len(msg.files) == 0 {
+			// No log files to pick from - go straight to the live console
+			return m.openLogViewer([]int{msg.server})
+		}
+		m.logServer = msg.server
+		m.logFiles = msg.files
+		m.logFileCursor = 0
+		m.view = viewLogFiles
+		return m, nil
+
+	case driftMsg:
+		if msg.err != nil {
+			m.actionTitle = "🧭 Drift Report"
+			return m, func() tea.Msg { return commandFinishedMsg{err: msg.err} }
+		}
+		m.running = false
+		m.activityLogs = make([]string, 0)
+		m.drift = msg.reports
+		if m.driftCursor >= len(m.drift) {
+			m.driftCursor = 0
+		}
+		m.view = viewDrift
+		return m, nil
+
+	case updateAvailableMsg:
+		m.updateAvailable = msg.available
+		m.latestVersion = msg.latestVersion
+		if m.view == viewMain {
+			m.items = getTabItemsWithUpdate(m.tab, m.updateAvailable, m.latestVersion, m.gameUpdate)
+		}
+		return m, nil
+
+	case gameUpdateMsg:
+		m.gameUpdate = msg.check
+		if m.view == viewMain {
+			m.items = getTabItemsWithUpdate(m.tab, m.updateAvailable, m.latestVersion, m.gameUpdate)
+		}
+		return m, nil
+
+	case gameVersionsMsg:
+		if msg.err != nil {
+			m.actionTitle = "⏪ Roll Back Game"
+			return m, func() tea.Msg { return commandFinishedMsg{err: msg.err} }
+		}
+		m.gameVersions = msg.versions
+		m.gameVersionCursor = 0
+		m.view = viewGameVersions
+		return m, nil
+
+	case backupsMsg:
+		if msg.err != nil {
+			m.actionTitle = fmt.Sprintf("💾 Server %d Backups", msg.server)
+			return m, func() tea.Msg { return commandFinishedMsg{err: msg.err} }
+		}
+		m.running = false
+		m.activityLogs = make([]string, 0)
+		m.backupServer = msg.server
+		m.backups = msg.backups
+		m.backupNotice = msg.notice
+		m.backupConfirm = ""
+		if m.backupCursor >= len(m.backups) || m.view != viewBackups {
+			m.backupCursor = 0
+		}
+		m.view = viewBackups
+		return m, nil
+
+	case warnedRestartProgressMsg:
+		if m.restart == nil {
+			return m, nil
+		}
+		m.restart.lines = append(m.restart.lines, msg.line)
+		return m, m.restart.wait()
+
+	case configEditorMsg:
+		if msg.err != nil {
+			m.actionTitle = "⚙️  Edit Server Configs"
+			return m, func() tea.Msg { return commandFinishedMsg{err: msg.err} }
+		}
+		m.configEditor = msg.editor
+		m.view = viewEditServerConfigs
+		return m, textinput.Blink
+
+	case schedulesMsg:
+		if msg.err != nil {
+			m.actionTitle = "⏰ Scheduled Jobs"
+			return m, func() tea.Msg { return commandFinishedMsg{err: msg.err} }
+		}
+		m.schedules = msg.config
+		m.scheduleState = msg.state
+		m.view = viewSchedules
+		return m, nil
+
+	case backupPruneMsg:
+		if msg.err != nil && len(msg.results) == 0 {
+			m.actionTitle = fmt.Sprintf("💾 Server %d Backups", msg.server)
+			return m, func() tea.Msg { return commandFinishedMsg{err: msg.err} }
+		}
+		m.backupPrunePreview = nil
+		if len(msg.results) == 0 {
+			m.backupPrunePreview = []string{"No retention policy configured in " + hytale.GetBackupConfigPath()}
+		}
+		for _, r := range msg.results {
+			m.backupPrunePreview = append(m.backupPrunePreview, r.String())
+			for _, item := range r.Removed {
+				m.backupPrunePreview = append(m.backupPrunePreview, fmt.Sprintf("  %s (%s)", item.Name, item.Time.Format("2006-01-02 15:04")))
+			}
+		}
+		m.backupConfirm = "prune"
+		return m, nil
+
 	case viewportContentMsg:
 		// Set viewport content and switch to viewport view
 		m.view = viewViewport
+		m.viewportTitle = msg.title
 		m.viewportContent = msg.content
 		m.viewport.SetContent(msg.content)
 		return m, nil
@@ -654,6 +1129,77 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// isMultiSelectAction reports whether a server selection action lets the user tick several servers
+func isMultiSelectAction(kind itemKind) bool {
+	switch kind {
+	case itemStartServers, itemStopServers, itemRestartServers, itemWarnedRestart, itemFollowLogs, itemConsole, itemEditConfigs:
+		return true
+	}
+	return false
+}
+
+// serverActionTitle returns the receipt title for a per-server action
+func serverActionTitle(kind itemKind, servers []int) string {
+	var title string
+	switch kind {
+	case itemStartServers:
+		title = "🚀 Start"
+	case itemStopServers:
+		title = "🛑 Stop"
+	case itemRestartServers:
+		title = "🔄 Restart"
+	case itemWarnedRestart:
+		title = "⏳ Warned Restart:"
+	case itemFollowLogs:
+		title = "📜 Follow Logs:"
+	case itemConsole:
+		title = "⌨️  Console:"
+	case itemEditConfigs:
+		title = "⚙️  Edit Config:"
+	case itemDrift:
+		title = "🧭 Reconcile"
+	}
+	if len(servers) == 0 {
+		return title + " Servers"
+	}
+	names := make([]string, len(servers))
+	for i, server := range servers {
+		names[i] = strconv.Itoa(server)
+	}
+	if len(servers) == 1 {
+		return fmt.Sprintf("%s Server %s", title, names[0])
+	}
+	return fmt.Sprintf("%s Servers %s", title, strings.Join(names, ", "))
+}
+
+// openLogViewer switches to the live log viewer for the given servers
+func (m model) openLogViewer(servers []int) (tea.Model, tea.Cmd) {
+	m.logViewer = newLogViewer(servers, m.width, m.height)
+	m.view = viewLogViewer
+	return m, m.logViewer.Init()
+}
+
+// checkedServers returns the ticked servers in list order
+func (m model) checkedServers() []int {
+	var servers []int
+	for _, serverNum := range m.serverList {
+		if m.selectedServers[serverNum] {
+			servers = append(servers, serverNum)
+		}
+	}
+	return servers
+}
+
+// serverStatusText returns the last polled status for a server (empty if unknown)
+func (m model) serverStatusText(serverNum int) string {
+	for _, st := range m.serverStatuses {
+		if st.ID == serverNum {
+			return st.Status
+		}
+	}
+	return ""
+}
+
 // Helper to send activity log message
 func sendActivityLog(msg string) tea.Cmd {
 	return func() tea.Msg {
@@ -821,9 +1367,37 @@ func (m model) View() string {
 		s += m.wizard.View()
 	} else if m.view == viewViewport {
 		// Viewport view (logs, status, etc.)
-		s += titleStyle.Render(" 📋 Server Logs") + "\n\n"
+		title := m.viewportTitle
+		if title == "" {
+			title = "Server Logs"
+		}
+		s += titleStyle.Render(" 📋 "+title) + "\n\n"
 		s += m.viewport.View()
 		s += "\n" + dimmedStyle.Render("↑/↓: Scroll  |  Esc: Back")
+	} else if m.view == viewLogFiles {
+		// Log file picker
+		s += m.renderLogFiles()
+	} else if m.view == viewBackups {
+		// Backup browser
+		s += m.renderBackups()
+	} else if m.view == viewSchedules {
+		// Scheduled jobs and their history
+		s += m.renderSchedules()
+	} else if m.view == viewDrift {
+		// Drift report
+		s += m.renderDrift()
+	} else if m.view == viewGameVersions {
+		// Game version picker (rollback)
+		s += m.renderGameVersions()
+	} else if m.view == viewWarnedRestart {
+		// Countdown of a warned restart
+		s += m.renderWarnedRestart()
+	} else if m.view == viewLogViewer {
+		// Live-follow log viewer
+		s += m.logViewer.View()
+	} else if m.view == viewConsole {
+		// Console prompt
+		s += m.console.View()
 	} else if m.view == viewServerStatus {
 		// Server status view
 		s += titleStyle.Render(" 📊 Server Status") + "\n\n"
@@ -832,25 +1406,45 @@ func (m model) View() string {
 			s += dimmedStyle.Render("Run the installation wizard to set up servers.")
 		} else {
 			// Table header
-			header := fmt.Sprintf("%-8s %-12s %-8s %-10s", "Server", "Status", "Port", "Session")
+			header := fmt.Sprintf("%-8s %-12s %-8s %-10s %-12s %s", "Server", "Status", "Port", "Session", "Patchline", "Game Version")
 			s += selectedStyle.Render(header) + "\n"
-			s += dimmedStyle.Render("────────────────────────────────────────") + "\n"
+			s += dimmedStyle.Render("────────────────────────────────────────────────────────────────────") + "\n"
 			
 			// Server rows
 			for _, st := range m.serverStatuses {
 				statusColor := "241" // dimmed (stopped)
 				statusText := "stopped"
-				if st.Status == "running" {
+				switch st.Status {
+				case hytale.StatusRunning:
 					statusColor = "46" // green
 					statusText = "running"
+				case hytale.StatusCrashed:
+					statusColor = "214" // orange (restart pending)
+					statusText = "crashed"
+				case hytale.StatusCrashLooping:
+					statusColor = "196" // red
+					statusText = "crash-looping"
 				}
 				
 				statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(statusColor))
-				row := fmt.Sprintf("%-8d %-12s %-8d %-10s",
+				patchline := st.Patchline
+				if patchline != hytale.PatchlineProduction {
+					// Canary servers stand out from the rest
+					patchline = lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render(fmt.Sprintf("%-12s", patchline))
+				} else {
+					patchline = fmt.Sprintf("%-12s", patchline)
+				}
+				gameVersion := st.GameVersion
+				if gameVersion == "" {
+					gameVersion = "master-install"
+				}
+				row := fmt.Sprintf("%-8d %-12s %-8d %-10s %s %s",
 					st.ID,
 					statusStyle.Render(statusText),
 					st.Port,
-					fmt.Sprintf("hytale-server-%d", st.ID),
+					st.Session,
+					patchline,
+					gameVersion,
 				)
 				s += row + "\n"
 			}
@@ -858,7 +1452,37 @@ func (m model) View() string {
 		s += "\n" + dimmedStyle.Render("Esc: Back")
 	} else if m.view == viewServerSelection {
 		// Server selection view (for logs, scale up/down)
-		if len(m.serverList) > 0 && m.serverList[0] <= 5 && len(m.serverList) == 5 {
+		if isMultiSelectAction(m.serverSelectionAction) {
+			// Multi-select for per-server start/stop/restart
+			s += titleStyle.Render(" "+serverActionTitle(m.serverSelectionAction, nil)) + "\n\n"
+			s += dimmedStyle.Render("Select servers (Space to toggle, a to toggle all):") + "\n\n"
+			for i, serverNum := range m.serverList {
+				cursor := "  "
+				if i == m.selectedServer {
+					cursor = selectedStyle.Render("▶ ")
+				}
+				check := "[ ]"
+				if m.selectedServers[serverNum] {
+					check = "[x]"
+				}
+				text := fmt.Sprintf("%s Server %d", check, serverNum)
+				if i == m.selectedServer {
+					text = selectedStyle.Render(text)
+				}
+				s += fmt.Sprintf("%s%s %s\n", cursor, text, dimmedStyle.Render(m.serverStatusText(serverNum)))
+			}
+			hint := "Space: Toggle  |  a: Toggle All  |  Enter: Run  |  Esc: Back"
+			if m.serverSelectionAction == itemWarnedRestart {
+				rolling := "off"
+				if m.restartRolling {
+					rolling = "on"
+				}
+				hint = fmt.Sprintf("Space: Toggle  |  a: Toggle All  |  o: Rolling (%s)  |  Enter: Start Countdown  |  Esc: Back", rolling)
+			} else if m.serverSelectionAction == itemEditConfigs {
+				hint = "Space: Toggle  |  a: Toggle All  |  Enter: Edit  |  Esc: Back"
+			}
+			s += "\n" + dimmedStyle.Render(hint)
+		} else if len(m.serverList) > 0 && m.serverList[0] <= 5 && len(m.serverList) == 5 {
 			// Scale up selection
 			s += titleStyle.Render(" ⬆️  Scale Up Servers") + "\n\n"
 			s += dimmedStyle.Render("Select number of servers to add:") + "\n\n"
@@ -880,8 +1504,12 @@ func (m model) View() string {
 			s += selectedStyle.Render("▶ Confirm Removal") + "\n"
 			s += "\n" + dimmedStyle.Render("Enter: Confirm  |  Esc: Cancel")
 		} else {
-			// Server selection for logs
-			s += titleStyle.Render(" 📋 Select Server for Logs") + "\n\n"
+			// Server selection for logs and backups
+			title, hint := " 📋 Select Server for Logs", "Enter: View Logs  |  Esc: Back"
+			if m.serverSelectionAction == itemBackups {
+				title, hint = " 💾 Select Server for Backups", "Enter: View Backups  |  Esc: Back"
+			}
+			s += titleStyle.Render(title) + "\n\n"
 			for i, serverNum := range m.serverList {
 				cursor := "  "
 				if i == m.selectedServer {
@@ -893,15 +1521,11 @@ func (m model) View() string {
 				}
 				s += fmt.Sprintf("%s%s\n", cursor, text)
 			}
-			s += "\n" + dimmedStyle.Render("Enter: View Logs  |  Esc: Back")
+			s += "\n" + dimmedStyle.Render(hint)
 		}
 	} else if m.view == viewEditServerConfigs {
 		// Config editor view
-		s += titleStyle.Render(" ⚙️  Edit Server Configs") + "\n\n"
-		s += dimmedStyle.Render("Config editor coming soon...") + "\n"
-		s += dimmedStyle.Render("For now, edit config files directly in:") + "\n"
-		s += fmt.Sprintf("  %s\n", hytale.DataDirBase)
-		s += "\n" + dimmedStyle.Render("Esc: Back")
+		s += m.renderConfigEditor()
 	} else if m.view == viewActionResult {
 		// Action result receipt view
 		s += titleStyle.Render(" " + m.actionTitle) + "\n\n"
@@ -971,6 +1595,11 @@ func (m model) View() string {
 		s += dimmedStyle.Render("Press Esc to return")
 	}
 
+	// Show hytale-downloader's sign-in prompt while it waits for it
+	if m.deviceCode != nil {
+		s += "\n" + m.renderDeviceCode()
+	}
+
 	// Show progress bar if active (above status bar)
 	if m.showProgress {
 		s += "\n" + m.progress.View()
@@ -0,0 +1,102 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sivert-io/hytale-server-manager/src/internal/hytale"
+)
+
+// scheduleHistoryRows is how many job runs the schedule view shows
+const scheduleHistoryRows = 15
+
+// Schedules message (scheduled jobs and the scheduler's state)
+type schedulesMsg struct {
+	config *hytale.ScheduleConfig
+	state  *hytale.SchedulerState
+	err    error
+}
+
+// loadSchedulesGo reads shared/schedules.json and the scheduler state
+func loadSchedulesGo() tea.Cmd {
+	return func() tea.Msg {
+		config, err := hytale.ReadSchedules()
+		if err != nil {
+			return schedulesMsg{err: err}
+		}
+		return schedulesMsg{config: config, state: hytale.LoadSchedulerState()}
+	}
+}
+
+// updateSchedules handles keys in the schedule view
+// Returns handled=false for keys the main handler deals with (esc)
+func (m model) updateSchedules(msg tea.KeyMsg) (model, tea.Cmd, bool) {
+	if msg.String() == "r" {
+		return m, loadSchedulesGo(), true
+	}
+	return m, nil, false
+}
+
+// renderSchedules renders the scheduled jobs and the newest job runs
+func (m model) renderSchedules() string {
+	s := titleStyle.Render(" ⏰ Scheduled Jobs") + "\n\n"
+
+	if m.schedules == nil || len(m.schedules.Jobs) == 0 {
+		s += dimmedStyle.Render("No jobs in "+hytale.GetSchedulesPath()) + "\n"
+		s += dimmedStyle.Render("Add jobs there and run hsm daemon to schedule restarts, snapshots, updates and commands.") + "\n"
+	} else {
+		s += selectedStyle.Render(fmt.Sprintf("  %-20s %-16s %-15s %-8s %-26s %s", "Job", "Schedule", "Action", "Servers", "Last run", "Next run")) + "\n"
+		for _, job := range m.schedules.Jobs {
+			servers := job.Servers
+			if servers == "" {
+				servers = "all"
+			}
+			last, next := "-", "-"
+			if js := m.scheduleState.Jobs[job.Name]; js != nil {
+				if !js.LastRun.IsZero() {
+					last = js.LastRun.Format("2006-01-02 15:04") + " " + js.LastStatus
+				}
+				if js.Running {
+					last = "running..."
+				}
+				if !js.NextRun.IsZero() {
+					next = js.NextRun.Format("2006-01-02 15:04")
+				}
+			}
+			if job.Disabled {
+				next = "disabled"
+			}
+			row := fmt.Sprintf("  %-20s %-16s %-15s %-8s %-26s %s", job.Name, job.Schedule, job.Action, servers, last, next)
+			if strings.HasSuffix(last, hytale.JobStatusFailed) {
+				row = consoleErrorStyle.Render(row)
+			}
+			s += row + "\n"
+		}
+	}
+
+	s += "\n" + titleStyle.Render(" History") + "\n\n"
+	history := m.scheduleState.History
+	if len(history) == 0 {
+		s += dimmedStyle.Render("No job runs recorded yet.") + "\n"
+	}
+	for i := len(history) - 1; i >= 0 && i >= len(history)-scheduleHistoryRows; i-- {
+		run := history[i]
+		status := run.Status
+		if run.CatchUp {
+			status += " (catch-up)"
+		}
+		// First line of the output is enough here; hsm schedule history shows all of it
+		output := strings.SplitN(run.Output, "\n", 2)[0]
+		row := fmt.Sprintf("  %s  %-20s %-18s %s", run.Started.Format("2006-01-02 15:04"), run.Job, status, output)
+		if run.Status == hytale.JobStatusFailed {
+			row = consoleErrorStyle.Render(row)
+		} else {
+			row = dimmedStyle.Render(row)
+		}
+		s += row + "\n"
+	}
+
+	s += "\n" + dimmedStyle.Render("r: Refresh  |  Esc: Back")
+	return s
+}
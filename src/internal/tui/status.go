@@ -23,7 +23,7 @@ func pollServerStatus() tea.Cmd {
 			return serverStatusMsg{statuses: []hytale.ServerStatus{}}
 		}
 
-		tm := hytale.NewTmuxManager(hytale.DefaultBasePort)
+		tm := hytale.NewTmuxManagerFromManifest(hytale.LoadManifestOrDefault())
 		statuses := tm.Status(numServers)
 
 		return serverStatusMsg{statuses: statuses}
@@ -38,7 +38,7 @@ func getServerStatus() tea.Cmd {
 			return serverStatusMsg{statuses: []hytale.ServerStatus{}}
 		}
 
-		tm := hytale.NewTmuxManager(hytale.DefaultBasePort)
+		tm := hytale.NewTmuxManagerFromManifest(hytale.LoadManifestOrDefault())
 		statuses := tm.Status(numServers)
 
 		return serverStatusMsg{statuses: statuses}
@@ -54,15 +54,23 @@ func formatServerStatus(statuses []hytale.ServerStatus) string {
 	var s string
 	running := 0
 	stopped := 0
+	crashed := 0
 
 	for _, st := range statuses {
-		if st.Status == "running" {
+		switch st.Status {
+		case hytale.StatusRunning:
 			running++
-		} else {
+		case hytale.StatusCrashed, hytale.StatusCrashLooping:
+			crashed++
+		default:
 			stopped++
 		}
 	}
 
+	if crashed > 0 {
+		return fmt.Sprintf("%d running, %d stopped, %d crashed", running, stopped, crashed)
+	}
+
 	if running > 0 && stopped > 0 {
 		s = fmt.Sprintf("%d running, %d stopped", running, stopped)
 	} else if running > 0 {
@@ -80,6 +88,26 @@ type updateAvailableMsg struct {
 	latestVersion string
 }
 
+// Game update check message
+type gameUpdateMsg struct {
+	check hytale.GameUpdateCheck
+}
+
+// checkForGameUpdate asks hytale-downloader for the latest game version without downloading it
+func checkForGameUpdate() tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		check, err := hytale.CheckGameUpdate(ctx)
+		if err != nil {
+			// Silently fail - hytale-downloader may be missing or not signed in yet
+			return gameUpdateMsg{}
+		}
+		return gameUpdateMsg{check: check}
+	}
+}
+
 // Check for updates periodically
 func checkForUpdates() tea.Cmd {
 	return func() tea.Msg {
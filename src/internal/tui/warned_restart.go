@@ -0,0 +1,178 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sivert-io/hytale-server-manager/src/internal/hytale"
+)
+
+// Warned restart progress message (one countdown step on one server)
+type warnedRestartProgressMsg struct {
+	line string
+}
+
+// warnedRestart is a running countdown restart; cancel stops the countdown
+// Rolling game updates run through it too, with summary describing the update
+type warnedRestart struct {
+	servers []int
+	rolling bool
+	summary string
+	cancel  context.CancelFunc
+	updates chan tea.Msg
+	lines   []string
+}
+
+// startWarnedRestart starts a warned restart in the background
+// Progress arrives as warnedRestartProgressMsg and the end as commandFinishedMsg, both through updates
+func startWarnedRestart(servers []int, rolling bool) (*warnedRestart, tea.Cmd) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &warnedRestart{
+		servers: servers,
+		rolling: rolling,
+		cancel:  cancel,
+		updates: make(chan tea.Msg, 64),
+	}
+
+	go func() {
+		defer close(r.updates)
+		defer cancel()
+		tm := hytale.NewTmuxManagerFromManifest(hytale.LoadManifestOrDefault())
+		opts := hytale.DefaultWarnedRestartOptions()
+		opts.Rolling = rolling
+		opts.Progress = func(server int, message string) {
+			r.updates <- warnedRestartProgressMsg{line: fmt.Sprintf("%s  Server %d: %s", time.Now().Format("15:04:05"), server, message)}
+		}
+
+		results := tm.WarnedRestartServers(ctx, servers, opts)
+		var output strings.Builder
+		failed := 0
+		for _, result := range results {
+			if result.Err != nil {
+				failed++
+				output.WriteString(fmt.Sprintf("❌ Server %d: %v\n", result.Server, result.Err))
+				continue
+			}
+			output.WriteString(fmt.Sprintf("✅ Server %d %s\n", result.Server, result.Detail))
+		}
+		var err error
+		if ctx.Err() != nil {
+			err = fmt.Errorf("restart cancelled")
+		} else if failed > 0 {
+			err = fmt.Errorf("%d of %d server(s) failed to restart", failed, len(results))
+		}
+		r.updates <- commandFinishedMsg{output: output.String(), err: err}
+	}()
+
+	return r, r.wait()
+}
+
+// startRollingUpdate starts a rolling game update in the background, one server at a time with a countdown
+// Progress and the end are reported like a warned restart
+func startRollingUpdate() (*warnedRestart, tea.Cmd) {
+	ctx, cancel := context.WithCancel(context.Background())
+	manifest := hytale.LoadManifestOrDefault()
+	opts := hytale.DefaultRollingUpdateOptions(manifest)
+	opts.Warn = true
+	r := &warnedRestart{
+		rolling: true,
+		summary: fmt.Sprintf("Downloading the latest game version, then one server at a time: countdown %s, stop, copy, start and health check (%s).\nA server that fails its health check is rolled back and the rollout stops.", hytale.DefaultRestartWarnings, opts.HealthTimeout),
+		cancel:  cancel,
+		updates: make(chan tea.Msg, 64),
+	}
+
+	go func() {
+		defer close(r.updates)
+		defer cancel()
+		tm := hytale.NewTmuxManagerFromManifest(manifest)
+		opts.Progress = func(server int, message string) {
+			r.updates <- warnedRestartProgressMsg{line: fmt.Sprintf("%s  Server %d: %s", time.Now().Format("15:04:05"), server, message)}
+		}
+		opts.DeviceCode = func(code hytale.DeviceCode) {
+			r.updates <- warnedRestartProgressMsg{line: fmt.Sprintf("%s  🔑 Sign in to download the game: open %s and enter %s", time.Now().Format("15:04:05"), code.URL, code.Code)}
+		}
+
+		version, results, err := tm.RollingUpdateGame(ctx, opts)
+		var output strings.Builder
+		failed := 0
+		for _, result := range results {
+			if result.Err != nil {
+				failed++
+				output.WriteString(fmt.Sprintf("❌ Server %d: %v\n", result.Server, result.Err))
+				continue
+			}
+			output.WriteString(fmt.Sprintf("✅ Server %d: %s\n", result.Server, result.Detail))
+		}
+		if err == nil && failed > 0 {
+			err = fmt.Errorf("rollout of game version %s stopped: %d of %d server(s) not updated", version, failed, len(results))
+		}
+		r.updates <- commandFinishedMsg{output: output.String(), err: err}
+	}()
+
+	return r, r.wait()
+}
+
+// wait returns the next progress or completion message
+func (r *warnedRestart) wait() tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-r.updates
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+// updateWarnedRestart handles keys while a countdown is running
+// Returns handled=false for keys the main handler deals with (ctrl+c)
+func (m model) updateWarnedRestart(msg tea.KeyMsg) (model, tea.Cmd, bool) {
+	switch msg.String() {
+	case "c", "esc":
+		if m.restart != nil {
+			m.restart.cancel()
+			m.restart.lines = append(m.restart.lines, "Cancelling...")
+		}
+		return m, nil, true
+	case "ctrl+c":
+		return m, nil, false
+	}
+	return m, nil, true
+}
+
+// renderWarnedRestart renders the countdown progress
+func (m model) renderWarnedRestart() string {
+	s := titleStyle.Render(" "+m.actionTitle) + "\n\n"
+	if m.restart == nil {
+		return s
+	}
+
+	if m.restart.summary != "" {
+		s += dimmedStyle.Render(m.restart.summary) + "\n\n"
+	} else {
+		mode := "together"
+		if m.restart.rolling && len(m.restart.servers) > 1 {
+			mode = "one after another"
+		}
+		s += dimmedStyle.Render(fmt.Sprintf("Countdown %s, servers restart %s. Players are warned with: %s", hytale.DefaultRestartWarnings, mode, hytale.DefaultRestartWarningCommand)) + "\n\n"
+	}
+
+	lines := m.restart.lines
+	if max := m.height - 12; max > 0 && len(lines) > max {
+		lines = lines[len(lines)-max:]
+	}
+	for _, line := range lines {
+		s += "  " + line + "\n"
+	}
+	if len(lines) == 0 {
+		s += dimmedStyle.Render("  Starting countdown...") + "\n"
+	}
+	if m.restart.summary != "" {
+		s += "\n" + dimmedStyle.Render("c/Esc: Cancel (servers not reached yet keep their version)")
+	} else {
+		s += "\n" + dimmedStyle.Render("c/Esc: Cancel restart")
+	}
+	return s
+}